@@ -0,0 +1,49 @@
+// Package audit records the change history of tasks from the events bus.
+package audit
+
+import (
+	"task/backend/events"
+	"task/backend/models"
+	"task/backend/repository"
+)
+
+// Subscribe records a history entry for every task event, in the
+// transaction that made the change.
+func Subscribe(bus *events.Bus) {
+	bus.Subscribe(record)
+}
+
+func record(tx *repository.Store, event events.Event) error {
+	task := event.Task()
+	entry := models.AuditEntry{
+		TaskID:      task.ID,
+		WorkspaceID: event.WorkspaceID,
+		ActorID:     event.ActorID,
+		Changes:     []models.FieldChange{},
+		CreatedAt:   event.OccurredAt,
+	}
+
+	switch payload := event.Payload.(type) {
+	case events.TaskCreated:
+		entry.Action = models.AuditCreated
+		entry.Changes = events.Diff(nil, &task)
+	case events.TaskUpdated:
+		entry.Action = models.AuditUpdated
+		entry.Changes = payload.Changes
+	case events.TaskDeleted:
+		entry.Action = models.AuditDeleted
+	case events.TaskRestored:
+		entry.Action = models.AuditRestored
+		if payload.Changes != nil {
+			entry.Changes = payload.Changes
+		}
+	case events.TaskPurged:
+		entry.Action = models.AuditPurged
+	default:
+		// Status changes are part of their TaskUpdated entry, and reminders
+		// change nothing
+		return nil
+	}
+
+	return tx.Audit.Record(&entry)
+}
@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+)
+
+var ErrInvalidToken = errors.New("invalid or expired token")
+
+type claims struct {
+	Type string `json:"typ"`
+	jwt.RegisteredClaims
+}
+
+// TokenManager issues and verifies HMAC-signed JWT access and refresh tokens.
+type TokenManager struct {
+	secret     []byte
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+}
+
+func NewTokenManager(secret string, accessTTL, refreshTTL time.Duration) *TokenManager {
+	return &TokenManager{
+		secret:     []byte(secret),
+		accessTTL:  accessTTL,
+		refreshTTL: refreshTTL,
+	}
+}
+
+// AccessTTL is how long an access token stays valid.
+func (m *TokenManager) AccessTTL() time.Duration {
+	return m.accessTTL
+}
+
+// Issue returns a new access and refresh token pair for the user.
+func (m *TokenManager) Issue(userID int) (access, refresh string, err error) {
+	if access, err = m.sign(userID, TokenTypeAccess, m.accessTTL); err != nil {
+		return "", "", err
+	}
+	if refresh, err = m.sign(userID, TokenTypeRefresh, m.refreshTTL); err != nil {
+		return "", "", err
+	}
+	return access, refresh, nil
+}
+
+// Parse verifies a token of the expected type and returns its user ID.
+func (m *TokenManager) Parse(token, tokenType string) (int, error) {
+	parsed := new(claims)
+	_, err := jwt.ParseWithClaims(token, parsed, func(*jwt.Token) (interface{}, error) {
+		return m.secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil || parsed.Type != tokenType {
+		return 0, ErrInvalidToken
+	}
+
+	userID, err := strconv.Atoi(parsed.Subject)
+	if err != nil || userID <= 0 {
+		return 0, ErrInvalidToken
+	}
+	return userID, nil
+}
+
+func (m *TokenManager) sign(userID int, tokenType string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims{
+		Type: tokenType,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   strconv.Itoa(userID),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+	})
+
+	signed, err := token.SignedString(m.secret)
+	if err != nil {
+		return "", fmt.Errorf("signing %s token: %w", tokenType, err)
+	}
+	return signed, nil
+}
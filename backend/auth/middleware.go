@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"task/backend/models"
+	"task/backend/repository"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	userIDKey   = "auth.userID"
+	apiTokenKey = "auth.apiToken"
+)
+
+// Middleware rejects requests without a valid "Authorization: Bearer" token
+// and stores the authenticated user ID for UserID. Both JWT access tokens
+// and personal API tokens are accepted; read-only API tokens may only be
+// used for safe methods.
+func Middleware(tokens *TokenManager, apiTokens repository.APITokenRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		header := c.Get(fiber.HeaderAuthorization)
+		token, found := strings.CutPrefix(header, "Bearer ")
+		if !found || token == "" {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Missing bearer token"})
+		}
+
+		if IsAPIToken(token) {
+			return authenticateAPIToken(c, apiTokens, token)
+		}
+
+		userID, err := tokens.Parse(token, TokenTypeAccess)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid or expired token"})
+		}
+
+		c.Locals(userIDKey, userID)
+		return c.Next()
+	}
+}
+
+func authenticateAPIToken(c *fiber.Ctx, apiTokens repository.APITokenRepository, token string) error {
+	apiToken, err := apiTokens.GetByHash(HashAPIToken(token))
+	if err != nil || (apiToken.ExpiresAt != nil && apiToken.ExpiresAt.Before(time.Now())) {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid or expired token"})
+	}
+
+	if apiToken.Scope != models.TokenScopeReadWrite && !isSafeMethod(c.Method()) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Token does not permit write access"})
+	}
+
+	// A failed timestamp update shouldn't fail the request itself
+	if err := apiTokens.TouchLastUsed(apiToken.ID, time.Now()); err != nil {
+		log.Printf("Could not update last use of API token %d: %v", apiToken.ID, err)
+	}
+
+	c.Locals(userIDKey, apiToken.UserID)
+	c.Locals(apiTokenKey, apiToken)
+	return c.Next()
+}
+
+// QueryCredentials lets clients that can't set request headers, like
+// browser WebSockets, pass the bearer token in the access_token query
+// parameter and the workspace in workspace_id. Headers take precedence. It
+// must run before Middleware.
+func QueryCredentials(c *fiber.Ctx) error {
+	if token := c.Query("access_token"); token != "" && c.Get(fiber.HeaderAuthorization) == "" {
+		c.Request().Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+	}
+	if workspaceID := c.Query("workspace_id"); workspaceID != "" && c.Get(WorkspaceHeader) == "" {
+		c.Request().Header.Set(WorkspaceHeader, workspaceID)
+	}
+	return c.Next()
+}
+
+// SessionOnly rejects requests authenticated with a personal API token. It
+// must run after Middleware.
+func SessionOnly(c *fiber.Ctx) error {
+	if _, ok := c.Locals(apiTokenKey).(*models.APIToken); ok {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "This endpoint requires a login session"})
+	}
+	return c.Next()
+}
+
+// UserID returns the authenticated user, or 0 outside of Middleware.
+func UserID(c *fiber.Ctx) int {
+	userID, _ := c.Locals(userIDKey).(int)
+	return userID
+}
+
+func isSafeMethod(method string) bool {
+	return method == fiber.MethodGet || method == fiber.MethodHead || method == fiber.MethodOptions
+}
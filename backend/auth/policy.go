@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"task/backend/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Action is an operation guarded by the workspace role policy.
+type Action string
+
+const (
+	ActionReadTasks      Action = "read_tasks"
+	ActionCreateTask     Action = "create_task"
+	ActionUpdateTask     Action = "update_task"
+	ActionDeleteTask     Action = "delete_task"
+	ActionPurgeTask      Action = "purge_task"
+	ActionReadTags       Action = "read_tags"
+	ActionManageTags     Action = "manage_tags"
+	ActionDeleteTag      Action = "delete_tag"
+	ActionManageMembers  Action = "manage_members"
+	ActionManageWorkflow Action = "manage_workflow"
+	ActionManageWebhooks Action = "manage_webhooks"
+)
+
+// roleRank orders roles so that each one includes everything the roles
+// below it may do.
+var roleRank = map[models.WorkspaceRole]int{
+	models.RoleViewer: 1,
+	models.RoleEditor: 2,
+	models.RoleAdmin:  3,
+	models.RoleOwner:  4,
+}
+
+// policy is the least privileged role allowed to perform each action.
+var policy = map[Action]models.WorkspaceRole{
+	ActionReadTasks:      models.RoleViewer,
+	ActionCreateTask:     models.RoleEditor,
+	ActionUpdateTask:     models.RoleEditor,
+	ActionDeleteTask:     models.RoleAdmin,
+	ActionPurgeTask:      models.RoleOwner,
+	ActionReadTags:       models.RoleViewer,
+	ActionManageTags:     models.RoleEditor,
+	ActionDeleteTag:      models.RoleAdmin,
+	ActionManageMembers:  models.RoleAdmin,
+	ActionManageWorkflow: models.RoleAdmin,
+	ActionManageWebhooks: models.RoleAdmin,
+}
+
+// Can reports whether role permits action. Unknown roles and actions are
+// denied.
+func Can(role models.WorkspaceRole, action Action) bool {
+	required, ok := policy[action]
+	if !ok {
+		return false
+	}
+	return roleRank[role] > 0 && roleRank[role] >= roleRank[required]
+}
+
+// Require rejects requests whose workspace role does not permit action. It
+// must run after Workspace.
+func Require(action Action) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !Can(WorkspaceRole(c), action) {
+			return Forbidden(c, action)
+		}
+		return c.Next()
+	}
+}
+
+// Forbidden writes the 403 response used for every policy denial.
+func Forbidden(c *fiber.Ctx, action Action) error {
+	return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+		"error":         "Insufficient permissions",
+		"action":        action,
+		"required_role": policy[action],
+	})
+}
@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"errors"
+	"strconv"
+
+	"task/backend/models"
+	"task/backend/repository"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// WorkspaceHeader selects the workspace a request acts on. Without it,
+// requests use the caller's personal workspace.
+const WorkspaceHeader = "X-Workspace-ID"
+
+const (
+	workspaceIDKey   = "auth.workspaceID"
+	workspaceRoleKey = "auth.workspaceRole"
+)
+
+// Workspace resolves the request's workspace and checks that the
+// authenticated user is a member of it. The member's role is stored for
+// Require. It must run after Middleware.
+func Workspace(workspaces repository.WorkspaceRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID := UserID(c)
+
+		header := c.Get(WorkspaceHeader)
+		if header == "" {
+			workspace, err := repository.EnsurePersonalWorkspace(workspaces, userID)
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not resolve workspace"})
+			}
+			c.Locals(workspaceIDKey, workspace.ID)
+			c.Locals(workspaceRoleKey, models.RoleOwner)
+			return c.Next()
+		}
+
+		workspaceID, err := strconv.Atoi(header)
+		if err != nil || workspaceID <= 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid " + WorkspaceHeader + " header"})
+		}
+
+		// Non-members get the same answer as for missing workspaces
+		member, err := workspaces.GetMember(workspaceID, userID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Workspace not found"})
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not resolve workspace"})
+		}
+
+		c.Locals(workspaceIDKey, workspaceID)
+		c.Locals(workspaceRoleKey, member.Role)
+		return c.Next()
+	}
+}
+
+// WorkspaceID returns the workspace resolved by Workspace, or 0 outside of it.
+func WorkspaceID(c *fiber.Ctx) int {
+	workspaceID, _ := c.Locals(workspaceIDKey).(int)
+	return workspaceID
+}
+
+// WorkspaceRole returns the caller's role in the resolved workspace, or ""
+// outside of Workspace.
+func WorkspaceRole(c *fiber.Ctx) models.WorkspaceRole {
+	role, _ := c.Locals(workspaceRoleKey).(models.WorkspaceRole)
+	return role
+}
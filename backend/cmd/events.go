@@ -0,0 +1,31 @@
+package main
+
+import (
+	"time"
+
+	"task/backend/audit"
+	"task/backend/events"
+	"task/backend/repository"
+	"task/backend/stream"
+	"task/backend/webhook"
+)
+
+// relayInterval is how often the outbox is checked for events that weren't
+// relayed right after their commit, e.g. because the server stopped.
+const relayInterval = 30 * time.Second
+
+// streamReplay is how many task events the live stream keeps for clients
+// that reconnect.
+const streamReplay = 1000
+
+// newBus returns the event bus with the subscribers that act on task
+// changes, and the broker of the live task stream it feeds.
+func newBus(store *repository.Store) (*events.Bus, *stream.Broker) {
+	bus := events.NewBus(store)
+	audit.Subscribe(bus)
+	webhook.Subscribe(bus)
+
+	broker := stream.NewBroker(streamReplay)
+	broker.Subscribe(bus)
+	return bus, broker
+}
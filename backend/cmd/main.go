@@ -1,29 +1,96 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
 	"log"
-	routes "task/backend/config"
+	"os"
+	"strings"
+
+	"task/backend/auth"
+	"task/backend/config"
 	"task/backend/database"
+	"task/backend/handlers"
+	"task/backend/reminder"
+	"task/backend/repository"
+	"task/backend/routes"
+	"task/backend/webhook"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 )
 
 func main() {
-	database.ConnectDB()
-	database.RunMigrations()
-	app := fiber.New()
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(os.Args[2:])
+		return
+	}
+
+	cfg, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+
+	var store *repository.Store
+	// The memory driver runs the API without a database, e.g. for demos
+	if cfg.Database.Driver == database.DriverMemory {
+		fmt.Println("Using in-memory storage. Data will be lost on exit.")
+		store = repository.NewMemoryStore()
+	} else {
+		database.ConnectDB(cfg.Database)
+		database.RunMigrations()
+		store = repository.NewGormStore(database.DB)
+	}
+
+	dispatcher := webhook.NewDispatcher(store.Webhooks)
+	dispatcher.Client = webhook.NewClient(cfg.Webhooks.AllowPrivate)
+	go deliverWebhooks(dispatcher)
+
+	bus, broker := newBus(store)
+	go bus.Run(relayInterval)
+	if cfg.Reminders.Interval > 0 {
+		go sendReminders(reminder.NewScheduler(bus, store.Reminders, cfg.Reminders.Windows), cfg.Reminders.Interval)
+	}
+	tasks := handlers.NewTaskHandler(store.Tasks, store.Workflows, store.Audit, bus)
+	if cfg.Trash.Retention > 0 {
+		go purgeTrash(tasks, cfg.Trash.Retention)
+	}
+
+	tokens := auth.NewTokenManager(cfg.Auth.JWTSecret, cfg.Auth.AccessTokenTTL, cfg.Auth.RefreshTokenTTL)
+
+	app := fiber.New(fiber.Config{
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
+	})
 
 	// Add CORS middleware
 	app.Use(cors.New(cors.Config{
+		AllowOrigins: strings.Join(cfg.CORS.AllowOrigins, ","),
 		AllowMethods: "GET,POST,PUT,DELETE,OPTIONS",
-		AllowHeaders: "Origin,Content-Type,Accept,Authorization",
+		AllowHeaders: "Origin,Content-Type,Accept,Authorization,Last-Event-ID," + auth.WorkspaceHeader,
 		// AllowCredentials: true,
 	}))
 
-	routes.Setup(app)
+	routes.Setup(app, routes.Handlers{
+		Tasks:       tasks,
+		Tags:        handlers.NewTagHandler(store.Tags),
+		Workflows:   handlers.NewWorkflowHandler(store.Workflows, store.Tasks),
+		Webhooks:    handlers.NewWebhookHandler(store.Webhooks, cfg.Webhooks.AllowPrivate),
+		Stream:      handlers.NewStreamHandler(broker),
+		Board:       handlers.NewBoardHandler(app, broker, store.Users),
+		Auth:        handlers.NewAuthHandler(store.Users, tokens),
+		APITokens:   handlers.NewAPITokenHandler(store.APITokens),
+		Workspaces:  handlers.NewWorkspaceHandler(store.Workspaces, store.Users),
+		RequireAuth: auth.Middleware(tokens, store.APITokens),
+
+		ResolveWorkspace: auth.Workspace(store.Workspaces),
+	})
 
-	fmt.Println("🚀 Server starting on localhost:3000")
-	log.Fatal(app.Listen("localhost:3000"))
+	fmt.Printf("🚀 Server starting on %s\n", cfg.Server.Addr)
+	log.Fatal(app.Listen(cfg.Server.Addr))
 }
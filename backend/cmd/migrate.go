@@ -0,0 +1,88 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"strconv"
+
+	"task/backend/config"
+	"task/backend/database"
+)
+
+const migrateUsage = `usage: server migrate <command> [flags]
+
+Commands:
+  up          apply all pending migrations
+  down        roll back the most recent migration
+  status      list migrations and whether they are applied
+  to VERSION  migrate up or down to VERSION (0 rolls back everything)
+
+Flags are the same as for the server, e.g. -db-driver or -database-url.`
+
+// runMigrate implements the "migrate" subcommand.
+func runMigrate(args []string) {
+	if len(args) == 0 {
+		log.Fatal(migrateUsage)
+	}
+
+	command, args := args[0], args[1:]
+	target := 0
+	if command == "to" {
+		if len(args) == 0 {
+			log.Fatal(migrateUsage)
+		}
+		version, err := strconv.Atoi(args[0])
+		if err != nil {
+			log.Fatalf("Invalid migration version %q", args[0])
+		}
+		target, args = version, args[1:]
+	}
+
+	cfg, err := config.LoadDatabase(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	if cfg.Database.Driver == database.DriverMemory {
+		log.Fatal("The memory driver has no schema to migrate")
+	}
+
+	database.ConnectDB(cfg.Database)
+	migrator := database.NewMigrator(database.DB)
+
+	switch command {
+	case "up":
+		err = migrator.Up()
+	case "down":
+		err = migrator.Down()
+	case "to":
+		err = migrator.To(target)
+	case "status":
+		err = printMigrationStatus(migrator)
+	default:
+		log.Fatal(migrateUsage)
+	}
+	if err != nil {
+		log.Fatalf("Migration failed: %v", err)
+	}
+}
+
+func printMigrationStatus(migrator *database.Migrator) error {
+	statuses, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+
+	for _, status := range statuses {
+		applied := "pending"
+		if status.AppliedAt != nil {
+			applied = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Printf("%4d  %-30s %s\n", status.Version, status.Name, applied)
+	}
+	return nil
+}
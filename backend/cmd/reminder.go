@@ -0,0 +1,23 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	"task/backend/reminder"
+)
+
+// sendReminders sends the due task reminders every interval. It never
+// returns.
+func sendReminders(scheduler *reminder.Scheduler, interval time.Duration) {
+	for {
+		sent, err := scheduler.SendDue(time.Now())
+		if err != nil {
+			log.Printf("Sending reminders failed: %v", err)
+		}
+		if sent > 0 {
+			log.Printf("Sent %d task reminders", sent)
+		}
+		time.Sleep(interval)
+	}
+}
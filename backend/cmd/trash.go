@@ -0,0 +1,26 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	"task/backend/handlers"
+)
+
+// purgeInterval is how often expired tasks are purged from the trash.
+const purgeInterval = time.Hour
+
+// purgeTrash permanently deletes tasks that have been in the trash for
+// longer than retention, once at startup and then every purgeInterval. It
+// never returns.
+func purgeTrash(tasks *handlers.TaskHandler, retention time.Duration) {
+	for {
+		purged, err := tasks.PurgeExpired(time.Now().Add(-retention))
+		if err != nil {
+			log.Printf("Purging trash failed: %v", err)
+		} else if purged > 0 {
+			log.Printf("Purged %d tasks from the trash", purged)
+		}
+		time.Sleep(purgeInterval)
+	}
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	"task/backend/webhook"
+)
+
+// deliveryInterval is how often the webhook queue is checked for due
+// deliveries.
+const deliveryInterval = 5 * time.Second
+
+// deliverWebhooks sends due webhook deliveries every deliveryInterval. It
+// never returns.
+func deliverWebhooks(dispatcher *webhook.Dispatcher) {
+	for {
+		if _, err := dispatcher.DeliverDue(time.Now()); err != nil {
+			log.Printf("Delivering webhooks failed: %v", err)
+		}
+		time.Sleep(deliveryInterval)
+	}
+}
@@ -0,0 +1,409 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the complete server configuration.
+//
+// Values are resolved in increasing order of precedence: built-in defaults,
+// the optional config file, environment variables (including a .env file)
+// and finally command line flags.
+type Config struct {
+	Server    ServerConfig    `yaml:"server" toml:"server"`
+	Database  DatabaseConfig  `yaml:"database" toml:"database"`
+	CORS      CORSConfig      `yaml:"cors" toml:"cors"`
+	Auth      AuthConfig      `yaml:"auth" toml:"auth"`
+	Trash     TrashConfig     `yaml:"trash" toml:"trash"`
+	Reminders RemindersConfig `yaml:"reminders" toml:"reminders"`
+	Webhooks  WebhooksConfig  `yaml:"webhooks" toml:"webhooks"`
+}
+
+type ServerConfig struct {
+	Addr         string        `yaml:"addr" toml:"addr"`
+	ReadTimeout  time.Duration `yaml:"read_timeout" toml:"read_timeout"`
+	WriteTimeout time.Duration `yaml:"write_timeout" toml:"write_timeout"`
+	IdleTimeout  time.Duration `yaml:"idle_timeout" toml:"idle_timeout"`
+}
+
+// Storage drivers. The database package re-exports them; they live here so
+// Validate can use them without an import cycle.
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+	// DriverMemory keeps everything in memory, without a database
+	DriverMemory = "memory"
+)
+
+type DatabaseConfig struct {
+	Driver string `yaml:"driver" toml:"driver"`
+	// DSN is a full connection string. When empty, PostgreSQL is reached
+	// through the individual Host/Port/Name settings below.
+	DSN             string        `yaml:"dsn" toml:"dsn"`
+	Host            string        `yaml:"host" toml:"host"`
+	Port            int           `yaml:"port" toml:"port"`
+	Name            string        `yaml:"name" toml:"name"`
+	User            string        `yaml:"user" toml:"user"`
+	Password        string        `yaml:"password" toml:"password"`
+	SSLMode         string        `yaml:"sslmode" toml:"sslmode"`
+	MaxOpenConns    int           `yaml:"max_open_conns" toml:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" toml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" toml:"conn_max_lifetime"`
+}
+
+type CORSConfig struct {
+	AllowOrigins []string `yaml:"allow_origins" toml:"allow_origins"`
+}
+
+type AuthConfig struct {
+	// JWTSecret signs access and refresh tokens. It must be kept private.
+	JWTSecret       string        `yaml:"jwt_secret" toml:"jwt_secret"`
+	AccessTokenTTL  time.Duration `yaml:"access_token_ttl" toml:"access_token_ttl"`
+	RefreshTokenTTL time.Duration `yaml:"refresh_token_ttl" toml:"refresh_token_ttl"`
+}
+
+type TrashConfig struct {
+	// Retention is how long deleted tasks stay in the trash before they
+	// are purged for good. 0 keeps them until they are purged by hand.
+	Retention time.Duration `yaml:"retention" toml:"retention"`
+}
+
+type RemindersConfig struct {
+	// Windows are how long before their due date tasks are reminded of,
+	// unless a task sets its own. Overdue tasks are always reminded of.
+	Windows []time.Duration `yaml:"windows" toml:"windows"`
+	// Interval is how often due tasks are checked. 0 turns reminders off.
+	Interval time.Duration `yaml:"interval" toml:"interval"`
+}
+
+type WebhooksConfig struct {
+	// AllowPrivate lets webhooks reach loopback, private and link-local
+	// addresses. Only turn it on when everyone who manages webhooks may
+	// reach the server's internal network.
+	AllowPrivate bool `yaml:"allow_private" toml:"allow_private"`
+}
+
+// Default returns the configuration used when nothing else is provided.
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Addr:         "localhost:3000",
+			ReadTimeout:  10 * time.Second,
+			WriteTimeout: 10 * time.Second,
+			IdleTimeout:  60 * time.Second,
+		},
+		Database: DatabaseConfig{
+			Driver:          DriverPostgres,
+			Host:            "localhost",
+			Port:            5432,
+			Name:            "task_manager",
+			SSLMode:         "disable",
+			MaxOpenConns:    10,
+			MaxIdleConns:    5,
+			ConnMaxLifetime: 30 * time.Minute,
+		},
+		CORS: CORSConfig{
+			AllowOrigins: []string{"*"},
+		},
+		Auth: AuthConfig{
+			AccessTokenTTL:  15 * time.Minute,
+			RefreshTokenTTL: 7 * 24 * time.Hour,
+		},
+		Trash: TrashConfig{
+			Retention: 30 * 24 * time.Hour,
+		},
+		Reminders: RemindersConfig{
+			Windows:  []time.Duration{24 * time.Hour, time.Hour},
+			Interval: time.Minute,
+		},
+	}
+}
+
+// option binds one setting to its command line flag and environment variable.
+type option struct {
+	flag  string
+	env   string
+	usage string
+	set   func(c *Config, value string) error
+}
+
+var options = []option{
+	{"listen", "LISTEN_ADDR", "address the HTTP server listens on", stringField(func(c *Config) *string { return &c.Server.Addr })},
+	{"read-timeout", "READ_TIMEOUT", "maximum duration for reading a request", durationField(func(c *Config) *time.Duration { return &c.Server.ReadTimeout })},
+	{"write-timeout", "WRITE_TIMEOUT", "maximum duration for writing a response", durationField(func(c *Config) *time.Duration { return &c.Server.WriteTimeout })},
+	{"idle-timeout", "IDLE_TIMEOUT", "maximum keep-alive idle time", durationField(func(c *Config) *time.Duration { return &c.Server.IdleTimeout })},
+	{"db-driver", "DB_DRIVER", "storage driver: postgres, sqlite or memory", stringField(func(c *Config) *string { return &c.Database.Driver })},
+	{"database-url", "DATABASE_URL", "full database connection string", stringField(func(c *Config) *string { return &c.Database.DSN })},
+	{"db-host", "DB_HOST", "PostgreSQL host", stringField(func(c *Config) *string { return &c.Database.Host })},
+	{"db-port", "DB_PORT", "PostgreSQL port", intField(func(c *Config) *int { return &c.Database.Port })},
+	{"db-name", "DB_NAME", "PostgreSQL database name", stringField(func(c *Config) *string { return &c.Database.Name })},
+	{"db-user", "DB_USER", "PostgreSQL user", stringField(func(c *Config) *string { return &c.Database.User })},
+	{"db-password", "DB_PASSWORD", "PostgreSQL password", stringField(func(c *Config) *string { return &c.Database.Password })},
+	{"db-sslmode", "DB_SSLMODE", "PostgreSQL sslmode", stringField(func(c *Config) *string { return &c.Database.SSLMode })},
+	{"db-max-open-conns", "DB_MAX_OPEN_CONNS", "maximum open database connections (0 = unlimited)", intField(func(c *Config) *int { return &c.Database.MaxOpenConns })},
+	{"db-max-idle-conns", "DB_MAX_IDLE_CONNS", "maximum idle database connections", intField(func(c *Config) *int { return &c.Database.MaxIdleConns })},
+	{"db-conn-max-lifetime", "DB_CONN_MAX_LIFETIME", "maximum lifetime of a database connection", durationField(func(c *Config) *time.Duration { return &c.Database.ConnMaxLifetime })},
+	{"cors-origins", "CORS_ORIGINS", "comma-separated list of allowed CORS origins", listField(func(c *Config) *[]string { return &c.CORS.AllowOrigins })},
+	{"jwt-secret", "JWT_SECRET", "secret used to sign authentication tokens", stringField(func(c *Config) *string { return &c.Auth.JWTSecret })},
+	{"access-token-ttl", "ACCESS_TOKEN_TTL", "lifetime of access tokens", durationField(func(c *Config) *time.Duration { return &c.Auth.AccessTokenTTL })},
+	{"refresh-token-ttl", "REFRESH_TOKEN_TTL", "lifetime of refresh tokens", durationField(func(c *Config) *time.Duration { return &c.Auth.RefreshTokenTTL })},
+	{"trash-retention", "TRASH_RETENTION", "how long deleted tasks are kept before they are purged (0 = forever)", durationField(func(c *Config) *time.Duration { return &c.Trash.Retention })},
+	{"reminder-windows", "REMINDER_WINDOWS", "comma-separated list of how long before their due date tasks are reminded of", durationListField(func(c *Config) *[]time.Duration { return &c.Reminders.Windows })},
+	{"reminder-interval", "REMINDER_INTERVAL", "how often due tasks are checked for reminders (0 = off)", durationField(func(c *Config) *time.Duration { return &c.Reminders.Interval })},
+	{"webhook-allow-private", "WEBHOOK_ALLOW_PRIVATE", "let webhooks reach loopback, private and link-local addresses", boolField(func(c *Config) *bool { return &c.Webhooks.AllowPrivate })},
+}
+
+// Load builds the configuration from args (usually os.Args[1:]), the
+// environment and an optional YAML or TOML file named by -config or
+// CONFIG_FILE, and validates it for serving. A missing .env file is not an
+// error.
+func Load(args []string) (*Config, error) {
+	cfg, err := load(args)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// LoadDatabase builds the configuration like Load but only validates the
+// database settings, for commands like migrate that don't serve requests
+// and need no JWT secret.
+func LoadDatabase(args []string) (*Config, error) {
+	cfg, err := load(args)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Database.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func load(args []string) (*Config, error) {
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("loading .env file: %w", err)
+	}
+
+	flags := flag.NewFlagSet("server", flag.ContinueOnError)
+	configFile := flags.String("config", os.Getenv("CONFIG_FILE"), "path to a YAML or TOML config file")
+	values := make(map[string]*string, len(options))
+	for _, opt := range options {
+		values[opt.flag] = flags.String(opt.flag, "", fmt.Sprintf("%s (env %s)", opt.usage, opt.env))
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	if flags.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected argument %q", flags.Arg(0))
+	}
+
+	cfg := Default()
+
+	if *configFile != "" {
+		if err := loadFile(cfg, *configFile); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, opt := range options {
+		if value, ok := os.LookupEnv(opt.env); ok && value != "" {
+			if err := opt.set(cfg, value); err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", opt.env, err)
+			}
+		}
+	}
+
+	var flagErr error
+	flags.Visit(func(f *flag.Flag) {
+		for _, opt := range options {
+			if opt.flag == f.Name && flagErr == nil {
+				if err := opt.set(cfg, *values[opt.flag]); err != nil {
+					flagErr = fmt.Errorf("invalid -%s: %w", opt.flag, err)
+				}
+			}
+		}
+	})
+	if flagErr != nil {
+		return nil, flagErr
+	}
+	return cfg, nil
+}
+
+// Validate reports every problem with the configuration at once.
+func (c *Config) Validate() error {
+	var errs []error
+
+	if c.Server.Addr == "" {
+		errs = append(errs, errors.New("server listen address is required"))
+	}
+	if c.Server.ReadTimeout < 0 || c.Server.WriteTimeout < 0 || c.Server.IdleTimeout < 0 {
+		errs = append(errs, errors.New("server timeouts cannot be negative"))
+	}
+
+	if err := c.Database.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(c.CORS.AllowOrigins) == 0 {
+		errs = append(errs, errors.New("at least one CORS origin is required"))
+	}
+
+	if len(c.Auth.JWTSecret) < 32 {
+		errs = append(errs, errors.New("JWT secret must be at least 32 characters"))
+	}
+	if c.Auth.AccessTokenTTL <= 0 || c.Auth.RefreshTokenTTL <= 0 {
+		errs = append(errs, errors.New("token lifetimes must be positive"))
+	}
+
+	if c.Trash.Retention < 0 {
+		errs = append(errs, errors.New("trash retention cannot be negative"))
+	}
+
+	for _, window := range c.Reminders.Windows {
+		if window < time.Minute || window%time.Minute != 0 {
+			errs = append(errs, fmt.Errorf("reminder window %s must be a positive number of minutes", window))
+		}
+	}
+	if c.Reminders.Interval < 0 {
+		errs = append(errs, errors.New("reminder interval cannot be negative"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Validate reports every problem with the database settings at once.
+func (db DatabaseConfig) Validate() error {
+	var errs []error
+
+	switch db.Driver {
+	case DriverPostgres:
+		if db.DSN == "" {
+			if db.Host == "" || db.Name == "" || db.User == "" {
+				errs = append(errs, errors.New("postgres requires either a database URL or host, name and user"))
+			}
+			if db.Port <= 0 || db.Port > 65535 {
+				errs = append(errs, fmt.Errorf("database port %d is out of range", db.Port))
+			}
+		}
+	case DriverSQLite, DriverMemory:
+	default:
+		errs = append(errs, fmt.Errorf("unsupported database driver %q", db.Driver))
+	}
+	if db.MaxOpenConns < 0 || db.MaxIdleConns < 0 {
+		errs = append(errs, errors.New("database pool sizes cannot be negative"))
+	}
+	if db.MaxOpenConns > 0 && db.MaxIdleConns > db.MaxOpenConns {
+		errs = append(errs, errors.New("database max idle connections cannot exceed max open connections"))
+	}
+	if db.ConnMaxLifetime < 0 {
+		errs = append(errs, errors.New("database connection lifetime cannot be negative"))
+	}
+
+	return errors.Join(errs...)
+}
+
+func loadFile(cfg *Config, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading config file: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, cfg)
+	case ".toml":
+		err = toml.Unmarshal(data, cfg)
+	default:
+		return fmt.Errorf("config file %s must be .yaml, .yml or .toml", path)
+	}
+	if err != nil {
+		return fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+	return nil
+}
+
+func stringField(field func(*Config) *string) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		*field(c) = value
+		return nil
+	}
+}
+
+func intField(field func(*Config) *int) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		*field(c) = n
+		return nil
+	}
+}
+
+func boolField(field func(*Config) *bool) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		*field(c) = b
+		return nil
+	}
+}
+
+func durationField(field func(*Config) *time.Duration) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		*field(c) = d
+		return nil
+	}
+}
+
+func listField(field func(*Config) *[]string) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		*field(c) = items
+		return nil
+	}
+}
+
+func durationListField(field func(*Config) *[]time.Duration) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		var items []time.Duration
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item == "" {
+				continue
+			}
+			d, err := time.ParseDuration(item)
+			if err != nil {
+				return err
+			}
+			items = append(items, d)
+		}
+		*field(c) = items
+		return nil
+	}
+}
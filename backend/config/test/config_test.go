@@ -0,0 +1,209 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"task/backend/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	t.Setenv("JWT_SECRET", testSecret)
+	t.Setenv("DB_USER", "manager")
+
+	cfg, err := config.Load(nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "localhost:3000", cfg.Server.Addr)
+	assert.Equal(t, "postgres", cfg.Database.Driver)
+	assert.Equal(t, 5432, cfg.Database.Port)
+	assert.Equal(t, "task_manager", cfg.Database.Name)
+	assert.Equal(t, []string{"*"}, cfg.CORS.AllowOrigins)
+	assert.Equal(t, 15*time.Minute, cfg.Auth.AccessTokenTTL)
+	assert.Equal(t, 30*24*time.Hour, cfg.Trash.Retention)
+	assert.Equal(t, []time.Duration{24 * time.Hour, time.Hour}, cfg.Reminders.Windows)
+	assert.Equal(t, time.Minute, cfg.Reminders.Interval)
+	assert.False(t, cfg.Webhooks.AllowPrivate)
+}
+
+func TestLoad_YAMLFile(t *testing.T) {
+	t.Setenv("JWT_SECRET", testSecret)
+	path := writeFile(t, "config.yaml", `
+server:
+  addr: ":8080"
+  read_timeout: 5s
+database:
+  driver: sqlite
+  dsn: tasks.db
+  max_open_conns: 1
+  max_idle_conns: 1
+cors:
+  allow_origins: ["https://app.example.com"]
+`)
+
+	cfg, err := config.Load([]string{"-config", path})
+	require.NoError(t, err)
+
+	assert.Equal(t, ":8080", cfg.Server.Addr)
+	assert.Equal(t, 5*time.Second, cfg.Server.ReadTimeout)
+	assert.Equal(t, 10*time.Second, cfg.Server.WriteTimeout) // default kept
+	assert.Equal(t, "sqlite", cfg.Database.Driver)
+	assert.Equal(t, "tasks.db", cfg.Database.DSN)
+	assert.Equal(t, 1, cfg.Database.MaxOpenConns)
+	assert.Equal(t, []string{"https://app.example.com"}, cfg.CORS.AllowOrigins)
+}
+
+func TestLoad_TOMLFile(t *testing.T) {
+	t.Setenv("JWT_SECRET", testSecret)
+	path := writeFile(t, "config.toml", `
+[server]
+addr = "0.0.0.0:9000"
+idle_timeout = "2m"
+
+[database]
+driver = "memory"
+`)
+	t.Setenv("CONFIG_FILE", path)
+
+	cfg, err := config.Load(nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "0.0.0.0:9000", cfg.Server.Addr)
+	assert.Equal(t, 2*time.Minute, cfg.Server.IdleTimeout)
+	assert.Equal(t, "memory", cfg.Database.Driver)
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	t.Setenv("JWT_SECRET", testSecret)
+	path := writeFile(t, "config.yaml", `
+server:
+  addr: "file:1"
+database:
+  driver: sqlite
+  max_open_conns: 20
+`)
+	t.Setenv("LISTEN_ADDR", "env:2")
+	t.Setenv("DB_MAX_OPEN_CONNS", "30")
+	t.Setenv("CORS_ORIGINS", "https://a.example.com, https://b.example.com")
+
+	cfg, err := config.Load([]string{"-config", path, "-listen", "flag:3"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "flag:3", cfg.Server.Addr)     // flag beats env and file
+	assert.Equal(t, 30, cfg.Database.MaxOpenConns) // env beats file
+	assert.Equal(t, "sqlite", cfg.Database.Driver) // file beats default
+	assert.Equal(t, []string{"https://a.example.com", "https://b.example.com"}, cfg.CORS.AllowOrigins)
+}
+
+func TestLoad_InvalidValues(t *testing.T) {
+	t.Setenv("JWT_SECRET", testSecret)
+	testCases := []struct {
+		name string
+		args []string
+		env  map[string]string
+		err  string
+	}{
+		{"Bad duration", []string{"-read-timeout", "soon"}, nil, "invalid -read-timeout"},
+		{"Bad env number", nil, map[string]string{"DB_PORT": "abc"}, "invalid DB_PORT"},
+		{"Unknown driver", []string{"-db-driver", "mysql"}, nil, "unsupported database driver"},
+		{"Postgres without user", []string{"-db-driver", "postgres", "-db-user", ""}, nil, "postgres requires"},
+		{"Idle above open", []string{"-db-driver", "memory", "-db-max-open-conns", "2", "-db-max-idle-conns", "5"}, nil, "cannot exceed"},
+		{"Empty listen address", []string{"-db-driver", "memory", "-listen", ""}, nil, "listen address is required"},
+		{"Missing file", []string{"-config", "missing.yaml"}, nil, "reading config file"},
+		{"Short JWT secret", []string{"-db-driver", "memory", "-jwt-secret", "short"}, nil, "at least 32 characters"},
+		{"Negative trash retention", []string{"-db-driver", "memory", "-trash-retention", "-1h"}, nil, "trash retention cannot be negative"},
+		{"Bad reminder window", nil, map[string]string{"DB_DRIVER": "memory", "REMINDER_WINDOWS": "1d"}, "invalid REMINDER_WINDOWS"},
+		{"Reminder window under a minute", []string{"-db-driver", "memory", "-reminder-windows", "24h,30s"}, nil, "reminder window 30s"},
+		{"Negative reminder interval", []string{"-db-driver", "memory", "-reminder-interval", "-1m"}, nil, "reminder interval cannot be negative"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for key, value := range tc.env {
+				t.Setenv(key, value)
+			}
+
+			_, err := config.Load(tc.args)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.err)
+		})
+	}
+}
+
+func TestLoad_ReminderWindows(t *testing.T) {
+	t.Setenv("JWT_SECRET", testSecret)
+	t.Setenv("DB_DRIVER", "memory")
+	t.Setenv("REMINDER_WINDOWS", "48h, 2h,15m")
+
+	cfg, err := config.Load(nil)
+	require.NoError(t, err)
+	assert.Equal(t, []time.Duration{48 * time.Hour, 2 * time.Hour, 15 * time.Minute}, cfg.Reminders.Windows)
+
+	// Only overdue tasks and the tasks' own reminders are left
+	cfg, err = config.Load([]string{"-reminder-windows", ""})
+	require.NoError(t, err)
+	assert.Empty(t, cfg.Reminders.Windows)
+
+	path := writeFile(t, "config.yaml", `
+reminders:
+  windows: [30m]
+  interval: 0s
+`)
+	t.Setenv("REMINDER_WINDOWS", "")
+	cfg, err = config.Load([]string{"-config", path})
+	require.NoError(t, err)
+	assert.Equal(t, []time.Duration{30 * time.Minute}, cfg.Reminders.Windows)
+	assert.Zero(t, cfg.Reminders.Interval)
+}
+
+func TestLoad_WebhookAllowPrivate(t *testing.T) {
+	t.Setenv("JWT_SECRET", testSecret)
+	t.Setenv("DB_DRIVER", "memory")
+	t.Setenv("WEBHOOK_ALLOW_PRIVATE", "true")
+
+	cfg, err := config.Load(nil)
+	require.NoError(t, err)
+	assert.True(t, cfg.Webhooks.AllowPrivate)
+
+	_, err = config.Load([]string{"-webhook-allow-private", "maybe"})
+	assert.ErrorContains(t, err, "invalid -webhook-allow-private")
+}
+
+func TestLoad_UnsupportedFile(t *testing.T) {
+	t.Setenv("JWT_SECRET", testSecret)
+	path := writeFile(t, "config.json", `{"server": {"addr": ":8080"}}`)
+
+	_, err := config.Load([]string{"-config", path})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "must be .yaml, .yml or .toml")
+}
+
+func TestLoadDatabase_WithoutJWTSecret(t *testing.T) {
+	t.Setenv("JWT_SECRET", "")
+
+	cfg, err := config.LoadDatabase([]string{"-db-driver", "sqlite", "-database-url", "tasks.db"})
+	require.NoError(t, err)
+	assert.Equal(t, "tasks.db", cfg.Database.DSN)
+
+	// Serving still needs it
+	_, err = config.Load([]string{"-db-driver", "sqlite"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "JWT secret")
+
+	_, err = config.LoadDatabase([]string{"-db-driver", "mysql"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported database driver")
+}
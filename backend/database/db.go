@@ -5,32 +5,78 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
-	"os"
 
-	"github.com/joho/godotenv"
-	"github.com/lib/pq" // PostgreSQL driver for checking specific errors
+	"task/backend/config"
+
+	"github.com/glebarez/sqlite" // Pure-Go SQLite driver, no cgo required
+	"github.com/lib/pq"          // PostgreSQL driver for checking specific errors
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
+const (
+	DriverPostgres = config.DriverPostgres
+	DriverSQLite   = config.DriverSQLite
+	// DriverMemory has no database; Open and ConnectDB don't support it
+	DriverMemory = config.DriverMemory
+)
+
 var DB *gorm.DB
 
-func ConnectDB() {
-	err := godotenv.Load()
+// Open connects GORM to the given driver and DSN. Driver-specific errors such
+// as unique violations are translated into GORM's generic errors.
+func Open(driver, dsn string) (*gorm.DB, error) {
+	var dialector gorm.Dialector
+	switch driver {
+	case DriverPostgres:
+		dialector = postgres.Open(dsn)
+	case DriverSQLite:
+		dialector = sqlite.Open(dsn)
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q", driver)
+	}
+
+	return gorm.Open(dialector, &gorm.Config{TranslateError: true})
+}
+
+// ConnectDB opens the configured database and applies the connection pool
+// settings.
+func ConnectDB(cfg config.DatabaseConfig) {
+	dsn := cfg.DSN
+
+	switch cfg.Driver {
+	case DriverPostgres:
+		if dsn == "" {
+			dsn = bootstrapPostgres(cfg)
+		}
+	case DriverSQLite:
+		if dsn == "" {
+			dsn = "task_manager.db?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)"
+		}
+	}
+
+	var err error
+	DB, err = Open(cfg.Driver, dsn)
 	if err != nil {
-		log.Fatal("Error loading .env file")
+		log.Fatal("failed to connect to database:", err)
 	}
-	// --- Step 1: Connect to the default 'postgres' database to check if our DB exists ---
-	const (
-		host   = "localhost"
-		port   = 5432
-		dbname = "task_manager"
-	)
-	user := os.Getenv("DB_USER")
-	password := os.Getenv("DB_PASSWORD")
 
-	// DSN for the initial connection to the 'postgres' database
-	initialDSN := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=postgres sslmode=disable", host, port, user, password)
+	sqlDB, err := DB.DB()
+	if err != nil {
+		log.Fatal("failed to access database pool:", err)
+	}
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+
+	fmt.Printf("Connected to the %s database successfully.\n", cfg.Driver)
+}
+
+// bootstrapPostgres creates the configured database if needed and returns the
+// DSN to reach it. It is only used when no database URL is configured.
+func bootstrapPostgres(cfg config.DatabaseConfig) string {
+	// --- Step 1: Connect to the default 'postgres' database to check if our DB exists ---
+	initialDSN := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=postgres sslmode=%s", cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.SSLMode)
 
 	// Open a standard SQL connection
 	sqlDB, err := sql.Open("postgres", initialDSN)
@@ -45,28 +91,21 @@ func ConnectDB() {
 		log.Fatal("failed to ping postgres database:", err)
 	}
 
-	// --- Step 2: Try to create the 'task_manager' database ---
-	_, err = sqlDB.Exec(fmt.Sprintf("CREATE DATABASE %s", dbname))
+	// --- Step 2: Try to create the configured database ---
+	_, err = sqlDB.Exec(fmt.Sprintf("CREATE DATABASE %s", pq.QuoteIdentifier(cfg.Name)))
 	if err != nil {
 		// If the error is that the database already exists, we can ignore it.
 		// The pq library is needed to inspect the specific PostgreSQL error code.
 		if pgErr, ok := err.(*pq.Error); ok && pgErr.Code == "42P04" { // 42P04 is for "duplicate_database"
-			fmt.Printf("Database '%s' already exists. Continuing.\n", dbname)
+			fmt.Printf("Database '%s' already exists. Continuing.\n", cfg.Name)
 		} else {
 			// A different error occurred
 			log.Fatal("failed to create database:", err)
 		}
 	} else {
-		fmt.Printf("Database '%s' created successfully.\n", dbname)
-	}
-
-	// --- Step 3: Now, connect to the 'task_manager' database with GORM ---
-	dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable", host, port, user, password, dbname)
-
-	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
-	if err != nil {
-		log.Fatal("failed to connect to task_manager database:", err)
+		fmt.Printf("Database '%s' created successfully.\n", cfg.Name)
 	}
 
-	fmt.Println("Connected to the database successfully.")
-}
\ No newline at end of file
+	// --- Step 3: Return the DSN for the configured database ---
+	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s", cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.Name, cfg.SSLMode)
+}
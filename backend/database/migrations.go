@@ -0,0 +1,471 @@
+package database
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// migrations is the ordered schema history. Each migration declares its own
+// snapshot of the tables it touches instead of using the structs in models,
+// so later model changes never alter what an old migration does.
+var migrations = []Migration{
+	{
+		Version: 1,
+		Name:    "create_tasks",
+		Up: func(tx *gorm.DB) error {
+			type task struct {
+				ID          int    `gorm:"primaryKey"`
+				Title       string `gorm:"unique;not null"`
+				Description string
+				Status      string `gorm:"default:'pending'"`
+				DueDate     *time.Time
+				CreatedAt   time.Time
+				UpdatedAt   time.Time
+			}
+			// AutoMigrate rather than CreateTable so databases created before
+			// versioned migrations existed are adopted as-is
+			return tx.Table("tasks").AutoMigrate(&task{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable("tasks")
+		},
+	},
+	{
+		Version: 2,
+		Name:    "create_users_and_task_owners",
+		Up: func(tx *gorm.DB) error {
+			type user struct {
+				ID           int    `gorm:"primaryKey"`
+				Email        string `gorm:"uniqueIndex;not null"`
+				PasswordHash string `gorm:"not null"`
+				CreatedAt    time.Time
+				UpdatedAt    time.Time
+			}
+			if err := tx.Table("users").AutoMigrate(&user{}); err != nil {
+				return err
+			}
+
+			// Tasks created before accounts existed keep owner 0 and are
+			// not visible to any user
+			type task struct {
+				OwnerID int `gorm:"index;not null;default:0"`
+			}
+			return tx.Table("tasks").AutoMigrate(&task{})
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropIndex("tasks", "idx_tasks_owner_id"); err != nil {
+				return err
+			}
+			if err := dropColumn(tx, "tasks", "owner_id"); err != nil {
+				return err
+			}
+			return tx.Migrator().DropTable("users")
+		},
+	},
+	{
+		Version: 3,
+		Name:    "create_api_tokens",
+		Up: func(tx *gorm.DB) error {
+			type apiToken struct {
+				ID         int    `gorm:"primaryKey"`
+				UserID     int    `gorm:"index;not null"`
+				Name       string `gorm:"not null"`
+				Prefix     string `gorm:"not null"`
+				TokenHash  string `gorm:"uniqueIndex;not null"`
+				Scope      string `gorm:"not null"`
+				ExpiresAt  *time.Time
+				LastUsedAt *time.Time
+				CreatedAt  time.Time
+			}
+			return tx.Table("api_tokens").AutoMigrate(&apiToken{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable("api_tokens")
+		},
+	},
+	{
+		Version: 4,
+		Name:    "create_workspaces",
+		Up: func(tx *gorm.DB) error {
+			type workspace struct {
+				ID        int    `gorm:"primaryKey"`
+				Name      string `gorm:"not null"`
+				OwnerID   int    `gorm:"index;not null"`
+				Personal  bool   `gorm:"not null;default:false"`
+				CreatedAt time.Time
+				UpdatedAt time.Time
+			}
+			type workspaceMember struct {
+				WorkspaceID int `gorm:"primaryKey;autoIncrement:false"`
+				UserID      int `gorm:"primaryKey;autoIncrement:false;index"`
+				CreatedAt   time.Time
+			}
+			if err := tx.Table("workspaces").AutoMigrate(&workspace{}); err != nil {
+				return err
+			}
+			if err := tx.Table("workspace_members").AutoMigrate(&workspaceMember{}); err != nil {
+				return err
+			}
+			// At most one personal workspace per user
+			if err := tx.Exec("CREATE UNIQUE INDEX idx_workspaces_personal_owner ON workspaces (owner_id) WHERE personal").Error; err != nil {
+				return err
+			}
+
+			type task struct {
+				ID          int    `gorm:"primaryKey"`
+				Title       string `gorm:"not null;uniqueIndex:idx_tasks_workspace_title,priority:2"`
+				Description string
+				Status      string `gorm:"default:'pending'"`
+				DueDate     *time.Time
+				OwnerID     int `gorm:"index;not null;default:0"`
+				WorkspaceID int `gorm:"not null;default:0;uniqueIndex:idx_tasks_workspace_title,priority:1"`
+				CreatedAt   time.Time
+				UpdatedAt   time.Time
+			}
+			if !tx.Migrator().HasColumn("tasks", "workspace_id") {
+				if err := tx.Exec("ALTER TABLE tasks ADD COLUMN workspace_id integer NOT NULL DEFAULT 0").Error; err != nil {
+					return err
+				}
+			}
+
+			// Give every existing user a personal workspace holding their tasks
+			var userIDs []int
+			if err := tx.Table("users").Order("id").Pluck("id", &userIDs).Error; err != nil {
+				return err
+			}
+			now := time.Now()
+			for _, userID := range userIDs {
+				personal := workspace{Name: "Personal", OwnerID: userID, Personal: true, CreatedAt: now, UpdatedAt: now}
+				if err := tx.Table("workspaces").Create(&personal).Error; err != nil {
+					return err
+				}
+				member := workspaceMember{WorkspaceID: personal.ID, UserID: userID, CreatedAt: now}
+				if err := tx.Table("workspace_members").Create(&member).Error; err != nil {
+					return err
+				}
+				if err := tx.Table("tasks").Where("owner_id = ?", userID).Update("workspace_id", personal.ID).Error; err != nil {
+					return err
+				}
+			}
+
+			// Replace the global title constraint. Its name depends on the
+			// GORM version that first created the table.
+			for _, name := range []string{"uni_tasks_title", "tasks_title_key"} {
+				if tx.Table("tasks").Migrator().HasConstraint(&task{}, name) {
+					if err := tx.Table("tasks").Migrator().DropConstraint(&task{}, name); err != nil {
+						return err
+					}
+				}
+				if tx.Migrator().HasIndex("tasks", name) {
+					if err := tx.Migrator().DropIndex("tasks", name); err != nil {
+						return err
+					}
+				}
+			}
+
+			// SQLite rebuilds the table to drop a constraint, which loses its
+			// indexes, so let AutoMigrate (re)create them
+			return tx.Table("tasks").AutoMigrate(&task{})
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropIndex("tasks", "idx_tasks_workspace_title"); err != nil {
+				return err
+			}
+			if err := tx.Exec("CREATE UNIQUE INDEX uni_tasks_title ON tasks (title)").Error; err != nil {
+				return err
+			}
+			if err := dropColumn(tx, "tasks", "workspace_id"); err != nil {
+				return err
+			}
+			if err := tx.Migrator().DropTable("workspace_members"); err != nil {
+				return err
+			}
+			return tx.Migrator().DropTable("workspaces")
+		},
+	},
+	{
+		Version: 5,
+		Name:    "add_workspace_member_roles",
+		Up: func(tx *gorm.DB) error {
+			// Existing members become editors, which keeps them able to
+			// create and update tasks; workspace owners get the owner role
+			type workspaceMember struct {
+				Role string `gorm:"not null;default:'editor'"`
+			}
+			if err := tx.Table("workspace_members").AutoMigrate(&workspaceMember{}); err != nil {
+				return err
+			}
+			return tx.Exec(`UPDATE workspace_members SET role = 'owner' WHERE EXISTS (
+				SELECT 1 FROM workspaces
+				WHERE workspaces.id = workspace_members.workspace_id AND workspaces.owner_id = workspace_members.user_id)`).Error
+		},
+		Down: func(tx *gorm.DB) error {
+			return dropColumn(tx, "workspace_members", "role")
+		},
+	},
+	{
+		Version: 6,
+		Name:    "add_task_priority",
+		Up: func(tx *gorm.DB) error {
+			type task struct {
+				Priority string `gorm:"not null;default:'medium'"`
+			}
+			return tx.Table("tasks").AutoMigrate(&task{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return dropColumn(tx, "tasks", "priority")
+		},
+	},
+	{
+		Version: 7,
+		Name:    "create_tags",
+		Up: func(tx *gorm.DB) error {
+			type tag struct {
+				ID          int    `gorm:"primaryKey"`
+				WorkspaceID int    `gorm:"not null;uniqueIndex:idx_tags_workspace_name,priority:1"`
+				Name        string `gorm:"not null;uniqueIndex:idx_tags_workspace_name,priority:2"`
+				CreatedAt   time.Time
+				UpdatedAt   time.Time
+			}
+			type taskTag struct {
+				TaskID int `gorm:"primaryKey;autoIncrement:false"`
+				TagID  int `gorm:"primaryKey;autoIncrement:false;index"`
+			}
+			if err := tx.Table("tags").AutoMigrate(&tag{}); err != nil {
+				return err
+			}
+			return tx.Table("task_tags").AutoMigrate(&taskTag{})
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropTable("task_tags"); err != nil {
+				return err
+			}
+			return tx.Migrator().DropTable("tags")
+		},
+	},
+	{
+		Version: 8,
+		Name:    "add_task_parents",
+		Up: func(tx *gorm.DB) error {
+			type task struct {
+				ParentID *int `gorm:"index"`
+			}
+			return tx.Table("tasks").AutoMigrate(&task{})
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropIndex("tasks", "idx_tasks_parent_id"); err != nil {
+				return err
+			}
+			return dropColumn(tx, "tasks", "parent_id")
+		},
+	},
+	{
+		Version: 9,
+		Name:    "create_task_dependencies",
+		Up: func(tx *gorm.DB) error {
+			type taskDependency struct {
+				TaskID      int `gorm:"primaryKey;autoIncrement:false"`
+				DependsOnID int `gorm:"primaryKey;autoIncrement:false;index"`
+				CreatedAt   time.Time
+			}
+			return tx.Table("task_dependencies").AutoMigrate(&taskDependency{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable("task_dependencies")
+		},
+	},
+	{
+		Version: 10,
+		Name:    "create_workflows",
+		Up: func(tx *gorm.DB) error {
+			// Statuses and transitions are stored as JSON
+			type workflow struct {
+				WorkspaceID int    `gorm:"primaryKey;autoIncrement:false"`
+				Initial     string `gorm:"not null"`
+				Statuses    string `gorm:"not null"`
+				Transitions string `gorm:"not null"`
+			}
+			return tx.Table("workflows").AutoMigrate(&workflow{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable("workflows")
+		},
+	},
+	{
+		Version: 11,
+		Name:    "add_task_recurrence",
+		Up: func(tx *gorm.DB) error {
+			type task struct {
+				Recurrence string `gorm:"not null;default:''"`
+				SeriesID   *int   `gorm:"index"`
+				Occurrence int    `gorm:"not null;default:0"`
+			}
+			return tx.Table("tasks").AutoMigrate(&task{})
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropIndex("tasks", "idx_tasks_series_id"); err != nil {
+				return err
+			}
+			for _, column := range []string{"occurrence", "series_id", "recurrence"} {
+				if err := dropColumn(tx, "tasks", column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version: 12,
+		Name:    "add_task_soft_delete",
+		Up: func(tx *gorm.DB) error {
+			type task struct {
+				DeletedAt *time.Time `gorm:"index"`
+			}
+			if err := tx.Table("tasks").AutoMigrate(&task{}); err != nil {
+				return err
+			}
+
+			// Trashed tasks give up their titles
+			if err := tx.Migrator().DropIndex("tasks", "idx_tasks_workspace_title"); err != nil {
+				return err
+			}
+			return tx.Exec("CREATE UNIQUE INDEX idx_tasks_workspace_title ON tasks (workspace_id, title) WHERE deleted_at IS NULL").Error
+		},
+		Down: func(tx *gorm.DB) error {
+			// Trashed tasks would come back and could clash with the tasks
+			// that took their titles, so they are purged
+			trashed := "SELECT id FROM tasks WHERE deleted_at IS NOT NULL"
+			for _, statement := range []string{
+				"DELETE FROM task_tags WHERE task_id IN (" + trashed + ")",
+				"UPDATE tasks SET parent_id = NULL WHERE parent_id IN (" + trashed + ")",
+				"DELETE FROM tasks WHERE deleted_at IS NOT NULL",
+			} {
+				if err := tx.Exec(statement).Error; err != nil {
+					return err
+				}
+			}
+
+			if err := tx.Migrator().DropIndex("tasks", "idx_tasks_workspace_title"); err != nil {
+				return err
+			}
+			if err := tx.Exec("CREATE UNIQUE INDEX idx_tasks_workspace_title ON tasks (workspace_id, title)").Error; err != nil {
+				return err
+			}
+			if err := tx.Migrator().DropIndex("tasks", "idx_tasks_deleted_at"); err != nil {
+				return err
+			}
+			return dropColumn(tx, "tasks", "deleted_at")
+		},
+	},
+	{
+		Version: 13,
+		Name:    "create_audit_entries",
+		Up: func(tx *gorm.DB) error {
+			// Changes are stored as JSON
+			type auditEntry struct {
+				ID          int    `gorm:"primaryKey"`
+				TaskID      int    `gorm:"not null;index"`
+				WorkspaceID int    `gorm:"not null"`
+				ActorID     int    `gorm:"not null"`
+				Action      string `gorm:"not null"`
+				Changes     string `gorm:"not null"`
+				CreatedAt   time.Time
+			}
+			return tx.Table("audit_entries").AutoMigrate(&auditEntry{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable("audit_entries")
+		},
+	},
+	{
+		Version: 14,
+		Name:    "create_webhooks",
+		Up: func(tx *gorm.DB) error {
+			// Event lists and payloads are stored as JSON
+			type webhook struct {
+				ID          int    `gorm:"primaryKey"`
+				WorkspaceID int    `gorm:"not null;index"`
+				URL         string `gorm:"not null"`
+				Events      string `gorm:"not null"`
+				Secret      string `gorm:"not null"`
+				CreatedAt   time.Time
+				UpdatedAt   time.Time
+			}
+			type webhookDelivery struct {
+				ID             int       `gorm:"primaryKey"`
+				WebhookID      int       `gorm:"not null;index"`
+				Event          string    `gorm:"not null"`
+				Payload        string    `gorm:"not null"`
+				Status         string    `gorm:"not null;index:idx_webhook_deliveries_due,priority:1"`
+				Attempts       int       `gorm:"not null"`
+				NextAttemptAt  time.Time `gorm:"not null;index:idx_webhook_deliveries_due,priority:2"`
+				ResponseStatus int
+				Error          string
+				DeliveredAt    *time.Time
+				CreatedAt      time.Time
+				UpdatedAt      time.Time
+			}
+			if err := tx.Table("webhooks").AutoMigrate(&webhook{}); err != nil {
+				return err
+			}
+			return tx.Table("webhook_deliveries").AutoMigrate(&webhookDelivery{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable("webhook_deliveries", "webhooks")
+		},
+	},
+	{
+		Version: 15,
+		Name:    "create_outbox_events",
+		Up: func(tx *gorm.DB) error {
+			type outboxEvent struct {
+				ID          int       `gorm:"primaryKey"`
+				Type        string    `gorm:"not null"`
+				WorkspaceID int       `gorm:"not null"`
+				ActorID     int       `gorm:"not null"`
+				Payload     string    `gorm:"not null"`
+				OccurredAt  time.Time `gorm:"not null"`
+			}
+			return tx.Table("outbox_events").AutoMigrate(&outboxEvent{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable("outbox_events")
+		},
+	},
+	{
+		Version: 16,
+		Name:    "add_task_reminders",
+		Up: func(tx *gorm.DB) error {
+			// Reminder offsets are stored as JSON
+			type task struct {
+				Reminders string
+			}
+			type taskReminder struct {
+				ID            int       `gorm:"primaryKey"`
+				TaskID        int       `gorm:"not null;uniqueIndex:idx_task_reminders_once,priority:1"`
+				DueDate       time.Time `gorm:"not null;uniqueIndex:idx_task_reminders_once,priority:2"`
+				MinutesBefore int       `gorm:"not null;uniqueIndex:idx_task_reminders_once,priority:3"`
+				Notified      bool      `gorm:"not null"`
+				CreatedAt     time.Time
+			}
+			if err := tx.Table("tasks").AutoMigrate(&task{}); err != nil {
+				return err
+			}
+			return tx.Table("task_reminders").AutoMigrate(&taskReminder{})
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropTable("task_reminders"); err != nil {
+				return err
+			}
+			return dropColumn(tx, "tasks", "reminders")
+		},
+	},
+}
+
+// dropColumn issues a plain ALTER TABLE, which both PostgreSQL and SQLite
+// support. GORM's SQLite migrator can only drop columns of parsed models.
+func dropColumn(tx *gorm.DB, table, column string) error {
+	return tx.Exec("ALTER TABLE ? DROP COLUMN ?", clause.Table{Name: table}, clause.Column{Name: column}).Error
+}
@@ -0,0 +1,174 @@
+package database
+
+import (
+	"errors"
+	"testing"
+
+	"task/backend/database"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func openSQLite(t *testing.T) *gorm.DB {
+	db, err := database.Open(database.DriverSQLite, ":memory:")
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	db.Logger = logger.Default.LogMode(logger.Silent)
+	return db
+}
+
+func testMigrations() []database.Migration {
+	createTable := func(name string) database.Migration {
+		return database.Migration{
+			Up: func(tx *gorm.DB) error {
+				return tx.Exec("CREATE TABLE " + name + " (id INTEGER PRIMARY KEY)").Error
+			},
+			Down: func(tx *gorm.DB) error {
+				return tx.Migrator().DropTable(name)
+			},
+		}
+	}
+
+	first, second, third := createTable("first"), createTable("second"), createTable("third")
+	first.Version, first.Name = 1, "create_first"
+	second.Version, second.Name = 2, "create_second"
+	third.Version, third.Name = 3, "create_third"
+	// Deliberately out of order: the migrator sorts by version
+	return []database.Migration{third, first, second}
+}
+
+func appliedVersions(t *testing.T, migrator *database.Migrator) []int {
+	statuses, err := migrator.Status()
+	require.NoError(t, err)
+
+	var versions []int
+	for _, status := range statuses {
+		if status.AppliedAt != nil {
+			versions = append(versions, status.Version)
+		}
+	}
+	return versions
+}
+
+func TestMigrator_UpDownAndTo(t *testing.T) {
+	db := openSQLite(t)
+	migrator := database.NewMigratorWith(db, testMigrations())
+
+	require.NoError(t, migrator.Up())
+	assert.Equal(t, []int{1, 2, 3}, appliedVersions(t, migrator))
+	assert.True(t, db.Migrator().HasTable("third"))
+
+	// Up is idempotent
+	require.NoError(t, migrator.Up())
+
+	require.NoError(t, migrator.Down())
+	assert.Equal(t, []int{1, 2}, appliedVersions(t, migrator))
+	assert.False(t, db.Migrator().HasTable("third"))
+
+	require.NoError(t, migrator.To(0))
+	assert.Empty(t, appliedVersions(t, migrator))
+	assert.False(t, db.Migrator().HasTable("first"))
+
+	require.NoError(t, migrator.To(2))
+	assert.Equal(t, []int{1, 2}, appliedVersions(t, migrator))
+
+	assert.Error(t, migrator.To(4))
+}
+
+func TestMigrator_FailedMigrationIsRolledBack(t *testing.T) {
+	db := openSQLite(t)
+	list := testMigrations()
+	list = append(list, database.Migration{
+		Version: 4,
+		Name:    "broken",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.Exec("CREATE TABLE fourth (id INTEGER PRIMARY KEY)").Error; err != nil {
+				return err
+			}
+			return errors.New("boom")
+		},
+	})
+	migrator := database.NewMigratorWith(db, list)
+
+	err := migrator.Up()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "4_broken")
+
+	assert.Equal(t, []int{1, 2, 3}, appliedVersions(t, migrator))
+	assert.False(t, db.Migrator().HasTable("fourth"))
+}
+
+func TestMigrator_ShippedMigrationsRoundTrip(t *testing.T) {
+	db := openSQLite(t)
+	migrator := database.NewMigrator(db)
+
+	require.NoError(t, migrator.Up())
+	assert.True(t, db.Migrator().HasTable("tasks"))
+
+	require.NoError(t, migrator.To(0))
+	assert.False(t, db.Migrator().HasTable("tasks"))
+
+	require.NoError(t, migrator.Up())
+}
+
+func TestMigrator_WorkspacesAdoptExistingTasks(t *testing.T) {
+	db := openSQLite(t)
+	migrator := database.NewMigrator(db)
+	require.NoError(t, migrator.To(3))
+
+	require.NoError(t, db.Exec("INSERT INTO users (id, email, password_hash) VALUES (1, 'a@example.com', 'x'), (2, 'b@example.com', 'x')").Error)
+	require.NoError(t, db.Exec("INSERT INTO tasks (title, owner_id) VALUES ('a-task', 1), ('b-task', 2)").Error)
+
+	require.NoError(t, migrator.Up())
+
+	var rows []struct {
+		Title       string
+		WorkspaceID int
+		Personal    bool
+		OwnerID     int
+	}
+	err := db.Table("tasks").
+		Select("tasks.title, tasks.workspace_id, workspaces.personal, workspaces.owner_id").
+		Joins("JOIN workspaces ON workspaces.id = tasks.workspace_id").
+		Order("tasks.title").
+		Scan(&rows).Error
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	for i, ownerID := range []int{1, 2} {
+		assert.True(t, rows[i].Personal)
+		assert.Equal(t, ownerID, rows[i].OwnerID)
+	}
+
+	// Titles are now unique per workspace only
+	require.NoError(t, db.Exec("INSERT INTO tasks (title, owner_id, workspace_id) VALUES ('a-task', 2, ?)", rows[1].WorkspaceID).Error)
+	assert.Error(t, db.Exec("INSERT INTO tasks (title, owner_id, workspace_id) VALUES ('a-task', 1, ?)", rows[0].WorkspaceID).Error)
+	assert.True(t, db.Migrator().HasIndex("tasks", "idx_tasks_owner_id"))
+
+	// Users own their personal workspaces
+	var roles []string
+	require.NoError(t, db.Table("workspace_members").Distinct().Pluck("role", &roles).Error)
+	assert.Equal(t, []string{"owner"}, roles)
+}
+
+func TestMigrator_TrashedTasksFreeTheirTitles(t *testing.T) {
+	db := openSQLite(t)
+	migrator := database.NewMigrator(db)
+	require.NoError(t, migrator.Up())
+
+	require.NoError(t, db.Exec("INSERT INTO tasks (title, workspace_id, deleted_at) VALUES ('report', 1, CURRENT_TIMESTAMP)").Error)
+	require.NoError(t, db.Exec("INSERT INTO tasks (title, workspace_id) VALUES ('report', 1)").Error)
+	assert.Error(t, db.Exec("INSERT INTO tasks (title, workspace_id) VALUES ('report', 1)").Error)
+
+	// Rolling back purges the trash so the old constraint fits again
+	require.NoError(t, migrator.To(11))
+	var titles []string
+	require.NoError(t, db.Table("tasks").Pluck("title", &titles).Error)
+	assert.Equal(t, []string{"report"}, titles)
+	assert.False(t, db.Migrator().HasColumn("tasks", "deleted_at"))
+}
@@ -0,0 +1,161 @@
+package events
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"task/backend/models"
+	"task/backend/repository"
+)
+
+// relayBatch is how many outbox events Relay reads at a time.
+const relayBatch = 100
+
+// Handler runs in the transaction that publishes an event. It must use tx
+// for storage; an error rolls the whole transaction back.
+type Handler func(tx *repository.Store, event Event) error
+
+// AsyncHandler runs in the background once the transaction that published
+// an event has committed.
+type AsyncHandler func(event Event)
+
+// Bus delivers published events to its subscribers. Synchronous
+// subscribers share the publisher's transaction. Asynchronous ones are fed
+// from the transactional outbox, in outbox order and at least once: an
+// event that was committed reaches them even if the process dies before
+// relaying it, and may reach them twice if it dies during the relay.
+type Bus struct {
+	store *repository.Store
+
+	mu            sync.RWMutex
+	handlers      []Handler
+	asyncHandlers []AsyncHandler
+
+	// relayMu keeps relays from handing out the same events twice
+	relayMu sync.Mutex
+	wake    chan struct{}
+}
+
+// NewBus returns a bus that keeps its outbox in store.
+func NewBus(store *repository.Store) *Bus {
+	return &Bus{store: store, wake: make(chan struct{}, 1)}
+}
+
+// Subscribe adds a synchronous subscriber.
+func (b *Bus) Subscribe(handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers = append(b.handlers, handler)
+}
+
+// SubscribeAsync adds an asynchronous subscriber. Each subscriber gets the
+// events one at a time, and should hand them off rather than block.
+func (b *Bus) SubscribeAsync(handler AsyncHandler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.asyncHandlers = append(b.asyncHandlers, handler)
+}
+
+// Transaction runs fn in a transaction of the bus's store and wakes Run
+// once it has committed, so the events fn published are relayed right away.
+func (b *Bus) Transaction(fn func(tx *repository.Store) error) error {
+	if err := b.store.Transaction(fn); err != nil {
+		return err
+	}
+	select {
+	case b.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Publish adds events to the outbox of tx and runs the synchronous
+// subscribers for each of them, in order. tx must come from Transaction.
+func (b *Bus) Publish(tx *repository.Store, events ...Event) error {
+	b.mu.RLock()
+	handlers := b.handlers
+	b.mu.RUnlock()
+
+	for _, event := range events {
+		stored, err := toOutbox(event)
+		if err != nil {
+			return err
+		}
+		if err := tx.Outbox.Add(&stored); err != nil {
+			return err
+		}
+		event.ID = stored.ID
+
+		for _, handler := range handlers {
+			if err := handler(tx, event); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Relay hands the events waiting in the outbox to the asynchronous
+// subscribers and removes them. It returns how many events it relayed.
+func (b *Bus) Relay() (int, error) {
+	b.relayMu.Lock()
+	defer b.relayMu.Unlock()
+
+	b.mu.RLock()
+	handlers := b.asyncHandlers
+	b.mu.RUnlock()
+
+	relayed := 0
+	for {
+		pending, err := b.store.Outbox.Pending(relayBatch)
+		if err != nil {
+			return relayed, err
+		}
+
+		ids := make([]int, len(pending))
+		for i, stored := range pending {
+			ids[i] = stored.ID
+			b.dispatch(handlers, stored)
+		}
+		if err := b.store.Outbox.Remove(ids); err != nil {
+			return relayed, err
+		}
+
+		relayed += len(pending)
+		if len(pending) < relayBatch {
+			return relayed, nil
+		}
+	}
+}
+
+// dispatch hands one stored event to the asynchronous subscribers. Events
+// that can't be decoded are logged and dropped so they don't block the
+// outbox.
+func (b *Bus) dispatch(handlers []AsyncHandler, stored models.OutboxEvent) {
+	event, err := fromOutbox(stored)
+	if err != nil {
+		log.Printf("Dropping outbox event: %v", err)
+		return
+	}
+	for _, handler := range handlers {
+		handler(event)
+	}
+}
+
+// Run relays events whenever a transaction has published some, and every
+// interval to pick up events left over from before a restart. It never
+// returns.
+func (b *Bus) Run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := b.Relay(); err != nil {
+			log.Printf("Relaying events failed: %v", err)
+		}
+		select {
+		case <-b.wake:
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package events
+
+import (
+	"encoding/json"
+
+	"task/backend/models"
+)
+
+// diffedFields are the task fields whose changes events carry, in the order
+// they are listed. Values are normalised so that equal values encode
+// equally.
+var diffedFields = []struct {
+	name  string
+	value func(task *models.Task) any
+}{
+	{"title", func(task *models.Task) any { return task.Title }},
+	{"description", func(task *models.Task) any { return task.Description }},
+	{"status", func(task *models.Task) any { return task.Status }},
+	{"priority", func(task *models.Task) any { return task.Priority }},
+	{"due_date", func(task *models.Task) any {
+		if task.DueDate == nil {
+			return nil
+		}
+		return task.DueDate.UTC()
+	}},
+	{"parent_id", func(task *models.Task) any {
+		if task.ParentID == nil {
+			return nil
+		}
+		return *task.ParentID
+	}},
+	{"recurrence", func(task *models.Task) any { return task.Recurrence }},
+	{"reminders", func(task *models.Task) any {
+		if task.Reminders == nil {
+			return []int{}
+		}
+		return task.Reminders
+	}},
+	{"tags", func(task *models.Task) any {
+		names := make([]string, len(task.Tags))
+		for i, tag := range task.Tags {
+			names[i] = tag.Name
+		}
+		return names
+	}},
+}
+
+// Diff returns the fields that differ between before and after. A nil
+// before stands for a new task: every field that is set counts as changed,
+// from null.
+func Diff(before, after *models.Task) []models.FieldChange {
+	created := before == nil
+	if created {
+		before = &models.Task{}
+	}
+
+	changes := make([]models.FieldChange, 0)
+	for _, field := range diffedFields {
+		old, current := field.value(before), field.value(after)
+		oldJSON, _ := json.Marshal(old)
+		currentJSON, _ := json.Marshal(current)
+		if string(oldJSON) == string(currentJSON) {
+			continue
+		}
+		if created {
+			old = nil
+		}
+		changes = append(changes, models.FieldChange{Field: field.name, Before: old, After: current})
+	}
+	return changes
+}
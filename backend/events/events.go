@@ -0,0 +1,174 @@
+// Package events is the in-process event bus of the task domain. Handlers
+// publish what happened to tasks in the transaction that made the change;
+// side effects like the task history and webhooks subscribe to it instead
+// of being called from every handler.
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"task/backend/models"
+)
+
+type Type string
+
+const (
+	TypeTaskCreated   Type = "task.created"
+	TypeTaskUpdated   Type = "task.updated"
+	TypeStatusChanged Type = "task.status_changed"
+	TypeTaskDeleted   Type = "task.deleted"
+	TypeTaskRestored  Type = "task.restored"
+	TypeTaskPurged    Type = "task.purged"
+	TypeTaskReminder  Type = "task.reminder"
+)
+
+// Payload is one of the event types below.
+type Payload interface {
+	Type() Type
+	subject() models.Task
+}
+
+// TaskCreated is published when a task is created, including the next
+// occurrence of a recurring task.
+type TaskCreated struct {
+	Task models.Task `json:"task"`
+}
+
+// TaskUpdated is published when a task changes. Before is the task as it
+// was, and Changes lists the changed fields; updates that change none of
+// them aren't published.
+type TaskUpdated struct {
+	Task    models.Task          `json:"task"`
+	Before  models.Task          `json:"before"`
+	Changes []models.FieldChange `json:"changes"`
+}
+
+// StatusChanged is published after TaskUpdated when the update moved the
+// task to another status.
+type StatusChanged struct {
+	Task models.Task       `json:"task"`
+	From models.TaskStatus `json:"from"`
+	To   models.TaskStatus `json:"to"`
+}
+
+// TaskDeleted is published when a task is moved to the trash.
+type TaskDeleted struct {
+	Task models.Task `json:"task"`
+}
+
+// TaskRestored is published when a task comes back from the trash. Changes
+// lists the fields changed on the way, like a new title.
+type TaskRestored struct {
+	Task    models.Task          `json:"task"`
+	Changes []models.FieldChange `json:"changes"`
+}
+
+// TaskPurged is published when a task is deleted for good.
+type TaskPurged struct {
+	Task models.Task `json:"task"`
+}
+
+// TaskReminder is published by the reminder scheduler when a task is about
+// to be due, MinutesBefore minutes from its due date, or when it becomes
+// overdue, with MinutesBefore 0. Its actor is 0, for the server.
+type TaskReminder struct {
+	Task          models.Task `json:"task"`
+	MinutesBefore int         `json:"minutes_before"`
+}
+
+func (TaskCreated) Type() Type   { return TypeTaskCreated }
+func (TaskUpdated) Type() Type   { return TypeTaskUpdated }
+func (StatusChanged) Type() Type { return TypeStatusChanged }
+func (TaskDeleted) Type() Type   { return TypeTaskDeleted }
+func (TaskRestored) Type() Type  { return TypeTaskRestored }
+func (TaskPurged) Type() Type    { return TypeTaskPurged }
+func (TaskReminder) Type() Type  { return TypeTaskReminder }
+
+func (e TaskCreated) subject() models.Task   { return e.Task }
+func (e TaskUpdated) subject() models.Task   { return e.Task }
+func (e StatusChanged) subject() models.Task { return e.Task }
+func (e TaskDeleted) subject() models.Task   { return e.Task }
+func (e TaskRestored) subject() models.Task  { return e.Task }
+func (e TaskPurged) subject() models.Task    { return e.Task }
+func (e TaskReminder) subject() models.Task  { return e.Task }
+
+// decoders turn a stored payload back into its event type.
+var decoders = map[Type]func(data []byte) (Payload, error){
+	TypeTaskCreated:   decode[TaskCreated],
+	TypeTaskUpdated:   decode[TaskUpdated],
+	TypeStatusChanged: decode[StatusChanged],
+	TypeTaskDeleted:   decode[TaskDeleted],
+	TypeTaskRestored:  decode[TaskRestored],
+	TypeTaskPurged:    decode[TaskPurged],
+	TypeTaskReminder:  decode[TaskReminder],
+}
+
+func decode[T Payload](data []byte) (Payload, error) {
+	var payload T
+	err := json.Unmarshal(data, &payload)
+	return payload, err
+}
+
+// Event is a payload together with where it comes from.
+type Event struct {
+	// ID is the event's outbox ID, set once it is published
+	ID          int
+	WorkspaceID int
+	ActorID     int
+	OccurredAt  time.Time
+	Payload     Payload
+}
+
+// New returns an event that happens now.
+func New(workspaceID, actorID int, payload Payload) Event {
+	return Event{
+		WorkspaceID: workspaceID,
+		ActorID:     actorID,
+		OccurredAt:  time.Now(),
+		Payload:     payload,
+	}
+}
+
+// Type returns the type of the event's payload.
+func (e Event) Type() Type {
+	return e.Payload.Type()
+}
+
+// Task returns the task the event is about, as it is after the event.
+func (e Event) Task() models.Task {
+	return e.Payload.subject()
+}
+
+func toOutbox(event Event) (models.OutboxEvent, error) {
+	payload, err := json.Marshal(event.Payload)
+	if err != nil {
+		return models.OutboxEvent{}, err
+	}
+	return models.OutboxEvent{
+		Type:        string(event.Type()),
+		WorkspaceID: event.WorkspaceID,
+		ActorID:     event.ActorID,
+		Payload:     payload,
+		OccurredAt:  event.OccurredAt,
+	}, nil
+}
+
+func fromOutbox(stored models.OutboxEvent) (Event, error) {
+	decoder, ok := decoders[Type(stored.Type)]
+	if !ok {
+		return Event{}, fmt.Errorf("unknown event type %q", stored.Type)
+	}
+	payload, err := decoder(stored.Payload)
+	if err != nil {
+		return Event{}, fmt.Errorf("decoding %s event %d: %w", stored.Type, stored.ID, err)
+	}
+	return Event{
+		ID:          stored.ID,
+		WorkspaceID: stored.WorkspaceID,
+		ActorID:     stored.ActorID,
+		OccurredAt:  stored.OccurredAt,
+		Payload:     payload,
+	}, nil
+}
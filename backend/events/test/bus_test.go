@@ -0,0 +1,161 @@
+package events
+
+import (
+	"errors"
+	"testing"
+
+	"task/backend/database"
+	"task/backend/events"
+	"task/backend/models"
+	"task/backend/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm/logger"
+)
+
+func openSQLite(t *testing.T) *repository.Store {
+	db, err := database.Open(database.DriverSQLite, ":memory:")
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	db.Logger = logger.Default.LogMode(logger.Silent)
+	require.NoError(t, database.NewMigrator(db).Up())
+	return repository.NewGormStore(db)
+}
+
+func newTask(title string) models.Task {
+	return models.Task{
+		Title:       title,
+		Status:      models.TaskStatusPending,
+		Priority:    models.TaskPriorityMedium,
+		OwnerID:     1,
+		WorkspaceID: 1,
+	}
+}
+
+// create stores a task and publishes TaskCreated for it.
+func create(bus *events.Bus, task *models.Task) error {
+	return bus.Transaction(func(tx *repository.Store) error {
+		if err := tx.Tasks.Create(task); err != nil {
+			return err
+		}
+		return bus.Publish(tx, events.New(task.WorkspaceID, task.OwnerID, events.TaskCreated{Task: *task}))
+	})
+}
+
+func pending(t *testing.T, store *repository.Store) []models.OutboxEvent {
+	stored, err := store.Outbox.Pending(100)
+	require.NoError(t, err)
+	return stored
+}
+
+func TestPublish_RunsSubscribersInTransaction(t *testing.T) {
+	store := repository.NewMemoryStore()
+	bus := events.NewBus(store)
+
+	var seen []events.Type
+	bus.Subscribe(func(tx *repository.Store, event events.Event) error {
+		assert.NotZero(t, event.ID, "events are stored before subscribers run")
+		seen = append(seen, event.Type())
+		return nil
+	})
+
+	task := newTask("write-docs")
+	before := task
+	task.Status = models.TaskStatusCompleted
+	err := bus.Transaction(func(tx *repository.Store) error {
+		return bus.Publish(tx,
+			events.New(1, 1, events.TaskUpdated{Task: task, Before: before, Changes: events.Diff(&before, &task)}),
+			events.New(1, 1, events.StatusChanged{Task: task, From: before.Status, To: task.Status}),
+		)
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []events.Type{events.TypeTaskUpdated, events.TypeStatusChanged}, seen)
+	assert.Len(t, pending(t, store), 2)
+}
+
+func TestTransaction_SubscriberErrorRollsBack(t *testing.T) {
+	store := openSQLite(t)
+	bus := events.NewBus(store)
+	failure := errors.New("subscriber failed")
+	bus.Subscribe(func(tx *repository.Store, event events.Event) error {
+		return failure
+	})
+
+	task := newTask("write-docs")
+	assert.ErrorIs(t, create(bus, &task), failure)
+
+	_, err := store.Tasks.GetByTitle(1, "write-docs")
+	assert.ErrorIs(t, err, repository.ErrNotFound, "the task is rolled back with its event")
+	assert.Empty(t, pending(t, store))
+}
+
+func TestRelay_DeliversDecodedEventsInOrder(t *testing.T) {
+	store := openSQLite(t)
+	bus := events.NewBus(store)
+
+	var relayed []events.Event
+	bus.SubscribeAsync(func(event events.Event) {
+		relayed = append(relayed, event)
+	})
+
+	first, second := newTask("first"), newTask("second")
+	require.NoError(t, create(bus, &first))
+	require.NoError(t, create(bus, &second))
+	assert.Empty(t, relayed, "asynchronous subscribers wait for the relay")
+
+	count, err := bus.Relay()
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	require.Len(t, relayed, 2)
+
+	created, ok := relayed[0].Payload.(events.TaskCreated)
+	require.True(t, ok, "payloads keep their type through the outbox")
+	assert.Equal(t, "first", created.Task.Title)
+	assert.Equal(t, first.ID, relayed[0].Task().ID)
+	assert.Equal(t, "second", relayed[1].Task().Title)
+	assert.Less(t, relayed[0].ID, relayed[1].ID)
+
+	assert.Empty(t, pending(t, store))
+	count, err = bus.Relay()
+	require.NoError(t, err)
+	assert.Zero(t, count)
+}
+
+func TestRelay_PicksUpEventsAfterRestart(t *testing.T) {
+	store := openSQLite(t)
+
+	// The first process commits the event but stops before relaying it
+	task := newTask("write-docs")
+	require.NoError(t, create(events.NewBus(store), &task))
+
+	restarted := events.NewBus(store)
+	var relayed []events.Type
+	restarted.SubscribeAsync(func(event events.Event) {
+		relayed = append(relayed, event.Type())
+	})
+
+	_, err := restarted.Relay()
+	require.NoError(t, err)
+	assert.Equal(t, []events.Type{events.TypeTaskCreated}, relayed)
+}
+
+func TestDiff(t *testing.T) {
+	before := newTask("write-docs")
+	after := before
+	after.Title = "write-more-docs"
+	after.Tags = []models.Tag{{Name: "docs"}}
+
+	assert.Equal(t, []models.FieldChange{
+		{Field: "title", Before: "write-docs", After: "write-more-docs"},
+		{Field: "tags", Before: []string{}, After: []string{"docs"}},
+	}, events.Diff(&before, &after))
+	assert.Empty(t, events.Diff(&before, &before))
+
+	created := events.Diff(nil, &before)
+	assert.Equal(t, models.FieldChange{Field: "title", Before: nil, After: "write-docs"}, created[0])
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"errors"
+	"time"
+
+	"task/backend/auth"
+	"task/backend/models"
+	"task/backend/repository"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+// APITokenHandler lets users create, list and revoke personal API tokens.
+type APITokenHandler struct {
+	Repo     repository.APITokenRepository
+	validate *validator.Validate
+}
+
+func NewAPITokenHandler(repo repository.APITokenRepository) *APITokenHandler {
+	return &APITokenHandler{Repo: repo, validate: newValidator()}
+}
+
+func (h *APITokenHandler) CreateToken(c *fiber.Ctx) error {
+	request := new(models.CreateAPITokenRequest)
+	if err := c.BodyParser(request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid body"})
+	}
+
+	if err := h.validate.Struct(request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	plaintext, hash, err := auth.GenerateAPIToken()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not create token"})
+	}
+
+	token := models.APIToken{
+		UserID:    auth.UserID(c),
+		Name:      request.Name,
+		Prefix:    plaintext[:len(auth.APITokenPrefix)+8],
+		TokenHash: hash,
+		Scope:     request.Scope,
+		ExpiresAt: request.ExpiresAt,
+		CreatedAt: time.Now(),
+	}
+
+	if err := h.Repo.Create(&token); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not create token"})
+	}
+
+	// The plaintext token is only ever returned here
+	return c.Status(fiber.StatusCreated).JSON(models.CreateAPITokenResponse{
+		APIToken: token,
+		Token:    plaintext,
+	})
+}
+
+func (h *APITokenHandler) ListTokens(c *fiber.Ctx) error {
+	tokens, err := h.Repo.ListByUser(auth.UserID(c))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve tokens"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(tokens)
+}
+
+func (h *APITokenHandler) RevokeToken(c *fiber.Ctx) error {
+	tokenID, err := c.ParamsInt("id")
+	if err != nil || tokenID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid token ID"})
+	}
+
+	if err := h.Repo.Delete(auth.UserID(c), tokenID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Token not found"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not revoke token"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Token revoked successfully"})
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"errors"
+
+	"task/backend/auth"
+	"task/backend/models"
+	"task/backend/repository"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetTaskHistory returns the change history of a task, newest first. The
+// history of tasks in the trash stays available.
+func (h *TaskHandler) GetTaskHistory(c *fiber.Ctx) error {
+	taskID, err := c.ParamsInt("id")
+	if err != nil || taskID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid task ID"})
+	}
+
+	task, err := h.findTask(c, taskID)
+	if errors.Is(err, repository.ErrNotFound) {
+		if task, err = h.Repo.GetDeleted(taskID); err == nil && task.WorkspaceID != auth.WorkspaceID(c) {
+			err = repository.ErrNotFound
+		}
+	}
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Task not found"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve task"})
+	}
+
+	page, size := pagination(c)
+	entries, total, err := h.Audit.ListForTask(task.ID, page, size)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve history"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.AuditResponse{
+		Entries: entries,
+		Total:   total,
+		Page:    page,
+		Size:    size,
+	})
+}
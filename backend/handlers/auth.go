@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"task/backend/auth"
+	"task/backend/models"
+	"task/backend/repository"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+// AuthHandler serves signup, login and token refresh.
+type AuthHandler struct {
+	Users    repository.UserRepository
+	Tokens   *auth.TokenManager
+	validate *validator.Validate
+}
+
+func NewAuthHandler(users repository.UserRepository, tokens *auth.TokenManager) *AuthHandler {
+	return &AuthHandler{Users: users, Tokens: tokens, validate: newValidator()}
+}
+
+func (h *AuthHandler) Signup(c *fiber.Ctx) error {
+	request := new(models.SignupRequest)
+	if err := c.BodyParser(request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid body"})
+	}
+
+	request.Email = strings.ToLower(strings.TrimSpace(request.Email))
+	if err := h.validate.Struct(request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	hash, err := auth.HashPassword(request.Password)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not create user"})
+	}
+
+	user := models.User{
+		Email:        request.Email,
+		PasswordHash: hash,
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+	}
+
+	if err := h.Users.Create(&user); err != nil {
+		if errors.Is(err, repository.ErrDuplicateEmail) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "User with this email already exists"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not create user"})
+	}
+
+	return h.respondWithTokens(c, fiber.StatusCreated, &user)
+}
+
+func (h *AuthHandler) Login(c *fiber.Ctx) error {
+	request := new(models.LoginRequest)
+	if err := c.BodyParser(request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid body"})
+	}
+
+	if err := h.validate.Struct(request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	user, err := h.Users.GetByEmail(strings.ToLower(strings.TrimSpace(request.Email)))
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not log in"})
+	}
+	// Same response for unknown emails and wrong passwords
+	if user == nil || !auth.CheckPassword(user.PasswordHash, request.Password) {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid email or password"})
+	}
+
+	return h.respondWithTokens(c, fiber.StatusOK, user)
+}
+
+func (h *AuthHandler) Refresh(c *fiber.Ctx) error {
+	request := new(models.RefreshRequest)
+	if err := c.BodyParser(request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid body"})
+	}
+
+	if err := h.validate.Struct(request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	userID, err := h.Tokens.Parse(request.RefreshToken, auth.TokenTypeRefresh)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid or expired token"})
+	}
+
+	// The account may have been removed since the token was issued
+	user, err := h.Users.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid or expired token"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not refresh token"})
+	}
+
+	return h.respondWithTokens(c, fiber.StatusOK, user)
+}
+
+func (h *AuthHandler) respondWithTokens(c *fiber.Ctx, status int, user *models.User) error {
+	access, refresh, err := h.Tokens.Issue(user.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not issue token"})
+	}
+
+	return c.Status(status).JSON(models.AuthResponse{
+		User:         user,
+		AccessToken:  access,
+		RefreshToken: refresh,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(h.Tokens.AccessTTL().Seconds()),
+	})
+}
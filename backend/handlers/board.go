@@ -0,0 +1,343 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"task/backend/auth"
+	"task/backend/models"
+	"task/backend/repository"
+	"task/backend/stream"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	// boardSendBuffer is how many replies and presence updates may wait
+	// for a connection before it is closed for falling behind.
+	boardSendBuffer = 64
+	// boardWriteWait is how long a single write may take.
+	boardWriteWait = 10 * time.Second
+	// boardPongWait is how long a connection may stay silent; pings are
+	// sent often enough for clients to answer in time.
+	boardPongWait   = 60 * time.Second
+	boardPingPeriod = boardPongWait * 9 / 10
+	// boardReadLimit is the largest message a client may send.
+	boardReadLimit = 64 << 10
+	// defaultBoardRecheck is how often a connection's credentials and
+	// membership are checked again.
+	defaultBoardRecheck = time.Minute
+)
+
+const boardConnKey = "board.conn"
+
+// BoardHandler serves the WebSocket of the live task board. Viewers of a
+// workspace's board get its task events and see who else is viewing it,
+// and can change tasks over the same connection.
+type BoardHandler struct {
+	// App runs the task changes sent over a connection through the same
+	// routes, validation and permission checks as REST requests.
+	App    *fiber.App
+	Broker *stream.Broker
+	Users  repository.UserRepository
+	// Recheck is how often a connection checks that its token is still
+	// valid and its user may still read the board. Commands check first too.
+	Recheck time.Duration
+
+	upgrade fiber.Handler
+
+	mu     sync.Mutex
+	boards map[int]map[*boardConn]struct{} // connections by workspace
+}
+
+func NewBoardHandler(app *fiber.App, broker *stream.Broker, users repository.UserRepository) *BoardHandler {
+	h := &BoardHandler{
+		App:     app,
+		Broker:  broker,
+		Users:   users,
+		Recheck: defaultBoardRecheck,
+		boards:  make(map[int]map[*boardConn]struct{}),
+	}
+	h.upgrade = websocket.New(h.serve)
+	return h
+}
+
+// boardConn is one connection to a board.
+type boardConn struct {
+	ws            *websocket.Conn
+	workspaceID   int
+	viewer        models.BoardViewer
+	authorization string
+	// path is the board route, through which authorized checks the
+	// connection again
+	path   string
+	filter repository.TaskFilter
+	// send queues the messages that don't come from the broker
+	send chan []byte
+}
+
+// Connect upgrades a request to the board WebSocket. The board shows the
+// tasks of the request's workspace, narrowed by the filters GetAllTasks
+// accepts.
+func (h *BoardHandler) Connect(c *fiber.Ctx) error {
+	if !websocket.IsWebSocketUpgrade(c) {
+		return c.Status(fiber.StatusUpgradeRequired).JSON(fiber.Map{"error": "Expected a WebSocket upgrade"})
+	}
+
+	filter, ok := taskFilter(c)
+	if !ok {
+		return nil
+	}
+
+	user, err := h.Users.GetByID(auth.UserID(c))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not open board"})
+	}
+
+	// Fiber reuses the memory of request strings once the handler returns,
+	// and the connection outlives it
+	filter.Title = strings.Clone(filter.Title)
+	filter.Status = strings.Clone(filter.Status)
+	filter.Search = strings.Clone(filter.Search)
+	filter.Priority = models.TaskPriority(strings.Clone(string(filter.Priority)))
+	for i, tag := range filter.Tags {
+		filter.Tags[i] = strings.Clone(tag)
+	}
+	c.Locals(boardConnKey, &boardConn{
+		workspaceID:   filter.WorkspaceID,
+		viewer:        models.BoardViewer{UserID: user.ID, Email: user.Email},
+		authorization: strings.Clone(c.Get(fiber.HeaderAuthorization)),
+		path:          strings.Clone(c.Path()),
+		filter:        filter,
+		send:          make(chan []byte, boardSendBuffer),
+	})
+	return h.upgrade(c)
+}
+
+func (h *BoardHandler) serve(ws *websocket.Conn) {
+	conn := ws.Locals(boardConnKey).(*boardConn)
+	conn.ws = ws
+
+	sub := h.Broker.Connect(conn.workspaceID, 0, false)
+	h.join(conn)
+
+	done := make(chan struct{})
+	written := make(chan struct{})
+	go func() {
+		defer close(written)
+		h.write(conn, sub, done)
+	}()
+
+	h.read(conn)
+
+	// The connection must be off the board before the writer stops, since
+	// ws can't be used once serve returns
+	h.leave(conn)
+	h.Broker.Disconnect(sub.Client)
+	close(done)
+	<-written
+}
+
+// read handles the client's commands until the connection fails or
+// closes.
+func (h *BoardHandler) read(conn *boardConn) {
+	conn.ws.SetReadLimit(boardReadLimit)
+	conn.ws.SetReadDeadline(time.Now().Add(boardPongWait))
+	conn.ws.SetPongHandler(func(string) error {
+		return conn.ws.SetReadDeadline(time.Now().Add(boardPongWait))
+	})
+
+	for {
+		_, data, err := conn.ws.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				log.Printf("Board connection of user %d failed: %v", conn.viewer.UserID, err)
+			}
+			return
+		}
+
+		var cmd models.BoardCommand
+		if err := json.Unmarshal(data, &cmd); err != nil {
+			h.enqueue(conn, models.BoardMessage{Type: models.BoardError, Error: "Invalid message"})
+			continue
+		}
+		if !h.authorized(conn) {
+			h.revoke(conn)
+			return
+		}
+		switch cmd.Type {
+		case models.BoardUpdateTask:
+			h.enqueue(conn, h.updateTask(conn, cmd))
+		default:
+			h.enqueue(conn, models.BoardMessage{Type: models.BoardError, Ref: cmd.Ref, Error: fmt.Sprintf("Unknown message type %q", cmd.Type)})
+		}
+	}
+}
+
+// updateTask runs an update_task command as PUT /tasks/:id on behalf of
+// the connection's user.
+func (h *BoardHandler) updateTask(conn *boardConn, cmd models.BoardCommand) models.BoardMessage {
+	status, body := h.do(conn, fiber.MethodPut, "/tasks/"+strconv.Itoa(cmd.TaskID), cmd.Changes)
+	return models.BoardMessage{
+		Type:   models.BoardResult,
+		Ref:    cmd.Ref,
+		Status: status,
+		Body:   json.RawMessage(body),
+	}
+}
+
+// authorized reports whether the connection would still be let onto the
+// board: its token hasn't expired or been revoked and its user may still
+// read the workspace's tasks. The board route answers requests that pass
+// its checks without asking for an upgrade with 426.
+func (h *BoardHandler) authorized(conn *boardConn) bool {
+	status, _ := h.do(conn, fiber.MethodGet, conn.path, nil)
+	return status == fiber.StatusUpgradeRequired
+}
+
+// revoke closes a connection that is no longer authorized. Clients may
+// reconnect with a fresh token.
+func (h *BoardHandler) revoke(conn *boardConn) {
+	conn.ws.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "Not authorized anymore, reconnect"),
+		time.Now().Add(boardWriteWait))
+}
+
+// do runs a request through the app with the connection's credentials and
+// returns the response status and body.
+func (h *BoardHandler) do(conn *boardConn, method, uri string, body []byte) (int, []byte) {
+	var req fasthttp.Request
+	req.Header.SetMethod(method)
+	req.SetRequestURI(uri)
+	req.Header.SetContentType(fiber.MIMEApplicationJSON)
+	req.Header.Set(fiber.HeaderAuthorization, conn.authorization)
+	req.Header.Set(auth.WorkspaceHeader, strconv.Itoa(conn.workspaceID))
+	req.SetBody(body)
+
+	var ctx fasthttp.RequestCtx
+	ctx.Init(&req, nil, nil)
+	h.App.Handler()(&ctx)
+	return ctx.Response.StatusCode(), slices.Clone(ctx.Response.Body())
+}
+
+// write sends the board's task events and the queued messages to the
+// client, pings it and checks that it is still authorized, until done is
+// closed or a write or check fails.
+func (h *BoardHandler) write(conn *boardConn, sub stream.Subscription, done <-chan struct{}) {
+	ticker := time.NewTicker(boardPingPeriod)
+	defer ticker.Stop()
+	recheck := time.NewTicker(h.Recheck)
+	defer recheck.Stop()
+	// Closing makes read return if the writer stops first
+	defer conn.ws.Close()
+
+	for {
+		var err error
+		select {
+		case msg, open := <-sub.Messages():
+			if !open {
+				// The broker dropped the connection for falling behind
+				conn.ws.WriteControl(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "Too slow, reconnect"),
+					time.Now().Add(boardWriteWait))
+				return
+			}
+			if !visible(conn.filter, msg) {
+				continue
+			}
+			err = h.writeJSON(conn, models.BoardMessage{Type: string(msg.Event), ID: msg.ID, Data: msg.Data})
+		case data := <-conn.send:
+			conn.ws.SetWriteDeadline(time.Now().Add(boardWriteWait))
+			err = conn.ws.WriteMessage(websocket.TextMessage, data)
+		case <-ticker.C:
+			err = conn.ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(boardWriteWait))
+		case <-recheck.C:
+			if !h.authorized(conn) {
+				h.revoke(conn)
+				return
+			}
+		case <-done:
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (h *BoardHandler) writeJSON(conn *boardConn, msg models.BoardMessage) error {
+	conn.ws.SetWriteDeadline(time.Now().Add(boardWriteWait))
+	return conn.ws.WriteJSON(msg)
+}
+
+// enqueue queues a message for the writer. A client whose queue is full
+// isn't reading and is disconnected rather than waited for.
+func (h *BoardHandler) enqueue(conn *boardConn, msg models.BoardMessage) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		log.Printf("Encoding board message: %v", err)
+		return
+	}
+	select {
+	case conn.send <- data:
+	default:
+		conn.ws.Close()
+	}
+}
+
+func (h *BoardHandler) join(conn *boardConn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	board := h.boards[conn.workspaceID]
+	if board == nil {
+		board = make(map[*boardConn]struct{})
+		h.boards[conn.workspaceID] = board
+	}
+	board[conn] = struct{}{}
+	h.announce(board)
+}
+
+func (h *BoardHandler) leave(conn *boardConn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	board := h.boards[conn.workspaceID]
+	delete(board, conn)
+	if len(board) == 0 {
+		delete(h.boards, conn.workspaceID)
+		return
+	}
+	h.announce(board)
+}
+
+// announce sends everyone on a board who is viewing it. A user with
+// several connections is listed once. h.mu must be held.
+func (h *BoardHandler) announce(board map[*boardConn]struct{}) {
+	viewers := make([]models.BoardViewer, 0, len(board))
+	for conn := range board {
+		if !slices.Contains(viewers, conn.viewer) {
+			viewers = append(viewers, conn.viewer)
+		}
+	}
+	slices.SortFunc(viewers, func(a, b models.BoardViewer) int { return a.UserID - b.UserID })
+
+	for conn := range board {
+		h.enqueue(conn, models.BoardMessage{Type: models.BoardPresence, Viewers: viewers})
+	}
+}
+
+// Viewers returns how many connections are open on a workspace's board.
+func (h *BoardHandler) Viewers(workspaceID int) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.boards[workspaceID])
+}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"errors"
+
+	"task/backend/events"
+	"task/backend/models"
+	"task/backend/repository"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ListDependencies returns the tasks a task depends on and the tasks that
+// depend on it.
+func (h *TaskHandler) ListDependencies(c *fiber.Ctx) error {
+	task, ok := h.taskFromPath(c)
+	if !ok {
+		return nil
+	}
+
+	dependsOn, err := h.Repo.Dependencies(task.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve dependencies"})
+	}
+	dependents, err := h.Repo.Dependents(task.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve dependencies"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.TaskDependencies{DependsOn: dependsOn, Dependents: dependents})
+}
+
+// AddDependency makes the task in the path wait for another task of the
+// workspace.
+func (h *TaskHandler) AddDependency(c *fiber.Ctx) error {
+	request := new(models.AddDependencyRequest)
+	if err := c.BodyParser(request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid body"})
+	}
+	if err := h.validate.Struct(request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	task, ok := h.taskFromPath(c)
+	if !ok {
+		return nil
+	}
+
+	if request.DependsOnID == task.ID {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "A task cannot depend on itself"})
+	}
+	if _, err := h.findTask(c, request.DependsOnID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Dependency task not found"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not add dependency"})
+	}
+
+	err := h.changeDependencies(c, task, func(tasks repository.TaskRepository) error {
+		return tasks.AddDependency(task.ID, request.DependsOnID)
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrDependencyCycle):
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Dependency would create a cycle"})
+		case errors.Is(err, repository.ErrDuplicateDependency):
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Dependency already exists"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not add dependency"})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(models.TaskDependency{TaskID: task.ID, DependsOnID: request.DependsOnID})
+}
+
+// RemoveDependency lets the task in the path stop waiting for another task.
+func (h *TaskHandler) RemoveDependency(c *fiber.Ctx) error {
+	dependsOnID, err := c.ParamsInt("dependsOnId")
+	if err != nil || dependsOnID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid dependency ID"})
+	}
+
+	task, ok := h.taskFromPath(c)
+	if !ok {
+		return nil
+	}
+
+	err = h.changeDependencies(c, task, func(tasks repository.TaskRepository) error {
+		return tasks.RemoveDependency(task.ID, dependsOnID)
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Dependency not found"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not remove dependency"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Dependency removed successfully"})
+}
+
+// changeDependencies runs change in a transaction and publishes it as a
+// TaskUpdated event of task. Its changes list the IDs of the tasks task
+// depends on, as depends_on, and the event's task has the new Blocked flag.
+func (h *TaskHandler) changeDependencies(c *fiber.Ctx, task *models.Task, change func(tasks repository.TaskRepository) error) error {
+	return h.Events.Transaction(func(tx *repository.Store) error {
+		before, err := dependencyIDs(tx.Tasks, task.ID)
+		if err != nil {
+			return err
+		}
+		if err := change(tx.Tasks); err != nil {
+			return err
+		}
+		after, err := dependencyIDs(tx.Tasks, task.ID)
+		if err != nil {
+			return err
+		}
+		updated, err := tx.Tasks.GetByID(task.ID)
+		if err != nil {
+			return err
+		}
+
+		changes := []models.FieldChange{{Field: "depends_on", Before: before, After: after}}
+		return h.Events.Publish(tx, h.event(c, events.TaskUpdated{Task: *updated, Before: *task, Changes: changes}))
+	})
+}
+
+func dependencyIDs(tasks repository.TaskRepository, taskID int) ([]int, error) {
+	dependencies, err := tasks.Dependencies(taskID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int, len(dependencies))
+	for i, dependency := range dependencies {
+		ids[i] = dependency.ID
+	}
+	return ids, nil
+}
+
+// blockers returns the IDs of the unresolved tasks taskID depends on.
+func (h *TaskHandler) blockers(taskID int) ([]int, error) {
+	dependencies, err := h.Repo.Dependencies(taskID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int, 0, len(dependencies))
+	for _, dependency := range dependencies {
+		if !dependency.Status.Resolved() {
+			ids = append(ids, dependency.ID)
+		}
+	}
+	return ids, nil
+}
+
+// taskFromPath loads the task named by the :id parameter. It writes the
+// error response itself and reports whether the caller may continue.
+func (h *TaskHandler) taskFromPath(c *fiber.Ctx) (*models.Task, bool) {
+	taskID, err := c.ParamsInt("id")
+	if err != nil || taskID <= 0 {
+		c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid task ID"})
+		return nil, false
+	}
+
+	task, err := h.findTask(c, taskID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Task not found"})
+			return nil, false
+		}
+		c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve task"})
+		return nil, false
+	}
+	return task, true
+}
@@ -0,0 +1,26 @@
+package handlers
+
+import "github.com/gofiber/fiber/v2"
+
+// Limits of the page and size query parameters of listings. They keep the
+// offsets and result sets that requests can ask for bounded.
+const (
+	defaultPageSize = 10
+	maxPageSize     = 100
+	maxPage         = 100_000
+)
+
+// pagination reads the page and size query parameters. Missing or invalid
+// values fall back to page 1 and size 10, and values above maxPage and
+// maxPageSize are lowered to them.
+func pagination(c *fiber.Ctx) (page, size int) {
+	page = c.QueryInt("page", 1)
+	size = c.QueryInt("size", defaultPageSize)
+	if page <= 0 {
+		page = 1
+	}
+	if size <= 0 {
+		size = defaultPageSize
+	}
+	return min(page, maxPage), min(size, maxPageSize)
+}
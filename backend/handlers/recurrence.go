@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"task/backend/models"
+	"task/backend/recurrence"
+	"task/backend/repository"
+)
+
+// occurrenceSuffix matches the date, and the counter added on title
+// clashes, that occurrence titles end with.
+var occurrenceSuffix = regexp.MustCompile(`@\d{4}-\d{2}-\d{2}(-\d+)?$`)
+
+// maxTitleAttempts bounds the search for a free occurrence title.
+const maxTitleAttempts = 10
+
+// maxTitleLength is the longest title CreateTaskRequest accepts.
+const maxTitleLength = 200
+
+// normalizeRecurrence validates an RRULE and returns it in canonical form.
+// An empty rule stays empty.
+func normalizeRecurrence(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	rule, err := recurrence.Parse(value)
+	if err != nil {
+		return "", err
+	}
+	return rule.String(), nil
+}
+
+// nextOccurrence returns the task following task in its series, or nil when
+// the series has ended. The new task is not stored yet and has no title.
+func (h *TaskHandler) nextOccurrence(task *models.Task) (*models.Task, error) {
+	rule, err := recurrence.Parse(task.Recurrence)
+	if err != nil {
+		return nil, err
+	}
+	if task.DueDate == nil {
+		return nil, nil
+	}
+	dueDate, ok := rule.Next(*task.DueDate, task.Occurrence)
+	if !ok {
+		return nil, nil
+	}
+
+	workflow, err := repository.WorkflowFor(h.Workflows, task.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.Task{
+		Description: task.Description,
+		Status:      workflow.Initial,
+		Priority:    task.Priority,
+		DueDate:     &dueDate,
+		OwnerID:     task.OwnerID,
+		WorkspaceID: task.WorkspaceID,
+		ParentID:    task.ParentID,
+		Recurrence:  task.Recurrence,
+		SeriesID:    task.SeriesID,
+		Occurrence:  task.Occurrence + 1,
+		Reminders:   task.Reminders,
+		Tags:        task.Tags,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}, nil
+}
+
+// createOccurrence stores next in tx, naming it after the series and its due
+// date, e.g. weekly-report@2026-10-19. A counter is appended if another
+// task already has that title. The series name is shortened where needed to
+// keep titles within maxTitleLength.
+func createOccurrence(tx *repository.Store, next *models.Task, previousTitle string) error {
+	stem := []rune(occurrenceSuffix.ReplaceAllString(previousTitle, ""))
+	base := "@" + next.DueDate.Format("2006-01-02")
+	for attempt := 1; attempt <= maxTitleAttempts; attempt++ {
+		suffix := base
+		if attempt > 1 {
+			suffix = fmt.Sprintf("%s-%d", base, attempt)
+		}
+		next.Title = string(stem[:min(len(stem), maxTitleLength-len(suffix))]) + suffix
+
+		// Each attempt gets its own savepoint, as PostgreSQL aborts the
+		// whole transaction on a failed insert otherwise
+		err := tx.Transaction(func(attempt *repository.Store) error {
+			return attempt.Tasks.Create(next)
+		})
+		if !errors.Is(err, repository.ErrDuplicateTitle) {
+			return err
+		}
+	}
+	return fmt.Errorf("no free title for occurrence %q", next.Title)
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"time"
+
+	"task/backend/repository"
+	"task/backend/stream"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// defaultHeartbeat keeps idle streams from being closed by proxies.
+const defaultHeartbeat = 15 * time.Second
+
+type StreamHandler struct {
+	Broker *stream.Broker
+	// Heartbeat is how often an idle stream gets a comment line
+	Heartbeat time.Duration
+}
+
+func NewStreamHandler(broker *stream.Broker) *StreamHandler {
+	return &StreamHandler{Broker: broker, Heartbeat: defaultHeartbeat}
+}
+
+// StreamTasks sends the workspace's task changes as Server-Sent Events,
+// filtered like GetAllTasks. An update is sent when the task matches the
+// filter before or after it, so clients see tasks leave their view too.
+// Clients that reconnect with Last-Event-ID get the events they missed; when
+// those are no longer buffered they get a reset event and should reload.
+func (h *StreamHandler) StreamTasks(c *fiber.Ctx) error {
+	filter, ok := taskFilter(c)
+	if !ok {
+		return nil
+	}
+
+	var lastID uint64
+	resume := false
+	if header := c.Get("Last-Event-ID"); header != "" {
+		id, err := strconv.ParseUint(header, 10, 64)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid Last-Event-ID"})
+		}
+		lastID, resume = id, true
+	}
+
+	sub := h.Broker.Connect(filter.WorkspaceID, lastID, resume)
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	heartbeat := h.Heartbeat
+	conn := c.Context().Conn()
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer h.Broker.Disconnect(sub.Client)
+
+		// The server's write timeout covers a whole response, which would
+		// end the stream; each flush gets a deadline of its own instead.
+		// Flushing fails once the client has gone.
+		flush := func() error {
+			conn.SetWriteDeadline(time.Now().Add(heartbeat))
+			return w.Flush()
+		}
+
+		if sub.Gap {
+			fmt.Fprintf(w, "id: %d\nevent: reset\ndata: {}\n\n", sub.LastID)
+		}
+		for _, msg := range sub.Replay {
+			writeMessage(w, filter, msg)
+		}
+		if err := flush(); err != nil {
+			return
+		}
+
+		ticker := time.NewTicker(heartbeat)
+		defer ticker.Stop()
+		for {
+			select {
+			case msg, open := <-sub.Messages():
+				if !open {
+					return
+				}
+				writeMessage(w, filter, msg)
+			case <-ticker.C:
+				fmt.Fprint(w, ": heartbeat\n\n")
+			}
+			if err := flush(); err != nil {
+				return
+			}
+		}
+	})
+	return nil
+}
+
+// writeMessage writes msg as an event if it is visible through the filter.
+func writeMessage(w *bufio.Writer, filter repository.TaskFilter, msg stream.Message) {
+	if !visible(filter, msg) {
+		return
+	}
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", msg.ID, msg.Event, msg.Data)
+}
+
+// visible reports whether msg concerns a task that matches the filter,
+// either before or after the change.
+func visible(filter repository.TaskFilter, msg stream.Message) bool {
+	return filter.Matches(msg.Task) || msg.Before != nil && filter.Matches(*msg.Before)
+}
@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"errors"
+
+	"task/backend/events"
+	"task/backend/models"
+	"task/backend/repository"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var (
+	errParentNotFound = errors.New("parent task not found")
+	errParentCycle    = errors.New("parent task is the task itself or one of its subtasks")
+)
+
+// Delete modes for tasks that have subtasks, selected with ?children=
+const (
+	childrenRestrict = "restrict"
+	childrenCascade  = "cascade"
+	childrenOrphan   = "orphan"
+)
+
+// ListChildren returns the direct subtasks of a task with their progress.
+func (h *TaskHandler) ListChildren(c *fiber.Ctx) error {
+	tree, ok := h.taskTree(c)
+	if !ok {
+		return nil
+	}
+
+	children := make([]models.Task, len(tree.Children))
+	for i, child := range tree.Children {
+		children[i] = child.Task
+	}
+	return c.Status(fiber.StatusOK).JSON(children)
+}
+
+// GetTaskTree returns a task with all of its subtasks nested below it.
+func (h *TaskHandler) GetTaskTree(c *fiber.Ctx) error {
+	tree, ok := h.taskTree(c)
+	if !ok {
+		return nil
+	}
+	return c.Status(fiber.StatusOK).JSON(tree)
+}
+
+// taskTree loads the tree rooted at the task in the request path. It writes
+// the error response itself and reports whether the caller may continue.
+func (h *TaskHandler) taskTree(c *fiber.Ctx) (*models.TaskNode, bool) {
+	task, ok := h.taskFromPath(c)
+	if !ok {
+		return nil, false
+	}
+
+	tree, err := buildTree(h.Repo, *task)
+	if err != nil {
+		c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve subtasks"})
+		return nil, false
+	}
+	return tree, true
+}
+
+// buildTree loads the subtasks of root from tasks one level at a time and
+// rolls their progress up the tree.
+func buildTree(tasks repository.TaskRepository, root models.Task) (*models.TaskNode, error) {
+	tree := &models.TaskNode{Task: root}
+	level := []*models.TaskNode{tree}
+	for len(level) > 0 {
+		nodes := make(map[int]*models.TaskNode, len(level))
+		ids := make([]int, len(level))
+		for i, node := range level {
+			node.Children = []models.TaskNode{}
+			nodes[node.ID] = node
+			ids[i] = node.ID
+		}
+
+		children, err := tasks.ListChildren(ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, child := range children {
+			parent := nodes[*child.ParentID]
+			parent.Children = append(parent.Children, models.TaskNode{Task: child})
+		}
+
+		// Only take addresses once every append to a parent is done
+		level = level[:0:0]
+		for _, node := range nodes {
+			for i := range node.Children {
+				level = append(level, &node.Children[i])
+			}
+		}
+	}
+
+	rollUp(tree)
+	return tree, nil
+}
+
+// rollUp sets the progress of every node with subtasks and returns the
+// completion of node as a fraction.
+func rollUp(node *models.TaskNode) float64 {
+	if len(node.Children) == 0 {
+		if node.Status == models.TaskStatusCompleted {
+			return 1
+		}
+		return 0
+	}
+
+	progress := &models.TaskProgress{Total: len(node.Children)}
+	var sum float64
+	for i := range node.Children {
+		child := &node.Children[i]
+		done := rollUp(child)
+		if child.Status == models.TaskStatusCompleted {
+			progress.Completed++
+			done = 1
+		}
+		sum += done
+	}
+	fraction := sum / float64(progress.Total)
+	progress.Percent = float64(int(fraction*1000+0.5)) / 10
+	node.Progress = progress
+
+	if node.Status == models.TaskStatusCompleted {
+		return 1
+	}
+	return fraction
+}
+
+// checkParent verifies that parentID names a task of the workspace that may
+// hold taskID as a subtask. taskID is 0 for tasks not created yet.
+func (h *TaskHandler) checkParent(c *fiber.Ctx, taskID, parentID int) error {
+	// Walk up from the new parent; meeting the task itself means a cycle
+	for id := &parentID; id != nil; {
+		if *id == taskID {
+			return errParentCycle
+		}
+		ancestor, err := h.findTask(c, *id)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) && *id == parentID {
+				return errParentNotFound
+			}
+			return err
+		}
+		id = ancestor.ParentID
+	}
+	return nil
+}
+
+// parentError writes the response for an error returned by checkParent.
+func parentError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, errParentNotFound):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Parent task not found"})
+	case errors.Is(err, errParentCycle):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "A task cannot be moved under itself or its subtasks"})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve parent task"})
+}
+
+// deleteWithChildren deletes task, handling its subtasks according to mode.
+// It writes the response itself.
+func (h *TaskHandler) deleteWithChildren(c *fiber.Ctx, task *models.Task, mode string) error {
+	children, err := h.Repo.ListChildren([]int{task.ID})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not delete task"})
+	}
+
+	var remove func(tasks repository.TaskRepository) error
+	published := []events.Event{h.event(c, events.TaskDeleted{Task: *task})}
+	switch mode {
+	case childrenRestrict:
+		if len(children) > 0 {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error":    "Task has subtasks. Use children=cascade or children=orphan",
+				"children": len(children),
+			})
+		}
+		remove = func(tasks repository.TaskRepository) error { return tasks.Delete(task) }
+	case childrenOrphan:
+		remove = func(tasks repository.TaskRepository) error { return tasks.Delete(task) }
+		for i := range children {
+			orphan := children[i]
+			orphan.ParentID = nil
+			changes := events.Diff(&children[i], &orphan)
+			published = append(published, h.event(c, events.TaskUpdated{Task: orphan, Before: children[i], Changes: changes}))
+		}
+	case childrenCascade:
+		tree, err := buildTree(h.Repo, *task)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not delete task"})
+		}
+		remove = func(tasks repository.TaskRepository) error { return tasks.DeleteTree(task) }
+		for _, subtask := range treeTasks(tree)[1:] {
+			published = append(published, h.event(c, events.TaskDeleted{Task: subtask}))
+		}
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid children mode. Use restrict, cascade or orphan"})
+	}
+
+	err = h.Events.Transaction(func(tx *repository.Store) error {
+		if err := remove(tx.Tasks); err != nil {
+			return err
+		}
+		return h.Events.Publish(tx, published...)
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not delete task"})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Task deleted successfully"})
+}
+
+// treeTasks returns the tasks of a tree, each parent before its subtasks.
+func treeTasks(tree *models.TaskNode) []models.Task {
+	tasks := []models.Task{tree.Task}
+	for i := range tree.Children {
+		tasks = append(tasks, treeTasks(&tree.Children[i])...)
+	}
+	return tasks
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"task/backend/auth"
+	"task/backend/models"
+	"task/backend/repository"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+// TagHandler manages the tags of the request's workspace.
+type TagHandler struct {
+	Repo     repository.TagRepository
+	validate *validator.Validate
+}
+
+func NewTagHandler(repo repository.TagRepository) *TagHandler {
+	return &TagHandler{Repo: repo, validate: newValidator()}
+}
+
+func (h *TagHandler) ListTags(c *fiber.Ctx) error {
+	tags, err := h.Repo.List(auth.WorkspaceID(c))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve tags"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(tags)
+}
+
+func (h *TagHandler) CreateTag(c *fiber.Ctx) error {
+	request := new(models.CreateTagRequest)
+	if err := c.BodyParser(request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid body"})
+	}
+
+	request.Name = strings.TrimSpace(request.Name)
+	if err := h.validate.Struct(request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	tag := models.Tag{
+		WorkspaceID: auth.WorkspaceID(c),
+		Name:        request.Name,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+
+	if err := h.Repo.Create(&tag); err != nil {
+		if errors.Is(err, repository.ErrDuplicateTag) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Tag with this name already exists"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not create tag"})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(tag)
+}
+
+func (h *TagHandler) RenameTag(c *fiber.Ctx) error {
+	tag, ok := h.findTag(c)
+	if !ok {
+		return nil
+	}
+
+	request := new(models.RenameTagRequest)
+	if err := c.BodyParser(request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid body"})
+	}
+
+	request.Name = strings.TrimSpace(request.Name)
+	if err := h.validate.Struct(request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	tag.Name = request.Name
+	tag.UpdatedAt = time.Now()
+
+	if err := h.Repo.Update(tag); err != nil {
+		if errors.Is(err, repository.ErrDuplicateTag) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Tag with this name already exists"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not rename tag"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(tag)
+}
+
+func (h *TagHandler) DeleteTag(c *fiber.Ctx) error {
+	tag, ok := h.findTag(c)
+	if !ok {
+		return nil
+	}
+
+	if err := h.Repo.Delete(tag); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not delete tag"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Tag deleted successfully"})
+}
+
+// findTag loads the tag addressed by the :id route parameter from the
+// request's workspace. Otherwise it writes the error response and returns
+// false.
+func (h *TagHandler) findTag(c *fiber.Ctx) (*models.Tag, bool) {
+	tagID, err := c.ParamsInt("id")
+	if err != nil || tagID <= 0 {
+		c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid tag ID"})
+		return nil, false
+	}
+
+	tag, err := h.Repo.GetByID(tagID)
+	if err == nil && tag.WorkspaceID != auth.WorkspaceID(c) {
+		err = repository.ErrNotFound
+	}
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Tag not found"})
+		} else {
+			c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve tag"})
+		}
+		return nil, false
+	}
+	return tag, true
+}
@@ -1,61 +1,118 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
+	"slices"
 	"strings"
 	"time"
 
-	"task/backend/database"
+	"task/backend/auth"
+	"task/backend/events"
 	"task/backend/models"
+	"task/backend/repository"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
-	"gorm.io/gorm"
 )
 
-func CreateTask(c *fiber.Ctx) error {
-	validate := validator.New()
-
-	// Custom validation for future date
-	validate.RegisterValidation("future", func(fl validator.FieldLevel) bool {
-		if date, ok := fl.Field().Interface().(time.Time); ok {
-			return date.After(time.Now())
-		}
-		return false
-	})
+// TaskHandler serves the task endpoints on top of an injected TaskRepository.
+type TaskHandler struct {
+	Repo      repository.TaskRepository
+	Workflows repository.WorkflowRepository
+	Audit     repository.AuditRepository
+	// Events publishes every change to a task. Changes are written through
+	// its transactions, together with their events.
+	Events   *events.Bus
+	validate *validator.Validate
+}
 
-	// Custom validation for no spaces
-	validate.RegisterValidation("nospaces", func(fl validator.FieldLevel) bool {
-		return !strings.Contains(fl.Field().String(), " ")
-	})
+func NewTaskHandler(repo repository.TaskRepository, workflows repository.WorkflowRepository, audit repository.AuditRepository, bus *events.Bus) *TaskHandler {
+	return &TaskHandler{Repo: repo, Workflows: workflows, Audit: audit, Events: bus, validate: newValidator()}
+}
 
+func (h *TaskHandler) CreateTask(c *fiber.Ctx) error {
 	taskRequest := new(models.CreateTaskRequest)
 	if err := c.BodyParser(taskRequest); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid body"})
 	}
 
-	if err := validate.Struct(taskRequest); err != nil {
+	if err := h.validate.Struct(taskRequest); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 	}
 
 	task := models.Task{
 		Title:       taskRequest.Title,
 		Description: taskRequest.Description,
-		Status:      models.TaskStatusPending,
+		Priority:    models.TaskPriorityMedium,
+		OwnerID:     auth.UserID(c),
+		WorkspaceID: auth.WorkspaceID(c),
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
 
+	workflow, err := repository.WorkflowFor(h.Workflows, task.WorkspaceID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not create task"})
+	}
+	task.Status = workflow.Initial
 	if taskRequest.Status != "" {
+		if !workflow.HasStatus(taskRequest.Status) {
+			return statusNotAllowed(c, fmt.Sprintf("Status %q is not part of the workflow", taskRequest.Status), "", workflow.Statuses)
+		}
 		task.Status = taskRequest.Status
 	}
 
+	if taskRequest.Priority != "" {
+		task.Priority = taskRequest.Priority
+	}
+
 	if taskRequest.DueDate != nil {
 		task.DueDate = taskRequest.DueDate
 	}
 
-	if result := database.DB.Create(&task); result.Error != nil {
-		if strings.Contains(result.Error.Error(), "duplicate key value violates unique constraint") ||
-		   strings.Contains(result.Error.Error(), "UNIQUE constraint failed") {
+	if task.Recurrence, err = normalizeRecurrence(taskRequest.Recurrence); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if task.Recurrence != "" {
+		if task.DueDate == nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Recurring tasks need a due date"})
+		}
+		task.Occurrence = 1
+	}
+
+	task.Reminders = sortedReminders(taskRequest.Reminders)
+
+	if taskRequest.ParentID != nil {
+		if err := h.checkParent(c, 0, *taskRequest.ParentID); err != nil {
+			return parentError(c, err)
+		}
+		task.ParentID = taskRequest.ParentID
+	}
+
+	err = h.Events.Transaction(func(tx *repository.Store) error {
+		tags, err := resolveTags(tx, task.WorkspaceID, taskRequest.Tags)
+		if err != nil {
+			return err
+		}
+		task.Tags = tags
+
+		if err := tx.Tasks.Create(&task); err != nil {
+			return err
+		}
+
+		// A recurring task starts its own series
+		if task.Recurrence != "" {
+			task.SeriesID = &task.ID
+			if err := tx.Tasks.Update(&task); err != nil {
+				return err
+			}
+		}
+
+		return h.Events.Publish(tx, h.event(c, events.TaskCreated{Task: task}))
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrDuplicateTitle) {
 			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Task with this title already exists"})
 		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not create task"})
@@ -64,107 +121,95 @@ func CreateTask(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusCreated).JSON(task)
 }
 
-func GetTask(c *fiber.Ctx) error {
-	taskTitle := c.Params("title")
-	if taskTitle == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Task title cannot be empty"})
+func (h *TaskHandler) GetTask(c *fiber.Ctx) error {
+	taskID, err := c.ParamsInt("id")
+	if err != nil || taskID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid task ID"})
 	}
 
-	var task models.Task
-	if result := database.DB.Where("title = ?", taskTitle).First(&task); result.Error != nil {
-		if result.Error == gorm.ErrRecordNotFound {
+	task, err := h.findTask(c, taskID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Task not found"})
 		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve task"})
 	}
 
+	// Progress rolls up the whole subtree
+	tree, err := buildTree(h.Repo, *task)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve task"})
+	}
+	task.Progress = tree.Progress
+
 	return c.Status(fiber.StatusOK).JSON(task)
 }
 
-func GetAllTasks(c *fiber.Ctx) error {
-	var tasks []models.Task
-	var total int64
-
-	// Get query parameters with proper validation
-	status := c.Query("status")
-	dueDateStr := c.Query("due_date")
-	
-	// Handle pagination parameters with proper validation
-	page := c.QueryInt("page", 1)
-	size := c.QueryInt("size", 10)
-	
-	// Ensure positive values for pagination
-	if page <= 0 {
-		page = 1
-	}
-	if size <= 0 {
-		size = 10
+func (h *TaskHandler) GetAllTasks(c *fiber.Ctx) error {
+	filter, ok := taskFilter(c)
+	if !ok {
+		return nil
 	}
-	
-	search := c.Query("search")
 
-	// Build base query
-	query := database.DB.Model(&models.Task{})
+	page, size := pagination(c)
+	filter.Page = page
+	filter.Size = size
 
-	// Apply filters
-	if status != "" {
-		query = query.Where("status = ?", status)
+	sort, err := repository.ParseSort(c.Query("sort"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 	}
+	filter.Sort = sort
 
-	if dueDateStr != "" {
-		// Parse due_date string to time.Time
-		dueDate, err := time.Parse("2006-01-02", dueDateStr)
-		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid due_date format. Use YYYY-MM-DD"})
+	after, before := c.Query("after"), c.Query("before")
+	if after != "" && before != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Use either after or before, not both"})
+	}
+	if after != "" {
+		if filter.After, err = repository.DecodeCursor(after, sort); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid after cursor"})
 		}
-		// Filter tasks due on or before the specified date
-		query = query.Where("due_date <= ?", dueDate)
 	}
-
-	// Apply search by title
-	if search != "" {
-		query = query.Where("title ILIKE ?", "%"+search+"%")
+	if before != "" {
+		if filter.Before, err = repository.DecodeCursor(before, sort); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid before cursor"})
+		}
 	}
 
-	// Count total records
-	query.Count(&total)
-
-	// Apply pagination
-	offset := (page - 1) * size
-	query = query.Offset(offset).Limit(size)
+	filter.SkipCount = !c.QueryBool("count", true)
 
-	// Execute query
-	if result := query.Find(&tasks); result.Error != nil {
+	result, err := h.Repo.List(filter)
+	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve tasks"})
 	}
 
-	return c.Status(fiber.StatusOK).JSON(models.TasksResponse{
-		Tasks: tasks,
-		Total: total,
-		Page:  page,
+	response := models.TasksResponse{
+		Tasks: result.Tasks,
+		Total: result.Total,
 		Size:  size,
-	})
-}
+	}
 
-func UpdateTask(c *fiber.Ctx) error {
-	validate := validator.New()
+	// Cursor pages have no page number
+	if filter.After == nil && filter.Before == nil {
+		response.Page = page
+	}
 
-	// Custom validation for future date
-	validate.RegisterValidation("future", func(fl validator.FieldLevel) bool {
-		if date, ok := fl.Field().Interface().(time.Time); ok {
-			return date.After(time.Now())
+	if n := len(result.Tasks); n > 0 {
+		if result.HasNext {
+			response.NextCursor = repository.EncodeCursor(result.Tasks[n-1], sort)
 		}
-		return false
-	})
+		if result.HasPrev {
+			response.PrevCursor = repository.EncodeCursor(result.Tasks[0], sort)
+		}
+	}
 
-	// Custom validation for no spaces
-	validate.RegisterValidation("nospaces", func(fl validator.FieldLevel) bool {
-		return !strings.Contains(fl.Field().String(), " ")
-	})
+	return c.Status(fiber.StatusOK).JSON(response)
+}
 
-	taskTitle := c.Params("title")
-	if taskTitle == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Task title cannot be empty"})
+func (h *TaskHandler) UpdateTask(c *fiber.Ctx) error {
+	taskID, err := c.ParamsInt("id")
+	if err != nil || taskID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid task ID"})
 	}
 
 	updateRequest := new(models.UpdateTaskRequest)
@@ -173,25 +218,25 @@ func UpdateTask(c *fiber.Ctx) error {
 	}
 
 	// Validate the update request BEFORE checking if task exists
-	if err := validate.Struct(updateRequest); err != nil {
+	if err := h.validate.Struct(updateRequest); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	var existingTask models.Task
-	if result := database.DB.Where("title = ?", taskTitle).First(&existingTask); result.Error != nil {
-		if result.Error == gorm.ErrRecordNotFound {
+	existingTask, err := h.findTask(c, taskID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Task not found"})
 		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve task"})
 	}
+	before := *existingTask
 
-	// Rest of the update logic remains the same...
 	if updateRequest.Title != nil {
 		if *updateRequest.Title != existingTask.Title {
-			var count int64
-			database.DB.Model(&models.Task{}).Where("title = ?", *updateRequest.Title).Count(&count)
-			if count > 0 {
+			if _, err := h.Repo.GetByTitle(existingTask.WorkspaceID, *updateRequest.Title); err == nil {
 				return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Task with this new title already exists"})
+			} else if !errors.Is(err, repository.ErrNotFound) {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not update task"})
 			}
 		}
 		existingTask.Title = *updateRequest.Title
@@ -201,40 +246,273 @@ func UpdateTask(c *fiber.Ctx) error {
 		existingTask.Description = *updateRequest.Description
 	}
 
+	wasCompleted := existingTask.Status == models.TaskStatusCompleted
 	if updateRequest.Status != nil {
+		workflow, err := repository.WorkflowFor(h.Workflows, existingTask.WorkspaceID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not update task"})
+		}
+		if !workflow.Allows(existingTask.Status, *updateRequest.Status) {
+			message := fmt.Sprintf("Cannot move task from %q to %q", existingTask.Status, *updateRequest.Status)
+			return statusNotAllowed(c, message, existingTask.Status, workflow.Next(existingTask.Status))
+		}
+
+		if workflow.Starts(*updateRequest.Status) && *updateRequest.Status != existingTask.Status && existingTask.Blocked && !updateRequest.IgnoreDependencies {
+			blockedBy, err := h.blockers(existingTask.ID)
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not update task"})
+			}
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error":      "Task is blocked by unfinished dependencies. Set ignore_dependencies to override",
+				"blocked_by": blockedBy,
+			})
+		}
 		existingTask.Status = *updateRequest.Status
 	}
 
+	if updateRequest.Priority != nil {
+		existingTask.Priority = *updateRequest.Priority
+	}
+
 	if updateRequest.DueDate != nil {
 		existingTask.DueDate = updateRequest.DueDate
 	}
 
+	if updateRequest.Recurrence != nil {
+		rule, err := normalizeRecurrence(*updateRequest.Recurrence)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		if rule != "" && existingTask.DueDate == nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Recurring tasks need a due date"})
+		}
+		existingTask.Recurrence = rule
+		if rule != "" && existingTask.SeriesID == nil {
+			existingTask.SeriesID = &existingTask.ID
+			existingTask.Occurrence = 1
+		}
+	}
+
+	if updateRequest.Reminders != nil {
+		existingTask.Reminders = sortedReminders(*updateRequest.Reminders)
+	}
+
+	if updateRequest.ParentID != nil {
+		if *updateRequest.ParentID == 0 {
+			existingTask.ParentID = nil
+		} else {
+			if err := h.checkParent(c, existingTask.ID, *updateRequest.ParentID); err != nil {
+				return parentError(c, err)
+			}
+			existingTask.ParentID = updateRequest.ParentID
+		}
+	}
+
 	existingTask.UpdatedAt = time.Now()
 
-	if result := database.DB.Save(&existingTask); result.Error != nil {
+	// Completing a recurring task hands its rule on to the next occurrence
+	var next *models.Task
+	if existingTask.Recurrence != "" && existingTask.Status == models.TaskStatusCompleted && !wasCompleted {
+		if next, err = h.nextOccurrence(existingTask); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not update task"})
+		}
+		existingTask.Recurrence = ""
+	}
+
+	err = h.Events.Transaction(func(tx *repository.Store) error {
+		if updateRequest.Tags != nil {
+			tags, err := resolveTags(tx, existingTask.WorkspaceID, *updateRequest.Tags)
+			if err != nil {
+				return err
+			}
+			existingTask.Tags = tags
+		}
+
+		if err := tx.Tasks.Update(existingTask); err != nil {
+			return err
+		}
+
+		var published []events.Event
+		if changes := events.Diff(&before, existingTask); len(changes) > 0 {
+			published = append(published, h.event(c, events.TaskUpdated{Task: *existingTask, Before: before, Changes: changes}))
+		}
+		if existingTask.Status != before.Status {
+			published = append(published, h.event(c, events.StatusChanged{Task: *existingTask, From: before.Status, To: existingTask.Status}))
+		}
+
+		if next != nil {
+			if err := createOccurrence(tx, next, existingTask.Title); err != nil {
+				return err
+			}
+			published = append(published, h.event(c, events.TaskCreated{Task: *next}))
+		}
+
+		return h.Events.Publish(tx, published...)
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrDuplicateTitle) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Task with this new title already exists"})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not update task"})
 	}
 
 	return c.Status(fiber.StatusOK).JSON(existingTask)
 }
 
-func DeleteTask(c *fiber.Ctx) error {
-	taskTitle := c.Params("title")
-	if taskTitle == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Task title cannot be empty"})
+func (h *TaskHandler) DeleteTask(c *fiber.Ctx) error {
+	taskID, err := c.ParamsInt("id")
+	if err != nil || taskID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid task ID"})
 	}
 
-	var task models.Task
-	if result := database.DB.Where("title = ?", taskTitle).First(&task); result.Error != nil {
-		if result.Error == gorm.ErrRecordNotFound {
+	task, err := h.findTask(c, taskID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Task not found"})
 		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve task"})
 	}
 
-	if result := database.DB.Delete(&task); result.Error != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not delete task"})
+	return h.deleteWithChildren(c, task, c.Query("children", childrenRestrict))
+}
+
+// findTask loads a task from the request's workspace. Tasks of other
+// workspaces are reported as missing so IDs don't leak.
+func (h *TaskHandler) findTask(c *fiber.Ctx, id int) (*models.Task, error) {
+	task, err := h.Repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if task.WorkspaceID != auth.WorkspaceID(c) {
+		return nil, repository.ErrNotFound
 	}
+	return task, nil
+}
 
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Task deleted successfully"})
-}
\ No newline at end of file
+// event returns an event of the request's workspace, caused by the caller.
+func (h *TaskHandler) event(c *fiber.Ctx, payload events.Payload) events.Event {
+	return events.New(auth.WorkspaceID(c), auth.UserID(c), payload)
+}
+
+// resolveTags returns the workspace's tags with the given names ordered by
+// name, creating the ones that don't exist yet in tx, so that they are
+// rolled back with a change that fails.
+func resolveTags(tx *repository.Store, workspaceID int, names []string) ([]models.Tag, error) {
+	names = uniqueNames(names)
+
+	tags, err := tx.Tags.GetByNames(workspaceID, names)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range names {
+		if slices.ContainsFunc(tags, func(tag models.Tag) bool { return tag.Name == name }) {
+			continue
+		}
+
+		tag := models.Tag{
+			WorkspaceID: workspaceID,
+			Name:        name,
+			CreatedAt:   time.Now(),
+			UpdatedAt:   time.Now(),
+		}
+		// A savepoint keeps PostgreSQL's transaction usable if the insert
+		// fails
+		err := tx.Transaction(func(attempt *repository.Store) error {
+			return attempt.Tags.Create(&tag)
+		})
+		if err != nil {
+			if !errors.Is(err, repository.ErrDuplicateTag) {
+				return nil, err
+			}
+			// Created concurrently by another request
+			existing, err := tx.Tags.GetByNames(workspaceID, []string{name})
+			if err != nil {
+				return nil, err
+			}
+			if len(existing) == 0 {
+				return nil, fmt.Errorf("tag %q was reported as duplicate but not found", name)
+			}
+			tag = existing[0]
+		}
+		tags = append(tags, tag)
+	}
+
+	slices.SortFunc(tags, func(a, b models.Tag) int { return strings.Compare(a.Name, b.Name) })
+	return tags, nil
+}
+
+// sortedReminders returns a task's reminder offsets in ascending order. No
+// offsets store as nil, so the task falls back to the server's defaults.
+func sortedReminders(minutes []int) []int {
+	if len(minutes) == 0 {
+		return nil
+	}
+	minutes = slices.Clone(minutes)
+	slices.Sort(minutes)
+	return minutes
+}
+
+// uniqueNames trims names and drops empty and repeated ones.
+func uniqueNames(names []string) []string {
+	unique := make([]string, 0, len(names))
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" && !slices.Contains(unique, name) {
+			unique = append(unique, name)
+		}
+	}
+	return unique
+}
+
+// taskFilter reads the filters of a task listing from the query: title,
+// status, search, series_id, priority, tag, tag_mode and due_date. It writes
+// the error response itself when one of them is invalid.
+func taskFilter(c *fiber.Ctx) (repository.TaskFilter, bool) {
+	filter := repository.TaskFilter{
+		WorkspaceID: auth.WorkspaceID(c),
+		Title:       c.Query("title"),
+		Status:      c.Query("status"),
+		Search:      c.Query("search"),
+		SeriesID:    c.QueryInt("series_id"),
+	}
+
+	if priority := models.TaskPriority(c.Query("priority")); priority != "" {
+		if priority.Rank() == 0 {
+			c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid priority. Use low, medium, high or urgent"})
+			return filter, false
+		}
+		filter.Priority = priority
+	}
+
+	if tags := splitList(c.Query("tag")); len(tags) > 0 {
+		filter.Tags = tags
+		switch c.Query("tag_mode", "any") {
+		case "any":
+		case "all":
+			filter.AllTags = true
+		default:
+			c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid tag_mode. Use any or all"})
+			return filter, false
+		}
+	}
+
+	if dueDateStr := c.Query("due_date"); dueDateStr != "" {
+		// Parse due_date string to time.Time
+		dueDate, err := time.Parse("2006-01-02", dueDateStr)
+		if err != nil {
+			c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid due_date format. Use YYYY-MM-DD"})
+			return filter, false
+		}
+		// Filter tasks due on or before the specified date
+		filter.DueBefore = &dueDate
+	}
+	return filter, true
+}
+
+// splitList splits a comma-separated query parameter into unique names.
+func splitList(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return uniqueNames(strings.Split(value, ","))
+}
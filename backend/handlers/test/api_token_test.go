@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"task/backend/auth"
+	"task/backend/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// ============================================================================
+// API TOKEN TESTS
+// ============================================================================
+
+// createAPIToken creates a token for the suite user through the API and
+// returns the response including the plaintext token.
+func (suite *HandlerTestSuite) createAPIToken(name string, scope models.TokenScope) models.CreateAPITokenResponse {
+	resp, body := suite.makeRequest("POST", "/tokens", models.CreateAPITokenRequest{Name: name, Scope: scope})
+	suite.Require().Equal(http.StatusCreated, resp.StatusCode, string(body))
+
+	var created models.CreateAPITokenResponse
+	suite.Require().NoError(json.Unmarshal(body, &created))
+	return created
+}
+
+func (suite *HandlerTestSuite) TestAPIToken_CreateAndList() {
+	created := suite.createAPIToken("ci", models.TokenScopeReadWrite)
+
+	assert.True(suite.T(), auth.IsAPIToken(created.Token))
+	assert.Equal(suite.T(), "ci", created.Name)
+	assert.Equal(suite.T(), models.TokenScopeReadWrite, created.Scope)
+	assert.Contains(suite.T(), created.Token, created.Prefix)
+
+	resp, body := suite.makeRequest("GET", "/tokens", nil)
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+
+	var tokens []models.APIToken
+	suite.Require().NoError(json.Unmarshal(body, &tokens))
+	suite.Require().Len(tokens, 1)
+	assert.Equal(suite.T(), created.ID, tokens[0].ID)
+	assert.Nil(suite.T(), tokens[0].LastUsedAt)
+
+	// Neither the secret nor its hash is ever listed
+	assert.NotContains(suite.T(), string(body), created.Token)
+	assert.NotContains(suite.T(), string(body), auth.HashAPIToken(created.Token))
+}
+
+func (suite *HandlerTestSuite) TestAPIToken_ValidationErrors() {
+	past := time.Now().Add(-time.Hour)
+	testCases := []struct {
+		name    string
+		request models.CreateAPITokenRequest
+	}{
+		{"Missing name", models.CreateAPITokenRequest{Scope: models.TokenScopeRead}},
+		{"Unknown scope", models.CreateAPITokenRequest{Name: "bad", Scope: "admin"}},
+		{"Past expiry", models.CreateAPITokenRequest{Name: "old", Scope: models.TokenScopeRead, ExpiresAt: &past}},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			resp, _ := suite.makeRequest("POST", "/tokens", tc.request)
+			assert.Equal(suite.T(), http.StatusBadRequest, resp.StatusCode)
+		})
+	}
+}
+
+func (suite *HandlerTestSuite) TestAPIToken_ReadWriteTokenCanMutateTasks() {
+	created := suite.createAPIToken("cron", models.TokenScopeReadWrite)
+
+	futureDate := time.Now().Add(24 * time.Hour)
+	resp, body := suite.makeRequestWithToken("POST", "/tasks", models.CreateTaskRequest{
+		Title:   "from-cron",
+		DueDate: &futureDate,
+	}, created.Token)
+	assert.Equal(suite.T(), http.StatusCreated, resp.StatusCode)
+
+	var task models.Task
+	suite.Require().NoError(json.Unmarshal(body, &task))
+	assert.Equal(suite.T(), suite.user.ID, task.OwnerID)
+
+	resp, _ = suite.makeRequestWithToken("DELETE", fmt.Sprintf("/tasks/%d", task.ID), nil, created.Token)
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+
+	tokens, err := suite.store.APITokens.ListByUser(suite.user.ID)
+	suite.Require().NoError(err)
+	suite.Require().Len(tokens, 1)
+	assert.NotNil(suite.T(), tokens[0].LastUsedAt)
+}
+
+func (suite *HandlerTestSuite) TestAPIToken_ReadOnlyTokenCannotMutateTasks() {
+	created := suite.createAPIToken("dashboard", models.TokenScopeRead)
+	futureDate := time.Now().Add(24 * time.Hour)
+	task := suite.createTestTask("read-only-target", "Description", models.TaskStatusPending, &futureDate)
+
+	resp, _ := suite.makeRequestWithToken("GET", "/tasks", nil, created.Token)
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+
+	resp, body := suite.makeRequestWithToken("POST", "/tasks", models.CreateTaskRequest{
+		Title:   "not-allowed",
+		DueDate: &futureDate,
+	}, created.Token)
+	assert.Equal(suite.T(), http.StatusForbidden, resp.StatusCode)
+
+	var errorResp map[string]interface{}
+	suite.Require().NoError(json.Unmarshal(body, &errorResp))
+	assert.Equal(suite.T(), "Token does not permit write access", errorResp["error"])
+
+	resp, _ = suite.makeRequestWithToken("DELETE", fmt.Sprintf("/tasks/%d", task.ID), nil, created.Token)
+	assert.Equal(suite.T(), http.StatusForbidden, resp.StatusCode)
+}
+
+func (suite *HandlerTestSuite) TestAPIToken_ExpiredTokenIsRejected() {
+	token, hash, err := auth.GenerateAPIToken()
+	suite.Require().NoError(err)
+	expired := time.Now().Add(-time.Minute)
+	suite.Require().NoError(suite.store.APITokens.Create(&models.APIToken{
+		UserID:    suite.user.ID,
+		Name:      "expired",
+		Prefix:    token[:11],
+		TokenHash: hash,
+		Scope:     models.TokenScopeReadWrite,
+		ExpiresAt: &expired,
+		CreatedAt: time.Now().Add(-time.Hour),
+	}))
+
+	resp, _ := suite.makeRequestWithToken("GET", "/tasks", nil, token)
+	assert.Equal(suite.T(), http.StatusUnauthorized, resp.StatusCode)
+}
+
+func (suite *HandlerTestSuite) TestAPIToken_Revoke() {
+	created := suite.createAPIToken("temporary", models.TokenScopeReadWrite)
+	tokenURL := fmt.Sprintf("/tokens/%d", created.ID)
+
+	// Other users cannot revoke someone else's token
+	_, otherToken := suite.createTestUser("other@example.com")
+	resp, _ := suite.makeRequestWithToken("DELETE", tokenURL, nil, otherToken)
+	assert.Equal(suite.T(), http.StatusNotFound, resp.StatusCode)
+
+	resp, _ = suite.makeRequest("DELETE", tokenURL, nil)
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+
+	resp, _ = suite.makeRequestWithToken("GET", "/tasks", nil, created.Token)
+	assert.Equal(suite.T(), http.StatusUnauthorized, resp.StatusCode)
+
+	resp, _ = suite.makeRequest("DELETE", tokenURL, nil)
+	assert.Equal(suite.T(), http.StatusNotFound, resp.StatusCode)
+}
+
+func (suite *HandlerTestSuite) TestAPIToken_CannotManageTokens() {
+	created := suite.createAPIToken("ci", models.TokenScopeReadWrite)
+
+	resp, _ := suite.makeRequestWithToken("GET", "/tokens", nil, created.Token)
+	assert.Equal(suite.T(), http.StatusForbidden, resp.StatusCode)
+
+	resp, _ = suite.makeRequestWithToken("POST", "/tokens", models.CreateAPITokenRequest{
+		Name:  "escalation",
+		Scope: models.TokenScopeReadWrite,
+	}, created.Token)
+	assert.Equal(suite.T(), http.StatusForbidden, resp.StatusCode)
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"task/backend/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// ============================================================================
+// HISTORY TESTS
+// ============================================================================
+
+func (suite *HandlerTestSuite) history(taskID int, query string) models.AuditResponse {
+	resp, body := suite.makeRequest("GET", fmt.Sprintf("/tasks/%d/history%s", taskID, query), nil)
+	suite.Require().Equal(http.StatusOK, resp.StatusCode, string(body))
+
+	var history models.AuditResponse
+	suite.Require().NoError(json.Unmarshal(body, &history))
+	return history
+}
+
+// changedFields maps each changed field of an entry to its before and after
+// values as decoded from JSON.
+func changedFields(entry models.AuditEntry) map[string][2]any {
+	fields := make(map[string][2]any, len(entry.Changes))
+	for _, change := range entry.Changes {
+		fields[change.Field] = [2]any{change.Before, change.After}
+	}
+	return fields
+}
+
+func (suite *HandlerTestSuite) TestHistory_RecordsChanges() {
+	task := suite.createTaggedTask("write-docs", "docs")
+
+	status := models.TaskStatusInProgress
+	tags := []string{"docs", "urgent"}
+	resp, body := suite.makeRequest("PUT", fmt.Sprintf("/tasks/%d", task.ID), models.UpdateTaskRequest{Status: &status, Tags: &tags})
+	suite.Require().Equal(http.StatusOK, resp.StatusCode, string(body))
+
+	// Updates that change nothing leave no trace
+	resp, _ = suite.makeRequest("PUT", fmt.Sprintf("/tasks/%d", task.ID), models.UpdateTaskRequest{Status: &status})
+	suite.Require().Equal(http.StatusOK, resp.StatusCode)
+
+	history := suite.history(task.ID, "")
+	assert.Equal(suite.T(), int64(2), history.Total)
+	suite.Require().Len(history.Entries, 2)
+
+	updated := history.Entries[0]
+	assert.Equal(suite.T(), models.AuditUpdated, updated.Action)
+	assert.Equal(suite.T(), suite.user.ID, updated.ActorID)
+	assert.Equal(suite.T(), map[string][2]any{
+		"status": {"pending", "in_progress"},
+		"tags":   {[]any{"docs"}, []any{"docs", "urgent"}},
+	}, changedFields(updated))
+
+	created := history.Entries[1]
+	assert.Equal(suite.T(), models.AuditCreated, created.Action)
+	fields := changedFields(created)
+	assert.Equal(suite.T(), [2]any{nil, "write-docs"}, fields["title"])
+	assert.Equal(suite.T(), [2]any{nil, "pending"}, fields["status"])
+	assert.Contains(suite.T(), fields, "due_date")
+	assert.NotContains(suite.T(), fields, "parent_id", "unset fields are left out")
+}
+
+func (suite *HandlerTestSuite) TestHistory_Pagination() {
+	task := suite.createTaggedTask("task")
+	for _, priority := range []models.TaskPriority{models.TaskPriorityHigh, models.TaskPriorityLow} {
+		resp, _ := suite.makeRequest("PUT", fmt.Sprintf("/tasks/%d", task.ID), models.UpdateTaskRequest{Priority: &priority})
+		suite.Require().Equal(http.StatusOK, resp.StatusCode)
+	}
+
+	history := suite.history(task.ID, "?page=2&size=1")
+	assert.Equal(suite.T(), int64(3), history.Total)
+	assert.Equal(suite.T(), 2, history.Page)
+	suite.Require().Len(history.Entries, 1)
+	assert.Equal(suite.T(), [2]any{"medium", "high"}, changedFields(history.Entries[0])["priority"])
+}
+
+func (suite *HandlerTestSuite) TestHistory_PaginationLimits() {
+	task := suite.createTaggedTask("task")
+
+	history := suite.history(task.ID, "?size=9223372036854775807")
+	assert.Equal(suite.T(), 100, history.Size)
+	assert.Len(suite.T(), history.Entries, 1)
+
+	history = suite.history(task.ID, "?page=1000000000000000000")
+	assert.Equal(suite.T(), 100000, history.Page)
+	assert.Empty(suite.T(), history.Entries)
+}
+
+func (suite *HandlerTestSuite) TestHistory_DeleteAndRestore() {
+	parent := suite.createTaggedTask("launch")
+	child := suite.createSubtask("press-release", parent.ID)
+
+	suite.deleteTask(parent, "?children=cascade")
+
+	// The history of trashed tasks stays readable
+	assert.Equal(suite.T(), models.AuditDeleted, suite.history(child.ID, "").Entries[0].Action)
+
+	title := "relaunch"
+	resp, body := suite.restoreTask(parent, models.RestoreTaskRequest{Title: &title})
+	suite.Require().Equal(http.StatusOK, resp.StatusCode, string(body))
+
+	restored := suite.history(parent.ID, "").Entries[0]
+	assert.Equal(suite.T(), models.AuditRestored, restored.Action)
+	assert.Equal(suite.T(), map[string][2]any{"title": {"launch", "relaunch"}}, changedFields(restored))
+	assert.Equal(suite.T(), models.AuditRestored, suite.history(child.ID, "").Entries[0].Action)
+
+	// Orphaned subtasks record losing their parent
+	suite.deleteTask(parent, "?children=orphan")
+	orphaned := suite.history(child.ID, "").Entries[0]
+	assert.Equal(suite.T(), models.AuditUpdated, orphaned.Action)
+	assert.Equal(suite.T(), map[string][2]any{"parent_id": {float64(parent.ID), nil}}, changedFields(orphaned))
+}
+
+func (suite *HandlerTestSuite) TestHistory_OtherWorkspace() {
+	team := suite.createWorkspace("Team")
+	resp, body := suite.makeRequestWithHeaders("POST", "/tasks", newTaskRequest("shared"), suite.token, inWorkspace(team.ID))
+	suite.Require().Equal(http.StatusCreated, resp.StatusCode, string(body))
+	var task models.Task
+	suite.Require().NoError(json.Unmarshal(body, &task))
+
+	resp, _ = suite.makeRequest("GET", fmt.Sprintf("/tasks/%d/history", task.ID), nil)
+	assert.Equal(suite.T(), http.StatusNotFound, resp.StatusCode)
+}
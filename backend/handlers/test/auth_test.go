@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"task/backend/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// ============================================================================
+// AUTHENTICATION TESTS
+// ============================================================================
+
+func (suite *HandlerTestSuite) TestSignup_Success() {
+	resp, body := suite.makeRequestWithToken("POST", "/auth/signup", models.SignupRequest{
+		Email:    "New.User@Example.com",
+		Password: testPassword,
+	}, "")
+
+	assert.Equal(suite.T(), http.StatusCreated, resp.StatusCode)
+
+	var authResp models.AuthResponse
+	err := json.Unmarshal(body, &authResp)
+	suite.Require().NoError(err)
+
+	suite.Require().NotNil(authResp.User)
+	assert.Equal(suite.T(), "new.user@example.com", authResp.User.Email)
+	assert.NotEmpty(suite.T(), authResp.AccessToken)
+	assert.NotEmpty(suite.T(), authResp.RefreshToken)
+	assert.Equal(suite.T(), "Bearer", authResp.TokenType)
+	assert.NotContains(suite.T(), string(body), "password")
+
+	// The new access token works right away
+	resp, _ = suite.makeRequestWithToken("GET", "/tasks", nil, authResp.AccessToken)
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+}
+
+func (suite *HandlerTestSuite) TestSignup_DuplicateEmail() {
+	resp, body := suite.makeRequestWithToken("POST", "/auth/signup", models.SignupRequest{
+		Email:    suite.user.Email,
+		Password: testPassword,
+	}, "")
+
+	assert.Equal(suite.T(), http.StatusConflict, resp.StatusCode)
+
+	var errorResp map[string]interface{}
+	err := json.Unmarshal(body, &errorResp)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), "User with this email already exists", errorResp["error"])
+}
+
+func (suite *HandlerTestSuite) TestSignup_ValidationErrors() {
+	testCases := []struct {
+		name        string
+		request     models.SignupRequest
+		expectedErr string
+	}{
+		{"Invalid email", models.SignupRequest{Email: "not-an-email", Password: testPassword}, "email"},
+		{"Short password", models.SignupRequest{Email: "short@example.com", Password: "short"}, "min"},
+		{"Missing password", models.SignupRequest{Email: "missing@example.com"}, "required"},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			resp, body := suite.makeRequestWithToken("POST", "/auth/signup", tc.request, "")
+
+			assert.Equal(suite.T(), http.StatusBadRequest, resp.StatusCode)
+
+			var errorResp map[string]interface{}
+			err := json.Unmarshal(body, &errorResp)
+			suite.Require().NoError(err)
+			assert.Contains(suite.T(), errorResp["error"], tc.expectedErr)
+		})
+	}
+}
+
+func (suite *HandlerTestSuite) TestLogin_Success() {
+	resp, body := suite.makeRequestWithToken("POST", "/auth/login", models.LoginRequest{
+		Email:    suite.user.Email,
+		Password: testPassword,
+	}, "")
+
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+
+	var authResp models.AuthResponse
+	err := json.Unmarshal(body, &authResp)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), suite.user.ID, authResp.User.ID)
+	assert.NotEmpty(suite.T(), authResp.AccessToken)
+}
+
+func (suite *HandlerTestSuite) TestLogin_InvalidCredentials() {
+	testCases := []struct {
+		name    string
+		request models.LoginRequest
+	}{
+		{"Wrong password", models.LoginRequest{Email: suite.user.Email, Password: "wrong-password"}},
+		{"Unknown email", models.LoginRequest{Email: "nobody@example.com", Password: testPassword}},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			resp, body := suite.makeRequestWithToken("POST", "/auth/login", tc.request, "")
+
+			assert.Equal(suite.T(), http.StatusUnauthorized, resp.StatusCode)
+
+			var errorResp map[string]interface{}
+			err := json.Unmarshal(body, &errorResp)
+			suite.Require().NoError(err)
+			assert.Equal(suite.T(), "Invalid email or password", errorResp["error"])
+		})
+	}
+}
+
+func (suite *HandlerTestSuite) TestRefresh() {
+	access, refresh, err := suite.tokens.Issue(suite.user.ID)
+	suite.Require().NoError(err)
+
+	resp, body := suite.makeRequestWithToken("POST", "/auth/refresh", models.RefreshRequest{RefreshToken: refresh}, "")
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+
+	var authResp models.AuthResponse
+	err = json.Unmarshal(body, &authResp)
+	suite.Require().NoError(err)
+	assert.NotEmpty(suite.T(), authResp.AccessToken)
+
+	// Access tokens cannot be used to refresh
+	resp, _ = suite.makeRequestWithToken("POST", "/auth/refresh", models.RefreshRequest{RefreshToken: access}, "")
+	assert.Equal(suite.T(), http.StatusUnauthorized, resp.StatusCode)
+}
+
+func (suite *HandlerTestSuite) TestTasks_RequireAuthentication() {
+	_, refresh, err := suite.tokens.Issue(suite.user.ID)
+	suite.Require().NoError(err)
+
+	testCases := []struct {
+		name  string
+		token string
+	}{
+		{"No token", ""},
+		{"Garbage token", "not-a-jwt"},
+		{"Refresh token", refresh},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			resp, _ := suite.makeRequestWithToken("GET", "/tasks", nil, tc.token)
+			assert.Equal(suite.T(), http.StatusUnauthorized, resp.StatusCode)
+		})
+	}
+}
+
+func (suite *HandlerTestSuite) TestTasks_AreScopedToOwner() {
+	futureDate := time.Now().Add(24 * time.Hour)
+	task := suite.createTestTask("owners-task", "Description", models.TaskStatusPending, &futureDate)
+
+	_, otherToken := suite.createTestUser("other@example.com")
+	taskURL := fmt.Sprintf("/tasks/%d", task.ID)
+
+	resp, body := suite.makeRequestWithToken("GET", "/tasks", nil, otherToken)
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+	var tasksResp models.TasksResponse
+	suite.Require().NoError(json.Unmarshal(body, &tasksResp))
+	assert.Equal(suite.T(), int64(0), *tasksResp.Total)
+
+	resp, _ = suite.makeRequestWithToken("GET", taskURL, nil, otherToken)
+	assert.Equal(suite.T(), http.StatusNotFound, resp.StatusCode)
+
+	newTitle := "hijacked"
+	resp, _ = suite.makeRequestWithToken("PUT", taskURL, models.UpdateTaskRequest{Title: &newTitle}, otherToken)
+	assert.Equal(suite.T(), http.StatusNotFound, resp.StatusCode)
+
+	resp, _ = suite.makeRequestWithToken("DELETE", taskURL, nil, otherToken)
+	assert.Equal(suite.T(), http.StatusNotFound, resp.StatusCode)
+
+	// The owner still sees the untouched task
+	resp, body = suite.makeRequest("GET", taskURL, nil)
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+	var ownTask models.Task
+	suite.Require().NoError(json.Unmarshal(body, &ownTask))
+	assert.Equal(suite.T(), "owners-task", ownTask.Title)
+	assert.Equal(suite.T(), suite.user.ID, ownTask.OwnerID)
+}
@@ -0,0 +1,248 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"task/backend/auth"
+	"task/backend/models"
+
+	"github.com/fasthttp/websocket"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// ============================================================================
+// BOARD TESTS
+// ============================================================================
+
+// listen serves the app on a local port for the length of the test and
+// returns its WebSocket URL.
+func (suite *HandlerTestSuite) listen() string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	suite.Require().NoError(err)
+	go suite.app.Listener(ln)
+	suite.T().Cleanup(func() { suite.app.ShutdownWithTimeout(time.Second) })
+	return "ws://" + ln.Addr().String()
+}
+
+// openBoard connects to the board. The connection is closed at the end of
+// the test.
+func (suite *HandlerTestSuite) openBoard(url string, header http.Header) *websocket.Conn {
+	conn, resp, err := websocket.DefaultDialer.Dial(url, header)
+	suite.Require().NoError(err)
+	resp.Body.Close()
+	suite.T().Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func bearer(token string, workspaceID int) http.Header {
+	return http.Header{
+		fiber.HeaderAuthorization: {"Bearer " + token},
+		auth.WorkspaceHeader:      {fmt.Sprint(workspaceID)},
+	}
+}
+
+// nextMessage reads messages until one of the given type arrives.
+func (suite *HandlerTestSuite) nextMessage(conn *websocket.Conn, typ string) models.BoardMessage {
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		var msg models.BoardMessage
+		suite.Require().NoError(conn.ReadJSON(&msg), "waiting for a %s message", typ)
+		if msg.Type == typ {
+			return msg
+		}
+	}
+}
+
+func viewerEmails(msg models.BoardMessage) []string {
+	emails := make([]string, len(msg.Viewers))
+	for i, viewer := range msg.Viewers {
+		emails[i] = viewer.Email
+	}
+	return emails
+}
+
+func (suite *HandlerTestSuite) updateOnBoard(conn *websocket.Conn, ref string, task models.Task, changes string) models.BoardMessage {
+	cmd := models.BoardCommand{Type: models.BoardUpdateTask, Ref: ref, TaskID: task.ID, Changes: json.RawMessage(changes)}
+	suite.Require().NoError(conn.WriteJSON(cmd))
+	msg := suite.nextMessage(conn, models.BoardResult)
+	suite.Require().Equal(ref, msg.Ref)
+	return msg
+}
+
+func (suite *HandlerTestSuite) TestBoard_PresenceAndEvents() {
+	url := suite.listen()
+	team := suite.createWorkspace("Team")
+	_, editorToken := suite.joinWorkspace(team, "editor@example.com", models.RoleEditor)
+
+	// Browsers can't set headers, so the owner passes the credentials in the query
+	owner := suite.openBoard(fmt.Sprintf("%s/board?access_token=%s&workspace_id=%d", url, suite.token, team.ID), nil)
+	assert.Equal(suite.T(), []string{"owner@example.com"}, viewerEmails(suite.nextMessage(owner, models.BoardPresence)))
+
+	editor := suite.openBoard(url+"/board", bearer(editorToken, team.ID))
+	assert.Equal(suite.T(), []string{"owner@example.com", "editor@example.com"}, viewerEmails(suite.nextMessage(owner, models.BoardPresence)))
+	assert.Len(suite.T(), suite.nextMessage(editor, models.BoardPresence).Viewers, 2)
+
+	// Only the team's tasks show up on its board
+	suite.createTaggedTask("personal")
+	resp, _ := suite.makeRequestWithHeaders("POST", "/tasks", newTaskRequest("write-docs"), editorToken, inWorkspace(team.ID))
+	suite.Require().Equal(http.StatusCreated, resp.StatusCode)
+	suite.relay()
+
+	for _, conn := range []*websocket.Conn{owner, editor} {
+		msg := suite.nextMessage(conn, "task.created")
+		assert.NotZero(suite.T(), msg.ID)
+		var data models.TaskStreamEvent
+		suite.Require().NoError(json.Unmarshal(msg.Data, &data))
+		assert.Equal(suite.T(), "write-docs", data.Task.Title)
+	}
+
+	editor.Close()
+	assert.Equal(suite.T(), []string{"owner@example.com"}, viewerEmails(suite.nextMessage(owner, models.BoardPresence)))
+}
+
+func (suite *HandlerTestSuite) TestBoard_UpdateTask() {
+	url := suite.listen()
+	board := suite.openBoard(url+"/board", bearer(suite.token, suite.workspace.ID))
+	task := suite.createTaggedTask("write-docs")
+
+	msg := suite.updateOnBoard(board, "1", task, `{"status": "completed"}`)
+	assert.Equal(suite.T(), http.StatusOK, msg.Status)
+	var updated models.Task
+	suite.Require().NoError(json.Unmarshal(msg.Body, &updated))
+	assert.Equal(suite.T(), models.TaskStatusCompleted, updated.Status)
+
+	suite.relay()
+	var data models.TaskStreamEvent
+	suite.Require().NoError(json.Unmarshal(suite.nextMessage(board, "task.updated").Data, &data))
+	assert.Equal(suite.T(), task.ID, data.Task.ID)
+
+	// Changes are validated like PUT /tasks/:id
+	msg = suite.updateOnBoard(board, "2", task, `{"priority": "someday"}`)
+	assert.Equal(suite.T(), http.StatusBadRequest, msg.Status)
+	assert.Contains(suite.T(), string(msg.Body), "error")
+
+	msg = suite.updateOnBoard(board, "3", models.Task{ID: task.ID + 100}, `{"title": "missing"}`)
+	assert.Equal(suite.T(), http.StatusNotFound, msg.Status)
+
+	suite.Require().NoError(board.WriteMessage(websocket.TextMessage, []byte("not json")))
+	assert.Equal(suite.T(), "Invalid message", suite.nextMessage(board, models.BoardError).Error)
+	suite.Require().NoError(board.WriteJSON(models.BoardCommand{Type: "delete_task", Ref: "4"}))
+	assert.Equal(suite.T(), "4", suite.nextMessage(board, models.BoardError).Ref)
+}
+
+func (suite *HandlerTestSuite) TestBoard_ViewersCantUpdate() {
+	url := suite.listen()
+	team := suite.createWorkspace("Team")
+	_, viewerToken := suite.joinWorkspace(team, "viewer@example.com", models.RoleViewer)
+	resp, body := suite.makeRequestWithHeaders("POST", "/tasks", newTaskRequest("write-docs"), suite.token, inWorkspace(team.ID))
+	suite.Require().Equal(http.StatusCreated, resp.StatusCode)
+	var task models.Task
+	suite.Require().NoError(json.Unmarshal(body, &task))
+
+	board := suite.openBoard(url+"/board", bearer(viewerToken, team.ID))
+	msg := suite.updateOnBoard(board, "1", task, `{"status": "completed"}`)
+	assert.Equal(suite.T(), http.StatusForbidden, msg.Status)
+}
+
+func (suite *HandlerTestSuite) TestBoard_Filters() {
+	url := suite.listen()
+	board := suite.openBoard(url+"/board?search=docs", bearer(suite.token, suite.workspace.ID))
+	suite.createTaggedTask("write-tests")
+	suite.createTaggedTask("write-docs")
+	suite.relay()
+
+	var data models.TaskStreamEvent
+	suite.Require().NoError(json.Unmarshal(suite.nextMessage(board, "task.created").Data, &data))
+	assert.Equal(suite.T(), "write-docs", data.Task.Title)
+}
+
+func (suite *HandlerTestSuite) TestBoard_ClosesLaggingConnections() {
+	url := suite.listen()
+	board := suite.openBoard(url+"/board", bearer(suite.token, suite.workspace.ID))
+	suite.nextMessage(board, models.BoardPresence)
+
+	// The broker drops clients that fall behind like this
+	suite.broker.DisconnectAll()
+	_, _, err := board.ReadMessage()
+	assert.True(suite.T(), websocket.IsCloseError(err, websocket.CloseTryAgainLater), "got %v", err)
+	suite.Require().Eventually(func() bool { return suite.boards.Viewers(suite.workspace.ID) == 0 }, time.Second, time.Millisecond)
+}
+
+// expectRevoked waits for the board to close a connection that is no
+// longer authorized.
+func (suite *HandlerTestSuite) expectRevoked(board *websocket.Conn, workspaceID int) {
+	board.SetReadDeadline(time.Now().Add(3 * time.Second))
+	for {
+		_, _, err := board.ReadMessage()
+		if err != nil {
+			assert.True(suite.T(), websocket.IsCloseError(err, websocket.ClosePolicyViolation), "got %v", err)
+			break
+		}
+	}
+	suite.Require().Eventually(func() bool { return suite.boards.Viewers(workspaceID) == 0 }, time.Second, time.Millisecond)
+}
+
+func (suite *HandlerTestSuite) TestBoard_ClosesRevokedTokens() {
+	task := suite.createTaggedTask("task")
+	created := suite.createAPIToken("board", models.TokenScopeReadWrite)
+	url := suite.listen()
+	board := suite.openBoard(url+"/board", bearer(created.Token, suite.workspace.ID))
+	suite.nextMessage(board, models.BoardPresence)
+	msg := suite.updateOnBoard(board, "1", task, `{"priority": "high"}`)
+	suite.Require().Equal(http.StatusOK, msg.Status)
+
+	resp, body := suite.makeRequest("DELETE", fmt.Sprintf("/tokens/%d", created.ID), nil)
+	suite.Require().Equal(http.StatusOK, resp.StatusCode, string(body))
+
+	// The next command finds the token revoked
+	cmd := models.BoardCommand{Type: models.BoardUpdateTask, Ref: "2", TaskID: task.ID, Changes: json.RawMessage(`{"priority": "low"}`)}
+	suite.Require().NoError(board.WriteJSON(cmd))
+	suite.expectRevoked(board, suite.workspace.ID)
+	_, fetched := suite.getTask(task.ID)
+	assert.Equal(suite.T(), models.TaskPriorityHigh, fetched.Priority)
+}
+
+func (suite *HandlerTestSuite) TestBoard_ClosesExpiredTokens() {
+	suite.boards.Recheck = 50 * time.Millisecond
+	defer func() { suite.boards.Recheck = time.Minute }()
+
+	token, _, err := auth.NewTokenManager(testJWTSecret, time.Second, time.Hour).Issue(suite.user.ID)
+	suite.Require().NoError(err)
+	url := suite.listen()
+	board := suite.openBoard(url+"/board", bearer(token, suite.workspace.ID))
+	suite.expectRevoked(board, suite.workspace.ID)
+}
+
+func (suite *HandlerTestSuite) TestBoard_ClosesRemovedMembers() {
+	suite.boards.Recheck = 20 * time.Millisecond
+	defer func() { suite.boards.Recheck = time.Minute }()
+
+	team := suite.createWorkspace("Team")
+	member, token := suite.joinWorkspace(team, "member@example.com", models.RoleViewer)
+	url := suite.listen()
+	board := suite.openBoard(url+"/board", bearer(token, team.ID))
+	suite.nextMessage(board, models.BoardPresence)
+
+	resp, body := suite.makeRequest("DELETE", fmt.Sprintf("/workspaces/%d/members/%d", team.ID, member.ID), nil)
+	suite.Require().Equal(http.StatusOK, resp.StatusCode, string(body))
+	suite.expectRevoked(board, team.ID)
+}
+
+func (suite *HandlerTestSuite) TestBoard_Rejected() {
+	resp, _ := suite.makeRequest("GET", "/board", nil)
+	assert.Equal(suite.T(), http.StatusUpgradeRequired, resp.StatusCode)
+
+	url := suite.listen()
+	_, resp, err := websocket.DefaultDialer.Dial(url+"/board", nil)
+	suite.Require().Error(err)
+	assert.Equal(suite.T(), http.StatusUnauthorized, resp.StatusCode)
+
+	_, resp, err = websocket.DefaultDialer.Dial(url+"/board?due_date=soon", bearer(suite.token, suite.workspace.ID))
+	suite.Require().Error(err)
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.StatusCode)
+}
@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"task/backend/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// ============================================================================
+// DEPENDENCY TESTS
+// ============================================================================
+
+func (suite *HandlerTestSuite) addDependency(task, dependsOn models.Task) (*http.Response, []byte) {
+	return suite.makeRequest("POST", fmt.Sprintf("/tasks/%d/dependencies", task.ID),
+		models.AddDependencyRequest{DependsOnID: dependsOn.ID})
+}
+
+func (suite *HandlerTestSuite) setStatus(task models.Task, status models.TaskStatus, ignoreDependencies bool) (*http.Response, []byte) {
+	return suite.makeRequest("PUT", fmt.Sprintf("/tasks/%d", task.ID),
+		models.UpdateTaskRequest{Status: &status, IgnoreDependencies: ignoreDependencies})
+}
+
+func (suite *HandlerTestSuite) TestDependencies_AddListRemove() {
+	design := suite.createTaggedTask("design")
+	build := suite.createTaggedTask("build")
+	ship := suite.createTaggedTask("ship")
+
+	resp, body := suite.addDependency(build, design)
+	suite.Require().Equal(http.StatusCreated, resp.StatusCode, string(body))
+	resp, body = suite.addDependency(ship, build)
+	suite.Require().Equal(http.StatusCreated, resp.StatusCode, string(body))
+
+	resp, body = suite.makeRequest("GET", fmt.Sprintf("/tasks/%d/dependencies", build.ID), nil)
+	suite.Require().Equal(http.StatusOK, resp.StatusCode)
+	var dependencies models.TaskDependencies
+	suite.Require().NoError(json.Unmarshal(body, &dependencies))
+	assert.Equal(suite.T(), []string{"design"}, taskTitles(dependencies.DependsOn))
+	assert.Equal(suite.T(), []string{"ship"}, taskTitles(dependencies.Dependents))
+
+	resp, _ = suite.addDependency(build, design)
+	assert.Equal(suite.T(), http.StatusConflict, resp.StatusCode)
+
+	resp, _ = suite.makeRequest("DELETE", fmt.Sprintf("/tasks/%d/dependencies/%d", build.ID, design.ID), nil)
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+	resp, _ = suite.makeRequest("DELETE", fmt.Sprintf("/tasks/%d/dependencies/%d", build.ID, design.ID), nil)
+	assert.Equal(suite.T(), http.StatusNotFound, resp.StatusCode)
+
+	// Deleting a task drops its dependencies
+	resp, _ = suite.makeRequest("DELETE", fmt.Sprintf("/tasks/%d", build.ID), nil)
+	suite.Require().Equal(http.StatusOK, resp.StatusCode)
+	_, fetched := suite.getTask(ship.ID)
+	assert.False(suite.T(), fetched.Blocked)
+}
+
+func (suite *HandlerTestSuite) TestDependencies_RejectCycles() {
+	a := suite.createTaggedTask("a")
+	b := suite.createTaggedTask("b")
+	c := suite.createTaggedTask("c")
+
+	resp, _ := suite.addDependency(b, a)
+	suite.Require().Equal(http.StatusCreated, resp.StatusCode)
+	resp, _ = suite.addDependency(c, b)
+	suite.Require().Equal(http.StatusCreated, resp.StatusCode)
+
+	resp, _ = suite.addDependency(a, c)
+	assert.Equal(suite.T(), http.StatusConflict, resp.StatusCode)
+	resp, _ = suite.addDependency(a, a)
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.StatusCode)
+
+	// A diamond is not a cycle
+	resp, _ = suite.addDependency(c, a)
+	assert.Equal(suite.T(), http.StatusCreated, resp.StatusCode)
+}
+
+func (suite *HandlerTestSuite) TestDependencies_ConcurrentCycle() {
+	for round := range 10 {
+		a := suite.createTaggedTask(fmt.Sprintf("a-%d", round))
+		b := suite.createTaggedTask(fmt.Sprintf("b-%d", round))
+
+		var wg sync.WaitGroup
+		statuses := make([]int, 2)
+		for i, pair := range [][2]models.Task{{a, b}, {b, a}} {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				resp, _ := suite.addDependency(pair[0], pair[1])
+				statuses[i] = resp.StatusCode
+			}()
+		}
+		wg.Wait()
+		assert.ElementsMatch(suite.T(), []int{http.StatusCreated, http.StatusConflict}, statuses)
+	}
+}
+
+func (suite *HandlerTestSuite) TestDependencies_PublishChanges() {
+	design := suite.createTaggedTask("design")
+	build := suite.createTaggedTask("build")
+	suite.relayedTypes()
+
+	closeStream := suite.openStream("", nil)
+	resp, _ := suite.addDependency(build, design)
+	suite.Require().Equal(http.StatusCreated, resp.StatusCode)
+	resp, _ = suite.makeRequest("DELETE", fmt.Sprintf("/tasks/%d/dependencies/%d", build.ID, design.ID), nil)
+	suite.Require().Equal(http.StatusOK, resp.StatusCode)
+	suite.relay()
+
+	// Live clients see the blocked flag change
+	stream := closeStream()
+	suite.Require().Equal([]string{"task.updated", "task.updated"}, stream.types())
+	assert.True(suite.T(), stream.events[0].Data.Task.Blocked)
+	assert.False(suite.T(), stream.events[1].Data.Task.Blocked)
+
+	entries := suite.history(build.ID, "").Entries
+	suite.Require().Len(entries, 3)
+	assert.Equal(suite.T(), map[string][2]any{"depends_on": {[]any{float64(design.ID)}, []any{}}}, changedFields(entries[0]))
+	assert.Equal(suite.T(), map[string][2]any{"depends_on": {[]any{}, []any{float64(design.ID)}}}, changedFields(entries[1]))
+}
+
+func (suite *HandlerTestSuite) TestDependencies_BlockedFlag() {
+	design := suite.createTaggedTask("design")
+	build := suite.createTaggedTask("build")
+	assert.False(suite.T(), build.Blocked)
+
+	resp, _ := suite.addDependency(build, design)
+	suite.Require().Equal(http.StatusCreated, resp.StatusCode)
+
+	_, fetched := suite.getTask(build.ID)
+	assert.True(suite.T(), fetched.Blocked)
+	page := suite.listPage("/tasks?sort=title")
+	suite.Require().Len(page.Tasks, 2)
+	assert.True(suite.T(), page.Tasks[0].Blocked)
+	assert.False(suite.T(), page.Tasks[1].Blocked)
+
+	suite.completeTask(design)
+	_, fetched = suite.getTask(build.ID)
+	assert.False(suite.T(), fetched.Blocked)
+}
+
+func (suite *HandlerTestSuite) TestDependencies_BlockedTaskCannotStart() {
+	design := suite.createTaggedTask("design")
+	build := suite.createTaggedTask("build")
+	resp, _ := suite.addDependency(build, design)
+	suite.Require().Equal(http.StatusCreated, resp.StatusCode)
+
+	resp, body := suite.setStatus(build, models.TaskStatusInProgress, false)
+	assert.Equal(suite.T(), http.StatusConflict, resp.StatusCode)
+	var conflict struct {
+		BlockedBy []int `json:"blocked_by"`
+	}
+	suite.Require().NoError(json.Unmarshal(body, &conflict))
+	assert.Equal(suite.T(), []int{design.ID}, conflict.BlockedBy)
+
+	resp, _ = suite.setStatus(build, models.TaskStatusCompleted, false)
+	assert.Equal(suite.T(), http.StatusConflict, resp.StatusCode)
+
+	// Other changes to a blocked task are fine
+	description := "Still waiting"
+	resp, _ = suite.makeRequest("PUT", fmt.Sprintf("/tasks/%d", build.ID), models.UpdateTaskRequest{Description: &description})
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+
+	resp, body = suite.setStatus(build, models.TaskStatusInProgress, true)
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode, string(body))
+
+	suite.completeTask(design)
+	resp, _ = suite.setStatus(build, models.TaskStatusCompleted, false)
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+}
+
+func (suite *HandlerTestSuite) TestDependencies_CustomStatuses() {
+	suite.setWorkflow(reviewWorkflow())
+	design := suite.createTaggedTask("design")
+	build := suite.createTaggedTask("build")
+	resp, _ := suite.setStatus(build, models.TaskStatusInProgress, false)
+	suite.Require().Equal(http.StatusOK, resp.StatusCode)
+	resp, _ = suite.addDependency(build, design)
+	suite.Require().Equal(http.StatusCreated, resp.StatusCode)
+
+	// Every status but the initial one counts as starting
+	resp, _ = suite.setStatus(build, models.TaskStatusReview, false)
+	assert.Equal(suite.T(), http.StatusConflict, resp.StatusCode)
+	resp, body := suite.setStatus(build, models.TaskStatusPending, false)
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode, string(body))
+
+	// A cancelled dependency no longer blocks
+	resp, body = suite.setStatus(design, models.TaskStatusCancelled, false)
+	suite.Require().Equal(http.StatusOK, resp.StatusCode, string(body))
+	_, fetched := suite.getTask(build.ID)
+	assert.False(suite.T(), fetched.Blocked)
+	page := suite.listPage("/tasks?sort=title")
+	suite.Require().Len(page.Tasks, 2)
+	assert.False(suite.T(), page.Tasks[0].Blocked)
+	resp, body = suite.setStatus(build, models.TaskStatusInProgress, false)
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode, string(body))
+}
+
+func (suite *HandlerTestSuite) TestDependencies_Errors() {
+	task := suite.createTaggedTask("task")
+
+	resp, _ := suite.makeRequest("POST", fmt.Sprintf("/tasks/%d/dependencies", task.ID), models.AddDependencyRequest{DependsOnID: 9999})
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.StatusCode)
+	resp, _ = suite.makeRequest("POST", fmt.Sprintf("/tasks/%d/dependencies", task.ID), models.AddDependencyRequest{})
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.StatusCode)
+	resp, _ = suite.makeRequest("POST", "/tasks/9999/dependencies", models.AddDependencyRequest{DependsOnID: task.ID})
+	assert.Equal(suite.T(), http.StatusNotFound, resp.StatusCode)
+
+	// Tasks of other workspaces cannot be depended on
+	team := suite.createWorkspace("Team")
+	resp, body := suite.makeRequestWithHeaders("POST", "/tasks", newTaskRequest("team-task"), suite.token, inWorkspace(team.ID))
+	suite.Require().Equal(http.StatusCreated, resp.StatusCode)
+	var teamTask models.Task
+	suite.Require().NoError(json.Unmarshal(body, &teamTask))
+	resp, _ = suite.addDependency(task, teamTask)
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.StatusCode)
+
+	_, viewerToken := suite.joinWorkspace(team, "viewer@example.com", models.RoleViewer)
+	resp, body = suite.makeRequestWithHeaders("POST", fmt.Sprintf("/tasks/%d/dependencies", teamTask.ID),
+		models.AddDependencyRequest{DependsOnID: teamTask.ID}, viewerToken, inWorkspace(team.ID))
+	suite.assertForbidden(resp, body, "update_task", models.RoleEditor)
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"task/backend/database"
+	"task/backend/events"
+	"task/backend/models"
+	"task/backend/repository"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// ============================================================================
+// EVENT TESTS
+// ============================================================================
+
+// relayedTypes relays the outbox and returns the types of the relayed events.
+func (suite *HandlerTestSuite) relayedTypes() []events.Type {
+	var relayed []events.Type
+	suite.bus.SubscribeAsync(func(event events.Event) {
+		relayed = append(relayed, event.Type())
+	})
+	_, err := suite.bus.Relay()
+	suite.Require().NoError(err)
+	return relayed
+}
+
+func (suite *HandlerTestSuite) TestEvents_TaskLifecycle() {
+	task := suite.createTaggedTask("write-docs")
+	resp, body := suite.setStatus(task, models.TaskStatusCompleted, false)
+	suite.Require().Equal(http.StatusOK, resp.StatusCode, string(body))
+	priority := models.TaskPriorityHigh
+	resp, _ = suite.makeRequest("PUT", fmt.Sprintf("/tasks/%d", task.ID), models.UpdateTaskRequest{Priority: &priority})
+	suite.Require().Equal(http.StatusOK, resp.StatusCode)
+	suite.deleteTask(task, "")
+	resp, _ = suite.restoreTask(task, nil)
+	suite.Require().Equal(http.StatusOK, resp.StatusCode)
+
+	assert.Equal(suite.T(), []events.Type{
+		events.TypeTaskCreated,
+		events.TypeTaskUpdated,
+		events.TypeStatusChanged,
+		events.TypeTaskUpdated,
+		events.TypeTaskDeleted,
+		events.TypeTaskRestored,
+	}, suite.relayedTypes())
+}
+
+func (suite *HandlerTestSuite) TestEvents_CompletingRecurringTask() {
+	task := suite.createRecurringTask("weekly-report", "FREQ=WEEKLY")
+	suite.relayedTypes()
+
+	suite.completeTask(task)
+	assert.Equal(suite.T(), []events.Type{
+		events.TypeTaskUpdated,
+		events.TypeStatusChanged,
+		events.TypeTaskCreated,
+	}, suite.relayedTypes())
+}
+
+func (suite *HandlerTestSuite) TestEvents_FailedSubscriberFailsTheChange() {
+	if suite.driver == database.DriverMemory {
+		suite.T().Skip("the memory store can't roll back")
+	}
+	suite.bus.Subscribe(func(tx *repository.Store, event events.Event) error {
+		return errors.New("subscriber failed")
+	})
+
+	resp, _ := suite.makeRequest("POST", "/tasks", newTaskRequest("write-docs"))
+	assert.Equal(suite.T(), http.StatusInternalServerError, resp.StatusCode)
+	assert.Empty(suite.T(), suite.listTitles("/tasks"))
+	assert.Empty(suite.T(), suite.relayedTypes())
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"task/backend/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// ============================================================================
+// RECURRENCE TESTS
+// ============================================================================
+
+// firstMonday is the due date of the recurring tasks in these tests.
+var firstMonday = time.Date(2030, time.January, 7, 9, 0, 0, 0, time.UTC)
+
+func (suite *HandlerTestSuite) createRecurringTask(title, rule string, tags ...string) models.Task {
+	request := newTaskRequest(title)
+	request.DueDate = &firstMonday
+	request.Recurrence = rule
+	request.Tags = tags
+	resp, body := suite.makeRequest("POST", "/tasks", request)
+	suite.Require().Equal(http.StatusCreated, resp.StatusCode, string(body))
+
+	var task models.Task
+	suite.Require().NoError(json.Unmarshal(body, &task))
+	return task
+}
+
+// series lists the tasks of a series in ID order.
+func (suite *HandlerTestSuite) series(seriesID int) []models.Task {
+	return suite.listPage(fmt.Sprintf("/tasks?series_id=%d", seriesID)).Tasks
+}
+
+func (suite *HandlerTestSuite) TestRecurrence_CreateNormalizesRule() {
+	task := suite.createRecurringTask("weekly-report", "rrule:freq=weekly;byday=mo")
+	assert.Equal(suite.T(), "FREQ=WEEKLY;BYDAY=MO", task.Recurrence)
+	suite.Require().NotNil(task.SeriesID)
+	assert.Equal(suite.T(), task.ID, *task.SeriesID)
+	assert.Equal(suite.T(), 1, task.Occurrence)
+
+	_, fetched := suite.getTask(task.ID)
+	assert.Equal(suite.T(), "FREQ=WEEKLY;BYDAY=MO", fetched.Recurrence)
+
+	request := newTaskRequest("bad-rule")
+	request.Recurrence = "FREQ=YEARLY"
+	resp, body := suite.makeRequest("POST", "/tasks", request)
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.StatusCode)
+	assert.Contains(suite.T(), string(body), "FREQ must be DAILY, WEEKLY or MONTHLY")
+}
+
+func (suite *HandlerTestSuite) TestRecurrence_CompletingCreatesNextOccurrence() {
+	task := suite.createRecurringTask("weekly-report", "FREQ=WEEKLY", "reports")
+	suite.completeTask(task)
+
+	tasks := suite.series(task.ID)
+	suite.Require().Len(tasks, 2)
+	assert.Empty(suite.T(), tasks[0].Recurrence, "the rule moves on to the next occurrence")
+
+	next := tasks[1]
+	assert.Equal(suite.T(), "weekly-report@2030-01-14", next.Title)
+	assert.Equal(suite.T(), models.TaskStatusPending, next.Status)
+	assert.Equal(suite.T(), "FREQ=WEEKLY", next.Recurrence)
+	assert.Equal(suite.T(), 2, next.Occurrence)
+	assert.Equal(suite.T(), []string{"reports"}, tagNames(next.Tags))
+	suite.Require().NotNil(next.DueDate)
+	assert.True(suite.T(), firstMonday.AddDate(0, 0, 7).Equal(*next.DueDate))
+
+	// Later occurrences are named after the series, not the previous one
+	suite.completeTask(next)
+	tasks = suite.series(task.ID)
+	suite.Require().Len(tasks, 3)
+	assert.Equal(suite.T(), "weekly-report@2030-01-21", tasks[2].Title)
+	assert.Equal(suite.T(), 3, tasks[2].Occurrence)
+
+	// Reopening and completing again doesn't create another occurrence
+	suite.setStatus(task, models.TaskStatusPending, false)
+	suite.completeTask(task)
+	assert.Len(suite.T(), suite.series(task.ID), 3)
+}
+
+func (suite *HandlerTestSuite) TestRecurrence_SeriesEnds() {
+	task := suite.createRecurringTask("twice", "FREQ=DAILY;COUNT=2")
+	suite.completeTask(task)
+	tasks := suite.series(task.ID)
+	suite.Require().Len(tasks, 2)
+
+	suite.completeTask(tasks[1])
+	assert.Len(suite.T(), suite.series(task.ID), 2)
+}
+
+func (suite *HandlerTestSuite) TestRecurrence_TitleClash() {
+	task := suite.createRecurringTask("standup", "FREQ=DAILY")
+
+	taken := newTaskRequest("standup@2030-01-08")
+	resp, _ := suite.makeRequest("POST", "/tasks", taken)
+	suite.Require().Equal(http.StatusCreated, resp.StatusCode)
+
+	suite.completeTask(task)
+	tasks := suite.series(task.ID)
+	suite.Require().Len(tasks, 2)
+	assert.Equal(suite.T(), "standup@2030-01-08-2", tasks[1].Title)
+}
+
+func (suite *HandlerTestSuite) TestRecurrence_LongTitle() {
+	title := strings.Repeat("x", 200)
+	task := suite.createRecurringTask(title, "FREQ=DAILY")
+
+	taken := newTaskRequest(title[:189] + "@2030-01-08")
+	resp, _ := suite.makeRequest("POST", "/tasks", taken)
+	suite.Require().Equal(http.StatusCreated, resp.StatusCode)
+
+	suite.completeTask(task)
+	tasks := suite.series(task.ID)
+	suite.Require().Len(tasks, 2)
+	assert.Equal(suite.T(), title[:187]+"@2030-01-08-2", tasks[1].Title)
+	assert.Len(suite.T(), tasks[1].Title, 200)
+}
+
+func (suite *HandlerTestSuite) TestRecurrence_SetAndClearOnUpdate() {
+	task := suite.createTaggedTask("chore")
+	taskURL := fmt.Sprintf("/tasks/%d", task.ID)
+
+	rule := "FREQ=MONTHLY;BYDAY=1MO"
+	resp, body := suite.makeRequest("PUT", taskURL, models.UpdateTaskRequest{Recurrence: &rule})
+	suite.Require().Equal(http.StatusOK, resp.StatusCode, string(body))
+	var updated models.Task
+	suite.Require().NoError(json.Unmarshal(body, &updated))
+	suite.Require().NotNil(updated.SeriesID)
+	assert.Equal(suite.T(), task.ID, *updated.SeriesID)
+
+	invalid := "FREQ=WEEKLY;BYDAY=1MO"
+	resp, _ = suite.makeRequest("PUT", taskURL, models.UpdateTaskRequest{Recurrence: &invalid})
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.StatusCode)
+
+	none := ""
+	resp, _ = suite.makeRequest("PUT", taskURL, models.UpdateTaskRequest{Recurrence: &none})
+	suite.Require().Equal(http.StatusOK, resp.StatusCode)
+	suite.completeTask(task)
+	assert.Len(suite.T(), suite.series(task.ID), 1)
+}
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"task/backend/models"
+	"task/backend/reminder"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// ============================================================================
+// REMINDER TESTS
+// ============================================================================
+
+func (suite *HandlerTestSuite) createTaskDue(title string, dueDate time.Time, reminders ...int) models.Task {
+	request := newTaskRequest(title)
+	request.DueDate = &dueDate
+	request.Reminders = reminders
+	resp, body := suite.makeRequest("POST", "/tasks", request)
+	suite.Require().Equal(http.StatusCreated, resp.StatusCode, string(body))
+
+	var task models.Task
+	suite.Require().NoError(json.Unmarshal(body, &task))
+	return task
+}
+
+// newScheduler returns a scheduler of the test's store with windows of 24
+// hours and 1 hour, like a server with the default configuration.
+func (suite *HandlerTestSuite) newScheduler() *reminder.Scheduler {
+	return reminder.NewScheduler(suite.bus, suite.store.Reminders, []time.Duration{24 * time.Hour, time.Hour})
+}
+
+func (suite *HandlerTestSuite) sendDue(scheduler *reminder.Scheduler, now time.Time) int {
+	sent, err := scheduler.SendDue(now)
+	suite.Require().NoError(err)
+	return sent
+}
+
+func minutesBefore(recv *webhookReceiver) []int {
+	recv.mu.Lock()
+	defer recv.mu.Unlock()
+	minutes := make([]int, len(recv.payloads))
+	for i, payload := range recv.payloads {
+		minutes[i] = payload.MinutesBefore
+	}
+	return minutes
+}
+
+func (suite *HandlerTestSuite) TestReminders_DefaultWindows() {
+	recv, url := suite.startReceiver(http.StatusOK)
+	suite.createWebhook(url, models.EventTaskDueSoon, models.EventTaskOverdue)
+	now := time.Now()
+	task := suite.createTaskDue("write-docs", now.Add(30*time.Hour))
+	scheduler := suite.newScheduler()
+
+	assert.Zero(suite.T(), suite.sendDue(scheduler, now))
+	assert.Equal(suite.T(), 1, suite.sendDue(scheduler, now.Add(6*time.Hour)))
+	assert.Zero(suite.T(), suite.sendDue(scheduler, now.Add(7*time.Hour)), "each window is reminded of once")
+
+	// A restarted server knows what was sent
+	scheduler = suite.newScheduler()
+	assert.Zero(suite.T(), suite.sendDue(scheduler, now.Add(8*time.Hour)))
+	assert.Equal(suite.T(), 1, suite.sendDue(scheduler, now.Add(29*time.Hour)))
+	assert.Equal(suite.T(), 1, suite.sendDue(scheduler, now.Add(30*time.Hour)))
+	assert.Zero(suite.T(), suite.sendDue(scheduler, now.Add(48*time.Hour)))
+
+	suite.deliverWebhooks()
+	assert.Equal(suite.T(), []models.WebhookEvent{models.EventTaskDueSoon, models.EventTaskDueSoon, models.EventTaskOverdue}, recv.events())
+	assert.Equal(suite.T(), []int{24 * 60, 60, 0}, minutesBefore(recv))
+	for _, payload := range recv.payloads {
+		assert.Equal(suite.T(), task.ID, payload.Task.ID)
+		assert.Zero(suite.T(), payload.ActorID, "reminders come from the server")
+	}
+}
+
+func (suite *HandlerTestSuite) TestReminders_OnlyClosestOpenWindow() {
+	recv, url := suite.startReceiver(http.StatusOK)
+	suite.createWebhook(url)
+	now := time.Now()
+	suite.createTaskDue("write-docs", now.Add(30*time.Minute))
+	scheduler := suite.newScheduler()
+
+	// The 24 hour window passed before the task was created
+	assert.Equal(suite.T(), 1, suite.sendDue(scheduler, now))
+	assert.Zero(suite.T(), suite.sendDue(scheduler, now.Add(time.Minute)))
+
+	// A server that was down while the task became overdue catches up
+	assert.Equal(suite.T(), 1, suite.sendDue(scheduler, now.Add(3*time.Hour)))
+
+	suite.deliverWebhooks()
+	assert.Equal(suite.T(), []models.WebhookEvent{models.EventTaskCreated, models.EventTaskDueSoon, models.EventTaskOverdue}, recv.events())
+	assert.Equal(suite.T(), []int{0, 60, 0}, minutesBefore(recv))
+}
+
+func (suite *HandlerTestSuite) TestReminders_TaskOffsets() {
+	now := time.Now()
+	task := suite.createTaskDue("write-docs", now.Add(3*time.Hour), 120, 15)
+	assert.Equal(suite.T(), []int{15, 120}, task.Reminders)
+	scheduler := suite.newScheduler()
+
+	assert.Zero(suite.T(), suite.sendDue(scheduler, now), "the task's offsets replace the default windows")
+	assert.Equal(suite.T(), 1, suite.sendDue(scheduler, now.Add(time.Hour)))
+	assert.Zero(suite.T(), suite.sendDue(scheduler, now.Add(2*time.Hour+30*time.Minute)))
+	assert.Equal(suite.T(), 1, suite.sendDue(scheduler, now.Add(2*time.Hour+45*time.Minute)))
+
+	// Clearing them brings the default windows back
+	reminders := []int{}
+	resp, body := suite.makeRequest("PUT", fmt.Sprintf("/tasks/%d", task.ID), models.UpdateTaskRequest{Reminders: &reminders})
+	suite.Require().Equal(http.StatusOK, resp.StatusCode, string(body))
+	_, task = suite.getTask(task.ID)
+	assert.Empty(suite.T(), task.Reminders)
+	assert.Zero(suite.T(), suite.sendDue(scheduler, now.Add(2*time.Hour+50*time.Minute)), "the 1 hour window is further away than the last reminder")
+	assert.Equal(suite.T(), 1, suite.sendDue(scheduler, now.Add(3*time.Hour)))
+}
+
+func (suite *HandlerTestSuite) TestReminders_MovedDueDate() {
+	now := time.Now()
+	task := suite.createTaskDue("write-docs", now.Add(30*time.Minute))
+	scheduler := suite.newScheduler()
+	assert.Equal(suite.T(), 1, suite.sendDue(scheduler, now))
+
+	dueDate := now.Add(5 * time.Hour)
+	resp, body := suite.makeRequest("PUT", fmt.Sprintf("/tasks/%d", task.ID), models.UpdateTaskRequest{DueDate: &dueDate})
+	suite.Require().Equal(http.StatusOK, resp.StatusCode, string(body))
+
+	assert.Equal(suite.T(), 1, suite.sendDue(scheduler, now), "the new due date opens the windows again")
+	assert.Zero(suite.T(), suite.sendDue(scheduler, now.Add(time.Hour)))
+	assert.Equal(suite.T(), 1, suite.sendDue(scheduler, now.Add(4*time.Hour)))
+}
+
+func (suite *HandlerTestSuite) TestReminders_SkipsClosedTasks() {
+	now := time.Now()
+	suite.completeTask(suite.createTaskDue("completed", now.Add(30*time.Minute)))
+	suite.deleteTask(suite.createTaskDue("trashed", now.Add(30*time.Minute)), "")
+	suite.createTaskDue("next-month", now.Add(30*24*time.Hour))
+
+	assert.Zero(suite.T(), suite.sendDue(suite.newScheduler(), now.Add(2*time.Hour)))
+}
+
+func (suite *HandlerTestSuite) TestReminders_ValidationErrors() {
+	for _, reminders := range [][]int{{0}, {7*24*60 + 1}, {30, 30}, {1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11}} {
+		request := newTaskRequest("write-docs")
+		request.Reminders = reminders
+		resp, body := suite.makeRequest("POST", "/tasks", request)
+		assert.Equal(suite.T(), http.StatusBadRequest, resp.StatusCode, "%v: %s", reminders, body)
+	}
+}
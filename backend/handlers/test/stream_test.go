@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"task/backend/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// ============================================================================
+// TASK STREAM TESTS
+// ============================================================================
+
+// streamEvent is one event read from the task stream.
+type streamEvent struct {
+	ID    string
+	Event string
+	Data  models.TaskStreamEvent
+}
+
+// taskStream is what a client of the task stream received.
+type taskStream struct {
+	events     []streamEvent
+	heartbeats int
+}
+
+func (s taskStream) types() []string {
+	types := make([]string, len(s.events))
+	for i, event := range s.events {
+		types[i] = event.Event
+	}
+	return types
+}
+
+// openStream connects to the task stream in the background. The returned
+// function disconnects it and returns what it received until then.
+func (suite *HandlerTestSuite) openStream(query string, headers map[string]string) func() taskStream {
+	connected := suite.broker.Clients()
+	req := httptest.NewRequest("GET", "/tasks/events"+query, nil)
+	req.Header.Set("Authorization", "Bearer "+suite.token)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	type result struct {
+		body []byte
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		resp, err := suite.app.Test(req, -1)
+		if err != nil {
+			done <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		done <- result{body: body, err: err}
+	}()
+	suite.Require().Eventually(func() bool { return suite.broker.Clients() > connected }, time.Second, time.Millisecond)
+
+	return func() taskStream {
+		suite.broker.DisconnectAll()
+		res := <-done
+		suite.Require().NoError(res.err)
+		return suite.parseStream(string(res.body))
+	}
+}
+
+func (suite *HandlerTestSuite) parseStream(body string) taskStream {
+	var stream taskStream
+	for _, block := range strings.Split(body, "\n\n") {
+		if block == "" {
+			continue
+		}
+		if strings.HasPrefix(block, ":") {
+			stream.heartbeats++
+			continue
+		}
+		var event streamEvent
+		for _, line := range strings.Split(block, "\n") {
+			field, value, _ := strings.Cut(line, ": ")
+			switch field {
+			case "id":
+				event.ID = value
+			case "event":
+				event.Event = value
+			case "data":
+				suite.Require().NoError(json.Unmarshal([]byte(value), &event.Data))
+			}
+		}
+		stream.events = append(stream.events, event)
+	}
+	return stream
+}
+
+// relay hands the published events to the stream.
+func (suite *HandlerTestSuite) relay() {
+	_, err := suite.bus.Relay()
+	suite.Require().NoError(err)
+}
+
+func (suite *HandlerTestSuite) rename(task models.Task, title string) {
+	resp, body := suite.makeRequest("PUT", fmt.Sprintf("/tasks/%d", task.ID), models.UpdateTaskRequest{Title: &title})
+	suite.Require().Equal(http.StatusOK, resp.StatusCode, string(body))
+}
+
+func (suite *HandlerTestSuite) TestStream_FilteredTaskChanges() {
+	closeStream := suite.openStream("?search=docs", nil)
+
+	docs := suite.createTaggedTask("write-docs")
+	other := suite.createTaggedTask("write-tests")
+	suite.rename(other, "review-docs")   // now matches
+	suite.rename(docs, "write-examples") // no longer matches
+	suite.completeTask(docs)             // matched neither before nor after
+	suite.deleteTask(other, "")
+
+	team := suite.createWorkspace("Team")
+	resp, _ := suite.makeRequestWithHeaders("POST", "/tasks", newTaskRequest("team-docs"), suite.token, inWorkspace(team.ID))
+	suite.Require().Equal(http.StatusCreated, resp.StatusCode)
+	suite.relay()
+
+	stream := closeStream()
+	assert.Equal(suite.T(), []string{"task.created", "task.updated", "task.updated", "task.deleted"}, stream.types())
+	suite.Require().Len(stream.events, 4)
+
+	assert.Equal(suite.T(), docs.ID, stream.events[0].Data.Task.ID)
+	assert.Equal(suite.T(), suite.user.ID, stream.events[0].Data.ActorID)
+	assert.Equal(suite.T(), "review-docs", stream.events[1].Data.Task.Title)
+	assert.Equal(suite.T(), map[string][2]any{"title": {"write-tests", "review-docs"}}, changedFields(models.AuditEntry{Changes: stream.events[1].Data.Changes}))
+	assert.Equal(suite.T(), "write-examples", stream.events[2].Data.Task.Title)
+	assert.Equal(suite.T(), other.ID, stream.events[3].Data.Task.ID)
+	assert.Less(suite.T(), stream.events[0].ID, stream.events[1].ID)
+}
+
+func (suite *HandlerTestSuite) TestStream_ResumesFromLastEventID() {
+	closeStream := suite.openStream("", nil)
+	for _, title := range []string{"first", "second", "third"} {
+		suite.createTaggedTask(title)
+	}
+	suite.relay()
+	first := closeStream().events[0]
+
+	closeStream = suite.openStream("?search=ir", map[string]string{"Last-Event-ID": first.ID})
+	replayed := closeStream().events
+	suite.Require().Len(replayed, 1, "the replay is filtered too")
+	assert.Equal(suite.T(), "third", replayed[0].Data.Task.Title)
+
+	// Events that aren't buffered any more can't be replayed
+	closeStream = suite.openStream("", map[string]string{"Last-Event-ID": "1"})
+	assert.Equal(suite.T(), []string{"reset"}, closeStream().types())
+
+	resp, _ := suite.makeRequestWithHeaders("GET", "/tasks/events", nil, suite.token, map[string]string{"Last-Event-ID": "latest"})
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.StatusCode)
+}
+
+func (suite *HandlerTestSuite) TestStream_Heartbeat() {
+	closeStream := suite.openStream("", nil)
+	time.Sleep(100 * time.Millisecond)
+	stream := closeStream()
+	assert.Positive(suite.T(), stream.heartbeats)
+	assert.Empty(suite.T(), stream.events)
+}
+
+func (suite *HandlerTestSuite) TestStream_InvalidFilter() {
+	resp, body := suite.makeRequest("GET", "/tasks/events?due_date=tomorrow", nil)
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.StatusCode, string(body))
+	assert.Zero(suite.T(), suite.broker.Clients())
+}
+
+func (suite *HandlerTestSuite) TestStream_LeavesOutStatusChangesAndPurges() {
+	task := suite.createTaggedTask("write-docs")
+	suite.relay()
+
+	closeStream := suite.openStream("", nil)
+	suite.completeTask(task)
+	suite.deleteTask(task, "")
+	resp, _ := suite.makeRequest("DELETE", fmt.Sprintf("/trash/%d", task.ID), nil)
+	suite.Require().Equal(http.StatusOK, resp.StatusCode)
+	suite.relay()
+	assert.Equal(suite.T(), []string{"task.updated", "task.deleted"}, closeStream().types())
+}
@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"task/backend/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// ============================================================================
+// SUBTASK TESTS
+// ============================================================================
+
+// createSubtask creates a task under parentID through the API.
+func (suite *HandlerTestSuite) createSubtask(title string, parentID int) models.Task {
+	request := newTaskRequest(title)
+	request.ParentID = &parentID
+	resp, body := suite.makeRequest("POST", "/tasks", request)
+	suite.Require().Equal(http.StatusCreated, resp.StatusCode, string(body))
+
+	var task models.Task
+	suite.Require().NoError(json.Unmarshal(body, &task))
+	return task
+}
+
+func (suite *HandlerTestSuite) completeTask(task models.Task) {
+	status := models.TaskStatusCompleted
+	resp, body := suite.makeRequest("PUT", fmt.Sprintf("/tasks/%d", task.ID), models.UpdateTaskRequest{Status: &status})
+	suite.Require().Equal(http.StatusOK, resp.StatusCode, string(body))
+}
+
+func (suite *HandlerTestSuite) getTask(id int) (*http.Response, models.Task) {
+	resp, body := suite.makeRequest("GET", fmt.Sprintf("/tasks/%d", id), nil)
+	var task models.Task
+	if resp.StatusCode == http.StatusOK {
+		suite.Require().NoError(json.Unmarshal(body, &task))
+	}
+	return resp, task
+}
+
+func taskTitles(tasks []models.Task) []string {
+	titles := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		titles = append(titles, task.Title)
+	}
+	return titles
+}
+
+func (suite *HandlerTestSuite) TestSubtasks_ChildrenAndTree() {
+	epic := suite.createTaggedTask("epic")
+	design := suite.createSubtask("design", epic.ID)
+	build := suite.createSubtask("build", epic.ID)
+	suite.createSubtask("backend", build.ID)
+	suite.createSubtask("frontend", build.ID)
+	suite.Require().NotNil(design.ParentID)
+	assert.Equal(suite.T(), epic.ID, *design.ParentID)
+
+	resp, body := suite.makeRequest("GET", fmt.Sprintf("/tasks/%d/children", epic.ID), nil)
+	suite.Require().Equal(http.StatusOK, resp.StatusCode, string(body))
+	var children []models.Task
+	suite.Require().NoError(json.Unmarshal(body, &children))
+	assert.Equal(suite.T(), []string{"design", "build"}, taskTitles(children))
+	assert.Nil(suite.T(), children[0].Progress)
+	suite.Require().NotNil(children[1].Progress)
+	assert.Equal(suite.T(), 2, children[1].Progress.Total)
+
+	resp, body = suite.makeRequest("GET", fmt.Sprintf("/tasks/%d/tree", epic.ID), nil)
+	suite.Require().Equal(http.StatusOK, resp.StatusCode, string(body))
+	var tree models.TaskNode
+	suite.Require().NoError(json.Unmarshal(body, &tree))
+	assert.Equal(suite.T(), "epic", tree.Title)
+	suite.Require().Len(tree.Children, 2)
+	assert.Empty(suite.T(), tree.Children[0].Children)
+	assert.Equal(suite.T(), "build", tree.Children[1].Title)
+	suite.Require().Len(tree.Children[1].Children, 2)
+	assert.Equal(suite.T(), "backend", tree.Children[1].Children[0].Title)
+
+	// Leaves report an empty list rather than null
+	resp, body = suite.makeRequest("GET", fmt.Sprintf("/tasks/%d/children", design.ID), nil)
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+	assert.JSONEq(suite.T(), "[]", string(body))
+
+	// Subtasks are still regular tasks in listings
+	assert.Len(suite.T(), suite.listTitles("/tasks?size=10"), 5)
+}
+
+func (suite *HandlerTestSuite) TestSubtasks_ProgressRollup() {
+	epic := suite.createTaggedTask("epic")
+	design := suite.createSubtask("design", epic.ID)
+	build := suite.createSubtask("build", epic.ID)
+	backend := suite.createSubtask("backend", build.ID)
+	suite.createSubtask("frontend", build.ID)
+
+	_, fetched := suite.getTask(epic.ID)
+	suite.Require().NotNil(fetched.Progress)
+	assert.Equal(suite.T(), models.TaskProgress{Completed: 0, Total: 2, Percent: 0}, *fetched.Progress)
+
+	// Half of build is done, so it counts as half a subtask of the epic
+	suite.completeTask(backend)
+	_, fetched = suite.getTask(epic.ID)
+	assert.Equal(suite.T(), models.TaskProgress{Completed: 0, Total: 2, Percent: 25}, *fetched.Progress)
+
+	suite.completeTask(design)
+	_, fetched = suite.getTask(epic.ID)
+	assert.Equal(suite.T(), models.TaskProgress{Completed: 1, Total: 2, Percent: 75}, *fetched.Progress)
+
+	// A completed subtask counts fully whatever its own subtasks say
+	suite.completeTask(build)
+	_, fetched = suite.getTask(epic.ID)
+	assert.Equal(suite.T(), models.TaskProgress{Completed: 2, Total: 2, Percent: 100}, *fetched.Progress)
+
+	// Tasks without subtasks report no progress
+	resp, body := suite.makeRequest("GET", fmt.Sprintf("/tasks/%d", design.ID), nil)
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+	assert.NotContains(suite.T(), string(body), `"progress"`)
+}
+
+func (suite *HandlerTestSuite) TestSubtasks_Reparent() {
+	epic := suite.createTaggedTask("epic")
+	child := suite.createSubtask("child", epic.ID)
+	grandchild := suite.createSubtask("grandchild", child.ID)
+	other := suite.createTaggedTask("other")
+
+	move := func(task models.Task, parentID int) *http.Response {
+		resp, _ := suite.makeRequest("PUT", fmt.Sprintf("/tasks/%d", task.ID), models.UpdateTaskRequest{ParentID: &parentID})
+		return resp
+	}
+
+	assert.Equal(suite.T(), http.StatusOK, move(child, other.ID).StatusCode)
+	_, fetched := suite.getTask(child.ID)
+	suite.Require().NotNil(fetched.ParentID)
+	assert.Equal(suite.T(), other.ID, *fetched.ParentID)
+
+	// Cycles are rejected
+	assert.Equal(suite.T(), http.StatusBadRequest, move(child, child.ID).StatusCode)
+	assert.Equal(suite.T(), http.StatusBadRequest, move(other, grandchild.ID).StatusCode)
+
+	// Zero makes the task top-level again
+	assert.Equal(suite.T(), http.StatusOK, move(child, 0).StatusCode)
+	_, fetched = suite.getTask(child.ID)
+	assert.Nil(suite.T(), fetched.ParentID)
+
+	// Moving an ancestor under a former descendant is fine once detached
+	assert.Equal(suite.T(), http.StatusOK, move(epic, grandchild.ID).StatusCode)
+}
+
+func (suite *HandlerTestSuite) TestSubtasks_ParentMustBeInWorkspace() {
+	request := newTaskRequest("orphan")
+	missing := 9999
+	request.ParentID = &missing
+	resp, body := suite.makeRequest("POST", "/tasks", request)
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.StatusCode)
+	assert.Contains(suite.T(), string(body), "Parent task not found")
+
+	// Tasks of another workspace cannot be parents
+	team := suite.createWorkspace("Team")
+	resp, body = suite.makeRequestWithHeaders("POST", "/tasks", newTaskRequest("team-epic"), suite.token, inWorkspace(team.ID))
+	suite.Require().Equal(http.StatusCreated, resp.StatusCode, string(body))
+	var teamEpic models.Task
+	suite.Require().NoError(json.Unmarshal(body, &teamEpic))
+
+	request.ParentID = &teamEpic.ID
+	resp, _ = suite.makeRequest("POST", "/tasks", request)
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.StatusCode)
+
+	resp, _ = suite.makeRequest("GET", fmt.Sprintf("/tasks/%d/tree", teamEpic.ID), nil)
+	assert.Equal(suite.T(), http.StatusNotFound, resp.StatusCode)
+}
+
+func (suite *HandlerTestSuite) TestSubtasks_DeleteModes() {
+	epic := suite.createTaggedTask("epic")
+	child := suite.createSubtask("child", epic.ID)
+	grandchild := suite.createSubtask("grandchild", child.ID)
+	epicURL := fmt.Sprintf("/tasks/%d", epic.ID)
+
+	// Tasks with subtasks are kept unless a mode is chosen
+	resp, _ := suite.makeRequest("DELETE", epicURL, nil)
+	assert.Equal(suite.T(), http.StatusConflict, resp.StatusCode)
+	resp, _ = suite.makeRequest("DELETE", epicURL+"?children=drop", nil)
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.StatusCode)
+
+	// Orphaning promotes the direct subtasks to top-level tasks
+	resp, _ = suite.makeRequest("DELETE", epicURL+"?children=orphan", nil)
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+	resp, fetched := suite.getTask(child.ID)
+	suite.Require().Equal(http.StatusOK, resp.StatusCode)
+	assert.Nil(suite.T(), fetched.ParentID)
+	_, fetched = suite.getTask(grandchild.ID)
+	suite.Require().NotNil(fetched.ParentID)
+	assert.Equal(suite.T(), child.ID, *fetched.ParentID)
+
+	// Cascading removes the whole subtree
+	resp, _ = suite.makeRequest("DELETE", fmt.Sprintf("/tasks/%d?children=cascade", child.ID), nil)
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+	resp, _ = suite.getTask(grandchild.ID)
+	assert.Equal(suite.T(), http.StatusNotFound, resp.StatusCode)
+	assert.Empty(suite.T(), suite.listTitles("/tasks"))
+
+	// Leaves delete under the default mode
+	leaf := suite.createTaggedTask("leaf", "backend")
+	resp, _ = suite.makeRequest("DELETE", fmt.Sprintf("/tasks/%d", leaf.ID), nil)
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+}
@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"task/backend/database"
+	"task/backend/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// ============================================================================
+// TAG TESTS
+// ============================================================================
+
+// createTaggedTask creates a task with tags through the API.
+func (suite *HandlerTestSuite) createTaggedTask(title string, tags ...string) models.Task {
+	request := newTaskRequest(title)
+	request.Tags = tags
+	resp, body := suite.makeRequest("POST", "/tasks", request)
+	suite.Require().Equal(http.StatusCreated, resp.StatusCode, string(body))
+
+	var task models.Task
+	suite.Require().NoError(json.Unmarshal(body, &task))
+	return task
+}
+
+func tagNames(tags []models.Tag) []string {
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+	return names
+}
+
+func (suite *HandlerTestSuite) TestTags_AttachOnCreate() {
+	task := suite.createTaggedTask("tagged-task", "customer-x", "backend", "backend")
+	assert.Equal(suite.T(), []string{"backend", "customer-x"}, tagNames(task.Tags))
+
+	resp, body := suite.makeRequest("GET", fmt.Sprintf("/tasks/%d", task.ID), nil)
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+	var fetched models.Task
+	suite.Require().NoError(json.Unmarshal(body, &fetched))
+	assert.Equal(suite.T(), []string{"backend", "customer-x"}, tagNames(fetched.Tags))
+
+	// Unknown names were created as workspace tags
+	resp, body = suite.makeRequest("GET", "/tags", nil)
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+	var tags []models.Tag
+	suite.Require().NoError(json.Unmarshal(body, &tags))
+	assert.Equal(suite.T(), []string{"backend", "customer-x"}, tagNames(tags))
+
+	// Untagged tasks have an empty list rather than null
+	untagged := suite.createTaggedTask("untagged-task")
+	resp, body = suite.makeRequest("GET", fmt.Sprintf("/tasks/%d", untagged.ID), nil)
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+	assert.Contains(suite.T(), string(body), `"tags":[]`)
+}
+
+func (suite *HandlerTestSuite) TestTags_RolledBackWithFailedChanges() {
+	if suite.driver == database.DriverMemory {
+		suite.T().Skip("the memory store can't roll back")
+	}
+	suite.createTaggedTask("taken", "kept")
+	task := suite.createTaggedTask("other")
+
+	request := newTaskRequest("taken")
+	request.Tags = []string{"kept", "orphan-on-create"}
+	resp, _ := suite.makeRequest("POST", "/tasks", request)
+	suite.Require().Equal(http.StatusConflict, resp.StatusCode)
+
+	title := "taken"
+	tags := []string{"orphan-on-update"}
+	resp, _ = suite.makeRequest("PUT", fmt.Sprintf("/tasks/%d", task.ID), models.UpdateTaskRequest{Title: &title, Tags: &tags})
+	suite.Require().Equal(http.StatusConflict, resp.StatusCode)
+
+	resp, body := suite.makeRequest("GET", "/tags", nil)
+	suite.Require().Equal(http.StatusOK, resp.StatusCode)
+	var listed []models.Tag
+	suite.Require().NoError(json.Unmarshal(body, &listed))
+	assert.Equal(suite.T(), []string{"kept"}, tagNames(listed))
+}
+
+func (suite *HandlerTestSuite) TestTags_ReplaceOnUpdate() {
+	task := suite.createTaggedTask("tagged-task", "backend", "urgent")
+	taskURL := fmt.Sprintf("/tasks/%d", task.ID)
+
+	update := func(request models.UpdateTaskRequest) []string {
+		resp, body := suite.makeRequest("PUT", taskURL, request)
+		suite.Require().Equal(http.StatusOK, resp.StatusCode, string(body))
+
+		resp, body = suite.makeRequest("GET", taskURL, nil)
+		suite.Require().Equal(http.StatusOK, resp.StatusCode)
+		var updated models.Task
+		suite.Require().NoError(json.Unmarshal(body, &updated))
+		return tagNames(updated.Tags)
+	}
+
+	tags := []string{"backend", "frontend"}
+	assert.Equal(suite.T(), []string{"backend", "frontend"}, update(models.UpdateTaskRequest{Tags: &tags}))
+
+	// Omitting tags leaves them alone
+	description := "New description"
+	assert.Equal(suite.T(), []string{"backend", "frontend"}, update(models.UpdateTaskRequest{Description: &description}))
+
+	none := []string{}
+	assert.Empty(suite.T(), update(models.UpdateTaskRequest{Tags: &none}))
+}
+
+func (suite *HandlerTestSuite) TestTags_RenameAndDelete() {
+	task := suite.createTaggedTask("tagged-task", "backend", "customer-x")
+	backend := task.Tags[0]
+	taskURL := fmt.Sprintf("/tasks/%d", task.ID)
+
+	resp, body := suite.makeRequest("PUT", fmt.Sprintf("/tags/%d", backend.ID), models.RenameTagRequest{Name: "api"})
+	suite.Require().Equal(http.StatusOK, resp.StatusCode, string(body))
+
+	resp, body = suite.makeRequest("GET", taskURL, nil)
+	suite.Require().Equal(http.StatusOK, resp.StatusCode)
+	var fetched models.Task
+	suite.Require().NoError(json.Unmarshal(body, &fetched))
+	assert.Equal(suite.T(), []string{"api", "customer-x"}, tagNames(fetched.Tags))
+
+	resp, _ = suite.makeRequest("PUT", fmt.Sprintf("/tags/%d", backend.ID), models.RenameTagRequest{Name: "customer-x"})
+	assert.Equal(suite.T(), http.StatusConflict, resp.StatusCode)
+
+	resp, _ = suite.makeRequest("DELETE", fmt.Sprintf("/tags/%d", backend.ID), nil)
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+
+	resp, body = suite.makeRequest("GET", taskURL, nil)
+	suite.Require().Equal(http.StatusOK, resp.StatusCode)
+	suite.Require().NoError(json.Unmarshal(body, &fetched))
+	assert.Equal(suite.T(), []string{"customer-x"}, tagNames(fetched.Tags))
+
+	resp, _ = suite.makeRequest("DELETE", fmt.Sprintf("/tags/%d", backend.ID), nil)
+	assert.Equal(suite.T(), http.StatusNotFound, resp.StatusCode)
+}
+
+func (suite *HandlerTestSuite) TestTags_Filter() {
+	suite.createTaggedTask("both", "backend", "customer-x")
+	suite.createTaggedTask("backend-only", "backend")
+	suite.createTaggedTask("customer-only", "customer-x")
+	suite.createTaggedTask("untagged")
+
+	assert.Equal(suite.T(), []string{"both", "backend-only"}, suite.listTitles("/tasks?tag=backend"))
+	assert.Equal(suite.T(), []string{"both", "backend-only", "customer-only"}, suite.listTitles("/tasks?tag=backend,customer-x"))
+	assert.Equal(suite.T(), []string{"both"}, suite.listTitles("/tasks?tag=backend,customer-x&tag_mode=all"))
+	assert.Empty(suite.T(), suite.listTitles("/tasks?tag=backend,unknown&tag_mode=all"))
+
+	page := suite.listPage("/tasks?tag=customer-x")
+	suite.Require().NotNil(page.Total)
+	assert.Equal(suite.T(), int64(2), *page.Total)
+
+	resp, _ := suite.makeRequest("GET", "/tasks?tag=backend&tag_mode=some", nil)
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.StatusCode)
+}
+
+func (suite *HandlerTestSuite) TestTags_Errors() {
+	resp, body := suite.makeRequest("POST", "/tags", models.CreateTagRequest{Name: "backend"})
+	suite.Require().Equal(http.StatusCreated, resp.StatusCode, string(body))
+	var tag models.Tag
+	suite.Require().NoError(json.Unmarshal(body, &tag))
+
+	resp, _ = suite.makeRequest("POST", "/tags", models.CreateTagRequest{Name: "backend"})
+	assert.Equal(suite.T(), http.StatusConflict, resp.StatusCode)
+
+	resp, _ = suite.makeRequest("POST", "/tags", models.CreateTagRequest{Name: "a,b"})
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.StatusCode)
+
+	request := newTaskRequest("comma-tag")
+	request.Tags = []string{"a,b"}
+	resp, _ = suite.makeRequest("POST", "/tasks", request)
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.StatusCode)
+
+	// Tags of other workspaces are invisible
+	team := suite.createWorkspace("Team")
+	resp, _ = suite.makeRequestWithHeaders("DELETE", fmt.Sprintf("/tags/%d", tag.ID), nil, suite.token, inWorkspace(team.ID))
+	assert.Equal(suite.T(), http.StatusNotFound, resp.StatusCode)
+
+	// The same name can exist in another workspace
+	resp, _ = suite.makeRequestWithHeaders("POST", "/tags", models.CreateTagRequest{Name: "backend"}, suite.token, inWorkspace(team.ID))
+	assert.Equal(suite.T(), http.StatusCreated, resp.StatusCode)
+
+	_, viewerToken := suite.joinWorkspace(team, "viewer@example.com", models.RoleViewer)
+	resp, _ = suite.makeRequestWithHeaders("GET", "/tags", nil, viewerToken, inWorkspace(team.ID))
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+	resp, body = suite.makeRequestWithHeaders("POST", "/tags", models.CreateTagRequest{Name: "frontend"}, viewerToken, inWorkspace(team.ID))
+	suite.assertForbidden(resp, body, "manage_tags", models.RoleEditor)
+}
+
+func (suite *HandlerTestSuite) TestTags_DeletedTaskReleasesTags() {
+	task := suite.createTaggedTask("tagged-task", "backend")
+
+	resp, _ := suite.makeRequest("DELETE", fmt.Sprintf("/tasks/%d", task.ID), nil)
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+
+	// Reusing the title gives a fresh, untagged task
+	dueDate := time.Now().Add(24 * time.Hour)
+	recreated := suite.createTestTask("tagged-task", "Description", models.TaskStatusPending, &dueDate)
+	fetched, err := suite.store.Tasks.GetByID(recreated.ID)
+	suite.Require().NoError(err)
+	assert.Empty(suite.T(), fetched.Tags)
+}
@@ -2,23 +2,32 @@ package handlers
 
 import (
 	"bytes"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"strings"
 	"testing"
 	"time"
 
+	"task/backend/audit"
+	"task/backend/auth"
 	"task/backend/database"
+	"task/backend/events"
 	"task/backend/handlers"
 	"task/backend/models"
+	"task/backend/repository"
+	"task/backend/routes"
+	"task/backend/stream"
+	"task/backend/webhook"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/joho/godotenv"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 	"gorm.io/driver/postgres"
@@ -26,94 +35,169 @@ import (
 	"gorm.io/gorm/logger"
 )
 
+const (
+	testJWTSecret = "test-secret-test-secret-test-secret"
+	testPassword  = "correct-horse-battery"
+)
+
+// tables lists every table the suite cleans on the shared PostgreSQL database
+var tables = []string{"outbox_events", "webhook_deliveries", "webhooks", "audit_entries", "workflows", "task_dependencies", "task_reminders", "task_tags", "tags", "tasks", "workspace_members", "workspaces", "api_tokens", "users"}
+
 type HandlerTestSuite struct {
 	suite.Suite
-	app        *fiber.App
-	db         *gorm.DB
-	originalDB *gorm.DB
+	driver       string
+	app          *fiber.App
+	store        *repository.Store
+	bus          *events.Bus
+	broker       *stream.Broker
+	tasks        *handlers.TaskHandler
+	boards       *handlers.BoardHandler
+	webhooks     *handlers.WebhookHandler
+	tokens       *auth.TokenManager
+	passwordHash string
+	user         models.User
+	token        string
+	workspace    *models.Workspace
+	db           *gorm.DB
+	originalDB   *gorm.DB
 }
 
 func (suite *HandlerTestSuite) SetupSuite() {
-	// Load .env file
-	err := godotenv.Load("../../../.env")
-	suite.Require().NoError(err, "Error loading .env file")
-
-	// Setup PostgreSQL database for testing
-	dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
-		"localhost",
-		5432,
-		os.Getenv("DB_USER"),
-		os.Getenv("DB_PASSWORD"),
-		"task_manager_test",
-	)
-
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Silent),
-	})
-
-	if err != nil {
-		// Fallback to main database
-		dsn = fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
-			"localhost", 5432, os.Getenv("DB_USER"), os.Getenv("DB_PASSWORD"), "task_manager",
-		)
-		db, err = gorm.Open(postgres.Open(dsn), &gorm.Config{
+	// PostgreSQL runs against a dedicated test database shared by all tests
+	if suite.driver == database.DriverPostgres {
+		db, err := gorm.Open(postgres.Open(os.Getenv("TEST_DATABASE_DSN")), &gorm.Config{
 			Logger: logger.Default.LogMode(logger.Silent),
 		})
 		suite.Require().NoError(err, "Failed to connect to PostgreSQL database")
-	}
 
-	// Store the original connection
-	suite.originalDB = db
-	database.DB = db
+		// Migrate the schema
+		err = database.NewMigrator(db).Up()
+		suite.Require().NoError(err, "Failed to migrate database schema")
 
-	// Migrate the schema
-	err = db.AutoMigrate(&models.Task{})
-	suite.Require().NoError(err, "Failed to migrate database schema")
+		suite.originalDB = db
+	}
 
-	// Setup Fiber app
-	suite.app = fiber.New()
-	suite.setupRoutes()
+	suite.tokens = auth.NewTokenManager(testJWTSecret, 15*time.Minute, time.Hour)
+
+	// bcrypt is deliberately slow, so hash the shared test password once
+	hash, err := auth.HashPassword(testPassword)
+	suite.Require().NoError(err)
+	suite.passwordHash = hash
 }
 
 func (suite *HandlerTestSuite) SetupTest() {
-	// Clean the database before each test
-	suite.originalDB.Exec("DELETE FROM tasks")
+	switch suite.driver {
+	case database.DriverMemory:
+		suite.store = repository.NewMemoryStore()
+
+	case database.DriverSQLite:
+		// Every test gets its own private in-memory SQLite database. A single
+		// connection keeps GORM from opening a second, empty database.
+		db, err := database.Open(database.DriverSQLite, ":memory:")
+		suite.Require().NoError(err)
+		sqlDB, err := db.DB()
+		suite.Require().NoError(err)
+		sqlDB.SetMaxOpenConns(1)
+		db.Logger = logger.Default.LogMode(logger.Silent)
+		suite.Require().NoError(database.NewMigrator(db).Up())
+
+		suite.db = db
+		suite.store = repository.NewGormStore(db)
 
-	// Start a new transaction for each test
-	suite.db = suite.originalDB.Begin()
-	suite.Require().NoError(suite.db.Error)
-	database.DB = suite.db
+	case database.DriverPostgres:
+		// Clean the database before each test
+		suite.cleanDatabase()
+
+		// Start a new transaction for each test
+		suite.db = suite.originalDB.Begin()
+		suite.Require().NoError(suite.db.Error)
+		suite.store = repository.NewGormStore(suite.db)
+	}
+
+	suite.bus = events.NewBus(suite.store)
+	audit.Subscribe(suite.bus)
+	webhook.Subscribe(suite.bus)
+	suite.broker = stream.NewBroker(100)
+	suite.broker.Subscribe(suite.bus)
+	streams := handlers.NewStreamHandler(suite.broker)
+	streams.Heartbeat = 20 * time.Millisecond
+
+	// Setup Fiber app
+	suite.app = fiber.New(fiber.Config{DisableStartupMessage: true})
+	suite.tasks = handlers.NewTaskHandler(suite.store.Tasks, suite.store.Workflows, suite.store.Audit, suite.bus)
+	suite.boards = handlers.NewBoardHandler(suite.app, suite.broker, suite.store.Users)
+	// Test receivers listen on the loopback interface
+	suite.webhooks = handlers.NewWebhookHandler(suite.store.Webhooks, true)
+	routes.Setup(suite.app, routes.Handlers{
+		Tasks:       suite.tasks,
+		Tags:        handlers.NewTagHandler(suite.store.Tags),
+		Workflows:   handlers.NewWorkflowHandler(suite.store.Workflows, suite.store.Tasks),
+		Webhooks:    suite.webhooks,
+		Stream:      streams,
+		Board:       suite.boards,
+		Auth:        handlers.NewAuthHandler(suite.store.Users, suite.tokens),
+		APITokens:   handlers.NewAPITokenHandler(suite.store.APITokens),
+		Workspaces:  handlers.NewWorkspaceHandler(suite.store.Workspaces, suite.store.Users),
+		RequireAuth: auth.Middleware(suite.tokens, suite.store.APITokens),
+
+		ResolveWorkspace: auth.Workspace(suite.store.Workspaces),
+	})
+
+	suite.user, suite.token = suite.createTestUser("owner@example.com")
+
+	workspace, err := repository.EnsurePersonalWorkspace(suite.store.Workspaces, suite.user.ID)
+	suite.Require().NoError(err)
+	suite.workspace = workspace
 }
 
 func (suite *HandlerTestSuite) TearDownTest() {
-	// Rollback transaction after each test
-	if suite.db != nil {
-		result := suite.db.Rollback()
-		// Check for rollback errors but don't fail the test
-		if result.Error != nil && !errors.Is(result.Error, gorm.ErrInvalidTransaction) {
-			suite.T().Logf("Rollback error: %v", result.Error)
+	switch suite.driver {
+	case database.DriverSQLite:
+		if sqlDB, err := suite.db.DB(); err == nil {
+			sqlDB.Close()
 		}
-	}
-	// Reset to original connection
-	database.DB = suite.originalDB
 
-	// Clean up any remaining data
-	suite.originalDB.Exec("DELETE FROM tasks")
+	case database.DriverPostgres:
+		// Rollback transaction after each test
+		if suite.db != nil {
+			result := suite.db.Rollback()
+			// Check for rollback errors but don't fail the test
+			if result.Error != nil && !errors.Is(result.Error, gorm.ErrInvalidTransaction) {
+				suite.T().Logf("Rollback error: %v", result.Error)
+			}
+		}
+
+		// Clean up any remaining data
+		suite.cleanDatabase()
+	}
 }
 
 func (suite *HandlerTestSuite) TearDownSuite() {
 	// Final cleanup
 	if suite.originalDB != nil {
-		suite.originalDB.Exec("DELETE FROM tasks")
+		suite.cleanDatabase()
+	}
+}
+
+func (suite *HandlerTestSuite) cleanDatabase() {
+	for _, table := range tables {
+		suite.originalDB.Exec("DELETE FROM " + table)
 	}
 }
 
-func (suite *HandlerTestSuite) setupRoutes() {
-	suite.app.Post("/tasks", handlers.CreateTask)
-	suite.app.Get("/tasks", handlers.GetAllTasks)
-	suite.app.Get("/tasks/:title", handlers.GetTask)
-	suite.app.Put("/tasks/:title", handlers.UpdateTask)
-	suite.app.Delete("/tasks/:title", handlers.DeleteTask)
+// createTestUser stores a user with testPassword and returns an access token.
+func (suite *HandlerTestSuite) createTestUser(email string) (models.User, string) {
+	user := models.User{
+		Email:        email,
+		PasswordHash: suite.passwordHash,
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+	}
+	suite.Require().NoError(suite.store.Users.Create(&user))
+
+	token, _, err := suite.tokens.Issue(user.ID)
+	suite.Require().NoError(err)
+	return user, token
 }
 
 func (suite *HandlerTestSuite) createTestTask(title, description string, status models.TaskStatus, dueDate *time.Time) models.Task {
@@ -122,15 +206,29 @@ func (suite *HandlerTestSuite) createTestTask(title, description string, status
 		Description: description,
 		Status:      status,
 		DueDate:     dueDate,
+		OwnerID:     suite.user.ID,
+		WorkspaceID: suite.workspace.ID,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
-	result := suite.db.Create(&task)
-	suite.Require().NoError(result.Error)
+	err := suite.store.Tasks.Create(&task)
+	suite.Require().NoError(err)
 	return task
 }
 
+// makeRequest sends a request authenticated as the suite's default user.
 func (suite *HandlerTestSuite) makeRequest(method, url string, body interface{}) (*http.Response, []byte) {
+	return suite.makeRequestWithToken(method, url, body, suite.token)
+}
+
+// makeRequestWithToken sends a request with the given bearer token, or
+// without authentication when token is empty.
+func (suite *HandlerTestSuite) makeRequestWithToken(method, url string, body interface{}, token string) (*http.Response, []byte) {
+	return suite.makeRequestWithHeaders(method, url, body, token, nil)
+}
+
+// makeRequestWithHeaders is makeRequestWithToken with extra request headers.
+func (suite *HandlerTestSuite) makeRequestWithHeaders(method, url string, body interface{}, token string, headers map[string]string) (*http.Response, []byte) {
 	var reqBody io.Reader
 	if body != nil {
 		jsonBody, err := json.Marshal(body)
@@ -140,6 +238,12 @@ func (suite *HandlerTestSuite) makeRequest(method, url string, body interface{})
 
 	req := httptest.NewRequest(method, url, reqBody)
 	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
 
 	resp, err := suite.app.Test(req, -1)
 	suite.Require().NoError(err)
@@ -161,6 +265,7 @@ func (suite *HandlerTestSuite) TestCreateTask_Success() {
 		Title:       "test-task",
 		Description: "Test description",
 		Status:      models.TaskStatusPending,
+		Priority:    models.TaskPriorityHigh,
 		DueDate:     &futureDate,
 	}
 
@@ -175,9 +280,11 @@ func (suite *HandlerTestSuite) TestCreateTask_Success() {
 	assert.Equal(suite.T(), "test-task", task.Title)
 	assert.Equal(suite.T(), "Test description", task.Description)
 	assert.Equal(suite.T(), models.TaskStatusPending, task.Status)
+	assert.Equal(suite.T(), models.TaskPriorityHigh, task.Priority)
 	assert.NotNil(suite.T(), task.DueDate)
 	assert.True(suite.T(), task.DueDate.After(time.Now()))
 	assert.NotZero(suite.T(), task.ID)
+	assert.Equal(suite.T(), suite.user.ID, task.OwnerID)
 	assert.NotZero(suite.T(), task.CreatedAt)
 	assert.NotZero(suite.T(), task.UpdatedAt)
 }
@@ -218,7 +325,8 @@ func (suite *HandlerTestSuite) TestCreateTask_WithoutOptionalFields() {
 
 	assert.Equal(suite.T(), "minimal-task", task.Title)
 	assert.Empty(suite.T(), task.Description)
-	assert.Equal(suite.T(), models.TaskStatusPending, task.Status) // Default status
+	assert.Equal(suite.T(), models.TaskStatusPending, task.Status)     // Default status
+	assert.Equal(suite.T(), models.TaskPriorityMedium, task.Priority) // Default priority
 }
 
 func (suite *HandlerTestSuite) TestCreateTask_DuplicateTitle() {
@@ -287,6 +395,15 @@ func (suite *HandlerTestSuite) TestCreateTask_ValidationErrors() {
 			},
 			expectedErr: "future",
 		},
+		{
+			name: "Unknown priority",
+			request: models.CreateTaskRequest{
+				Title:    "unknown-priority-task",
+				Priority: "critical",
+				DueDate:  func() *time.Time { t := time.Now().Add(24 * time.Hour); return &t }(),
+			},
+			expectedErr: "oneof",
+		},
 		{
 			name: "Current time (not future)",
 			request: models.CreateTaskRequest{
@@ -314,6 +431,7 @@ func (suite *HandlerTestSuite) TestCreateTask_ValidationErrors() {
 func (suite *HandlerTestSuite) TestCreateTask_InvalidJSON() {
 	req := httptest.NewRequest("POST", "/tasks", strings.NewReader("invalid json"))
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+suite.token)
 
 	resp, err := suite.app.Test(req)
 	suite.Require().NoError(err)
@@ -337,7 +455,7 @@ func (suite *HandlerTestSuite) TestGetTask_Success() {
 	futureDate := time.Now().Add(24 * time.Hour)
 	task := suite.createTestTask("test-get-task", "Test description", models.TaskStatusInProgress, &futureDate)
 
-	resp, body := suite.makeRequest("GET", "/tasks/test-get-task", nil)
+	resp, body := suite.makeRequest("GET", fmt.Sprintf("/tasks/%d", task.ID), nil)
 
 	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
 
@@ -353,7 +471,7 @@ func (suite *HandlerTestSuite) TestGetTask_Success() {
 }
 
 func (suite *HandlerTestSuite) TestGetTask_NotFound() {
-	resp, body := suite.makeRequest("GET", "/tasks/non-existent-task", nil)
+	resp, body := suite.makeRequest("GET", "/tasks/9999", nil)
 
 	assert.Equal(suite.T(), http.StatusNotFound, resp.StatusCode)
 
@@ -363,6 +481,19 @@ func (suite *HandlerTestSuite) TestGetTask_NotFound() {
 	assert.Equal(suite.T(), "Task not found", errorResp["error"])
 }
 
+func (suite *HandlerTestSuite) TestGetTask_InvalidID() {
+	for _, url := range []string{"/tasks/abc", "/tasks/0", "/tasks/-1"} {
+		resp, body := suite.makeRequest("GET", url, nil)
+
+		assert.Equal(suite.T(), http.StatusBadRequest, resp.StatusCode, url)
+
+		var errorResp map[string]interface{}
+		err := json.Unmarshal(body, &errorResp)
+		suite.Require().NoError(err)
+		assert.Equal(suite.T(), "Invalid task ID", errorResp["error"])
+	}
+}
+
 // ============================================================================
 // GET ALL TASKS TESTS
 // ============================================================================
@@ -384,7 +515,7 @@ func (suite *HandlerTestSuite) TestGetAllTasks_Success() {
 	err := json.Unmarshal(body, &tasksResp)
 	suite.Require().NoError(err)
 
-	assert.Equal(suite.T(), int64(3), tasksResp.Total)
+	assert.Equal(suite.T(), int64(3), *tasksResp.Total)
 	assert.Equal(suite.T(), 1, tasksResp.Page)
 	assert.Equal(suite.T(), 10, tasksResp.Size)
 	assert.Len(suite.T(), tasksResp.Tasks, 3)
@@ -399,7 +530,7 @@ func (suite *HandlerTestSuite) TestGetAllTasks_EmptyDatabase() {
 	err := json.Unmarshal(body, &tasksResp)
 	suite.Require().NoError(err)
 
-	assert.Equal(suite.T(), int64(0), tasksResp.Total)
+	assert.Equal(suite.T(), int64(0), *tasksResp.Total)
 	assert.Equal(suite.T(), 1, tasksResp.Page)
 	assert.Equal(suite.T(), 10, tasksResp.Size)
 	assert.Len(suite.T(), tasksResp.Tasks, 0)
@@ -419,11 +550,129 @@ func (suite *HandlerTestSuite) TestGetAllTasks_WithStatusFilter() {
 	err := json.Unmarshal(body, &tasksResp)
 	suite.Require().NoError(err)
 
-	assert.Equal(suite.T(), int64(1), tasksResp.Total)
+	assert.Equal(suite.T(), int64(1), *tasksResp.Total)
 	assert.Len(suite.T(), tasksResp.Tasks, 1)
 	assert.Equal(suite.T(), models.TaskStatusPending, tasksResp.Tasks[0].Status)
 }
 
+// setPriority stores a new priority for a task created by createTestTask.
+func (suite *HandlerTestSuite) setPriority(task *models.Task, priority models.TaskPriority) {
+	task.Priority = priority
+	suite.Require().NoError(suite.store.Tasks.Update(task))
+}
+
+func (suite *HandlerTestSuite) TestGetAllTasks_WithPriorityFilter() {
+	futureDate := time.Now().Add(24 * time.Hour)
+	urgent := suite.createTestTask("urgent-task", "Description", models.TaskStatusPending, &futureDate)
+	suite.setPriority(&urgent, models.TaskPriorityUrgent)
+	suite.createTestTask("medium-task", "Description", models.TaskStatusPending, &futureDate)
+
+	resp, body := suite.makeRequest("GET", "/tasks?priority=urgent", nil)
+
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+
+	var tasksResp models.TasksResponse
+	err := json.Unmarshal(body, &tasksResp)
+	suite.Require().NoError(err)
+
+	assert.Equal(suite.T(), int64(1), *tasksResp.Total)
+	suite.Require().Len(tasksResp.Tasks, 1)
+	assert.Equal(suite.T(), "urgent-task", tasksResp.Tasks[0].Title)
+}
+
+func (suite *HandlerTestSuite) TestGetAllTasks_SortByPriority() {
+	soon := time.Now().Add(24 * time.Hour)
+	later := time.Now().Add(48 * time.Hour)
+
+	low := suite.createTestTask("low-task", "Description", models.TaskStatusPending, &soon)
+	suite.setPriority(&low, models.TaskPriorityLow)
+	highLater := suite.createTestTask("high-later", "Description", models.TaskStatusPending, &later)
+	suite.setPriority(&highLater, models.TaskPriorityHigh)
+	highUndated := suite.createTestTask("high-undated", "Description", models.TaskStatusPending, nil)
+	suite.setPriority(&highUndated, models.TaskPriorityHigh)
+	highSoon := suite.createTestTask("high-soon", "Description", models.TaskStatusPending, &soon)
+	suite.setPriority(&highSoon, models.TaskPriorityHigh)
+	suite.createTestTask("medium-task", "Description", models.TaskStatusPending, &soon)
+	urgent := suite.createTestTask("urgent-task", "Description", models.TaskStatusPending, &later)
+	suite.setPriority(&urgent, models.TaskPriorityUrgent)
+
+	titles := suite.listTitles("/tasks?sort=priority")
+	assert.Equal(suite.T(), []string{"urgent-task", "high-soon", "high-later", "high-undated", "medium-task", "low-task"}, titles)
+}
+
+func (suite *HandlerTestSuite) TestGetAllTasks_InvalidPriority() {
+	resp, _ := suite.makeRequest("GET", "/tasks?priority=critical", nil)
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.StatusCode)
+
+	futureDate := time.Now().Add(24 * time.Hour)
+	task := suite.createTestTask("priority-task", "Description", models.TaskStatusPending, &futureDate)
+	invalid := models.TaskPriority("critical")
+	resp, _ = suite.makeRequest("PUT", fmt.Sprintf("/tasks/%d", task.ID), models.UpdateTaskRequest{Priority: &invalid})
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.StatusCode)
+}
+
+// listTitles returns the titles of the tasks listed at url, in order.
+func (suite *HandlerTestSuite) listTitles(url string) []string {
+	resp, body := suite.makeRequest("GET", url, nil)
+	suite.Require().Equal(http.StatusOK, resp.StatusCode, string(body))
+
+	var tasksResp models.TasksResponse
+	suite.Require().NoError(json.Unmarshal(body, &tasksResp))
+
+	titles := make([]string, 0, len(tasksResp.Tasks))
+	for _, task := range tasksResp.Tasks {
+		titles = append(titles, task.Title)
+	}
+	return titles
+}
+
+func (suite *HandlerTestSuite) TestGetAllTasks_SortByMultipleFields() {
+	soon := time.Now().Add(24 * time.Hour)
+	later := time.Now().Add(48 * time.Hour)
+	suite.createTestTask("b-pending-soon", "Description", models.TaskStatusPending, &soon)
+	suite.createTestTask("a-done-soon", "Description", models.TaskStatusCompleted, &soon)
+	suite.createTestTask("c-pending-later", "Description", models.TaskStatusPending, &later)
+	suite.createTestTask("d-pending-undated", "Description", models.TaskStatusPending, nil)
+
+	assert.Equal(suite.T(),
+		[]string{"a-done-soon", "b-pending-soon", "c-pending-later", "d-pending-undated"},
+		suite.listTitles("/tasks?sort=title"))
+	assert.Equal(suite.T(),
+		[]string{"d-pending-undated", "c-pending-later", "b-pending-soon", "a-done-soon"},
+		suite.listTitles("/tasks?sort=-title"))
+	assert.Equal(suite.T(),
+		[]string{"a-done-soon", "b-pending-soon", "c-pending-later", "d-pending-undated"},
+		suite.listTitles("/tasks?sort=status,due_date"))
+	assert.Equal(suite.T(),
+		[]string{"d-pending-undated", "c-pending-later", "b-pending-soon", "a-done-soon"},
+		suite.listTitles("/tasks?sort=-status,-due_date"))
+	assert.Equal(suite.T(),
+		[]string{"d-pending-undated", "c-pending-later", "a-done-soon", "b-pending-soon"},
+		suite.listTitles("/tasks?sort=-created_at,title"))
+}
+
+func (suite *HandlerTestSuite) TestGetAllTasks_SortTieBreaksOnID() {
+	// Equal due dates must not make pages overlap or skip tasks
+	dueDate := time.Now().Add(24 * time.Hour)
+	for i := 1; i <= 5; i++ {
+		suite.createTestTask(fmt.Sprintf("task-%d", i), "Description", models.TaskStatusPending, &dueDate)
+	}
+
+	var titles []string
+	for page := 1; page <= 3; page++ {
+		titles = append(titles, suite.listTitles(fmt.Sprintf("/tasks?sort=due_date&page=%d&size=2", page))...)
+	}
+	assert.Equal(suite.T(), []string{"task-1", "task-2", "task-3", "task-4", "task-5"}, titles)
+}
+
+func (suite *HandlerTestSuite) TestGetAllTasks_InvalidSort() {
+	for _, sort := range []string{"color", "-id", "title,title", "title,", "due_date;DROP"} {
+		resp, body := suite.makeRequest("GET", "/tasks?sort="+url.QueryEscape(sort), nil)
+		assert.Equal(suite.T(), http.StatusBadRequest, resp.StatusCode, sort)
+		assert.Contains(suite.T(), string(body), "invalid sort", sort)
+	}
+}
+
 func (suite *HandlerTestSuite) TestGetAllTasks_WithSearchFilter() {
 	futureDate := time.Now().Add(24 * time.Hour)
 	suite.createTestTask("important-task", "Important description", models.TaskStatusPending, &futureDate)
@@ -438,11 +687,29 @@ func (suite *HandlerTestSuite) TestGetAllTasks_WithSearchFilter() {
 	err := json.Unmarshal(body, &tasksResp)
 	suite.Require().NoError(err)
 
-	assert.Equal(suite.T(), int64(1), tasksResp.Total)
+	assert.Equal(suite.T(), int64(1), *tasksResp.Total)
 	assert.Len(suite.T(), tasksResp.Tasks, 1)
 	assert.Equal(suite.T(), "important-task", tasksResp.Tasks[0].Title)
 }
 
+func (suite *HandlerTestSuite) TestGetAllTasks_WithTitleLookup() {
+	futureDate := time.Now().Add(24 * time.Hour)
+	task := suite.createTestTask("look-me-up", "Description", models.TaskStatusPending, &futureDate)
+	suite.createTestTask("look-me-up-too", "Description", models.TaskStatusPending, &futureDate)
+
+	resp, body := suite.makeRequest("GET", "/tasks?title=look-me-up", nil)
+
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+
+	var tasksResp models.TasksResponse
+	err := json.Unmarshal(body, &tasksResp)
+	suite.Require().NoError(err)
+
+	// Title lookups are exact, unlike search
+	suite.Require().Len(tasksResp.Tasks, 1)
+	assert.Equal(suite.T(), task.ID, tasksResp.Tasks[0].ID)
+}
+
 func (suite *HandlerTestSuite) TestGetAllTasks_WithPagination() {
 	futureDate := time.Now().Add(24 * time.Hour)
 	// Create 15 tasks
@@ -459,12 +726,147 @@ func (suite *HandlerTestSuite) TestGetAllTasks_WithPagination() {
 	err := json.Unmarshal(body, &tasksResp)
 	suite.Require().NoError(err)
 
-	assert.Equal(suite.T(), int64(15), tasksResp.Total)
+	assert.Equal(suite.T(), int64(15), *tasksResp.Total)
 	assert.Equal(suite.T(), 1, tasksResp.Page)
 	assert.Equal(suite.T(), 5, tasksResp.Size)
 	assert.Len(suite.T(), tasksResp.Tasks, 5)
 }
 
+func (suite *HandlerTestSuite) TestGetAllTasks_PaginationLimits() {
+	suite.createTaggedTask("task")
+
+	// Sizes and pages are bounded rather than allocated or multiplied as asked
+	page := suite.listPage("/tasks?size=9223372036854775807")
+	assert.Equal(suite.T(), 100, page.Size)
+	assert.Len(suite.T(), page.Tasks, 1)
+
+	page = suite.listPage("/tasks?page=1000000000000000000&size=10")
+	assert.Equal(suite.T(), 100000, page.Page)
+	assert.Empty(suite.T(), page.Tasks)
+	assert.Equal(suite.T(), int64(1), *page.Total)
+}
+
+// listPage fetches url and decodes the listing.
+func (suite *HandlerTestSuite) listPage(url string) models.TasksResponse {
+	resp, body := suite.makeRequest("GET", url, nil)
+	suite.Require().Equal(http.StatusOK, resp.StatusCode, string(body))
+
+	var tasksResp models.TasksResponse
+	suite.Require().NoError(json.Unmarshal(body, &tasksResp))
+	return tasksResp
+}
+
+func (suite *HandlerTestSuite) TestGetAllTasks_CursorPagination() {
+	soon := time.Now().Add(24 * time.Hour)
+	later := time.Now().Add(48 * time.Hour)
+	dueDates := []*time.Time{&later, nil, &soon, &later, nil, &soon, &soon}
+	for i, dueDate := range dueDates {
+		task := suite.createTestTask(fmt.Sprintf("task-%d", i), "Description", models.TaskStatusPending, dueDate)
+		if i%3 == 0 {
+			suite.setPriority(&task, models.TaskPriorityUrgent)
+		}
+	}
+
+	for _, sort := range []string{"", "due_date", "-due_date", "priority,-title", "-priority", "status,-created_at"} {
+		suite.Run("sort="+sort, func() {
+			expected := suite.listTitles("/tasks?size=100&sort=" + sort)
+			suite.Require().Len(expected, len(dueDates))
+
+			// Walk forward with next_cursor
+			var forward []string
+			page := suite.listPage("/tasks?size=3&sort=" + sort)
+			assert.Empty(suite.T(), page.PrevCursor)
+			for {
+				for _, task := range page.Tasks {
+					forward = append(forward, task.Title)
+				}
+				if page.NextCursor == "" {
+					break
+				}
+				page = suite.listPage("/tasks?size=3&sort=" + sort + "&after=" + page.NextCursor)
+				assert.Zero(suite.T(), page.Page)
+				suite.Require().NotEmpty(page.PrevCursor)
+			}
+			assert.Equal(suite.T(), expected, forward)
+
+			// And back again with prev_cursor from the last page, which
+			// holds only the final task
+			var backward []string
+			for page.PrevCursor != "" {
+				page = suite.listPage("/tasks?size=3&sort=" + sort + "&before=" + page.PrevCursor)
+				titles := make([]string, 0, len(page.Tasks))
+				for _, task := range page.Tasks {
+					titles = append(titles, task.Title)
+				}
+				backward = append(titles, backward...)
+			}
+			assert.Equal(suite.T(), expected[:len(expected)-1], backward)
+		})
+	}
+}
+
+func (suite *HandlerTestSuite) TestGetAllTasks_CursorIsStableAcrossInserts() {
+	dueDate := time.Now().Add(24 * time.Hour)
+	for i := 1; i <= 4; i++ {
+		suite.createTestTask(fmt.Sprintf("task-%d", i), "Description", models.TaskStatusPending, &dueDate)
+	}
+
+	first := suite.listPage("/tasks?size=2&sort=-created_at")
+	suite.Require().NotEmpty(first.NextCursor)
+
+	// A new task sorts first and would shift an offset-based second page
+	suite.createTestTask("task-5", "Description", models.TaskStatusPending, &dueDate)
+
+	second := suite.listPage("/tasks?size=2&sort=-created_at&after=" + first.NextCursor)
+	titles := []string{second.Tasks[0].Title, second.Tasks[1].Title}
+	assert.Equal(suite.T(), []string{"task-2", "task-1"}, titles)
+	assert.Empty(suite.T(), second.NextCursor)
+}
+
+func (suite *HandlerTestSuite) TestGetAllTasks_SkipCount() {
+	futureDate := time.Now().Add(24 * time.Hour)
+	suite.createTestTask("counted-task", "Description", models.TaskStatusPending, &futureDate)
+
+	resp, body := suite.makeRequest("GET", "/tasks?count=false", nil)
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+	assert.NotContains(suite.T(), string(body), `"total"`)
+
+	page := suite.listPage("/tasks")
+	suite.Require().NotNil(page.Total)
+	assert.Equal(suite.T(), int64(1), *page.Total)
+}
+
+func (suite *HandlerTestSuite) TestGetAllTasks_InvalidCursor() {
+	futureDate := time.Now().Add(24 * time.Hour)
+	suite.createTestTask("task-1", "Description", models.TaskStatusPending, &futureDate)
+	suite.createTestTask("task-2", "Description", models.TaskStatusPending, &futureDate)
+	cursor := suite.listPage("/tasks?size=1&sort=title").NextCursor
+	suite.Require().NotEmpty(cursor)
+
+	for _, url := range []string{
+		"/tasks?after=not-a-cursor",
+		"/tasks?before=" + base64.RawURLEncoding.EncodeToString([]byte(`{"id":0}`)),
+		"/tasks?sort=-title&after=" + cursor, // created for another order
+		"/tasks?sort=title&after=" + cursor + "&before=" + cursor,
+	} {
+		resp, _ := suite.makeRequest("GET", url, nil)
+		assert.Equal(suite.T(), http.StatusBadRequest, resp.StatusCode, url)
+	}
+}
+
+func (suite *HandlerTestSuite) TestGetAllTasks_PagePastTheEnd() {
+	if suite.driver != database.DriverMemory {
+		suite.T().Skip("only the memory store slices pages itself")
+	}
+	suite.createTaggedTask("task")
+
+	// The offset of this page overflows an int
+	page, err := suite.store.Tasks.List(repository.TaskFilter{WorkspaceID: suite.workspace.ID, Page: math.MaxInt / 5, Size: 10})
+	suite.Require().NoError(err)
+	assert.Empty(suite.T(), page.Tasks)
+	assert.False(suite.T(), page.HasNext)
+}
+
 func (suite *HandlerTestSuite) TestGetAllTasks_InvalidPaginationParams() {
 	futureDate := time.Now().Add(24 * time.Hour)
 	suite.createTestTask("test-task", "Description", models.TaskStatusPending, &futureDate)
@@ -493,7 +895,7 @@ func (suite *HandlerTestSuite) TestGetAllTasks_InvalidPaginationParams() {
 			suite.Require().NoError(err)
 
 			// Should return default values (page=1, size=10) for invalid inputs
-			assert.Equal(suite.T(), int64(1), tasksResp.Total)
+			assert.Equal(suite.T(), int64(1), *tasksResp.Total)
 		})
 	}
 }
@@ -509,16 +911,18 @@ func (suite *HandlerTestSuite) TestUpdateTask_Success() {
 	newTitle := "updated-title"
 	newDescription := "Updated description"
 	newStatus := models.TaskStatusInProgress
+	newPriority := models.TaskPriorityUrgent
 	newDueDate := time.Now().Add(48 * time.Hour)
 
 	updateReq := models.UpdateTaskRequest{
 		Title:       &newTitle,
 		Description: &newDescription,
 		Status:      &newStatus,
+		Priority:    &newPriority,
 		DueDate:     &newDueDate,
 	}
 
-	resp, body := suite.makeRequest("PUT", "/tasks/original-title", updateReq)
+	resp, body := suite.makeRequest("PUT", fmt.Sprintf("/tasks/%d", task.ID), updateReq)
 
 	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
 
@@ -530,6 +934,7 @@ func (suite *HandlerTestSuite) TestUpdateTask_Success() {
 	assert.Equal(suite.T(), "updated-title", updatedTask.Title)
 	assert.Equal(suite.T(), "Updated description", updatedTask.Description)
 	assert.Equal(suite.T(), models.TaskStatusInProgress, updatedTask.Status)
+	assert.Equal(suite.T(), models.TaskPriorityUrgent, updatedTask.Priority)
 	assert.NotNil(suite.T(), updatedTask.DueDate)
 	assert.True(suite.T(), updatedTask.UpdatedAt.After(task.UpdatedAt))
 }
@@ -543,7 +948,7 @@ func (suite *HandlerTestSuite) TestUpdateTask_PartialUpdate() {
 		Status: &newStatus,
 	}
 
-	resp, body := suite.makeRequest("PUT", "/tasks/partial-update-task", updateReq)
+	resp, body := suite.makeRequest("PUT", fmt.Sprintf("/tasks/%d", task.ID), updateReq)
 
 	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
 
@@ -555,17 +960,35 @@ func (suite *HandlerTestSuite) TestUpdateTask_PartialUpdate() {
 	assert.Equal(suite.T(), task.Title, updatedTask.Title)                             // Unchanged
 	assert.Equal(suite.T(), task.Description, updatedTask.Description)                 // Unchanged
 	assert.Equal(suite.T(), models.TaskStatusCompleted, updatedTask.Status)            // Changed
+	assert.Equal(suite.T(), task.Priority, updatedTask.Priority)                       // Unchanged
 	assert.WithinDuration(suite.T(), *task.DueDate, *updatedTask.DueDate, time.Second) // Unchanged
 	assert.True(suite.T(), updatedTask.UpdatedAt.After(task.UpdatedAt))                // Should be updated
 }
 
+func (suite *HandlerTestSuite) TestUpdateTask_RenameKeepsURL() {
+	futureDate := time.Now().Add(24 * time.Hour)
+	task := suite.createTestTask("before-rename", "Description", models.TaskStatusPending, &futureDate)
+
+	newTitle := "after-rename"
+	resp, _ := suite.makeRequest("PUT", fmt.Sprintf("/tasks/%d", task.ID), models.UpdateTaskRequest{Title: &newTitle})
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+
+	resp, body := suite.makeRequest("GET", fmt.Sprintf("/tasks/%d", task.ID), nil)
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+
+	var renamed models.Task
+	err := json.Unmarshal(body, &renamed)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), "after-rename", renamed.Title)
+}
+
 func (suite *HandlerTestSuite) TestUpdateTask_NotFound() {
 	newTitle := "new-title"
 	updateReq := models.UpdateTaskRequest{
 		Title: &newTitle,
 	}
 
-	resp, body := suite.makeRequest("PUT", "/tasks/non-existent-task", updateReq)
+	resp, body := suite.makeRequest("PUT", "/tasks/9999", updateReq)
 
 	assert.Equal(suite.T(), http.StatusNotFound, resp.StatusCode)
 
@@ -578,7 +1001,7 @@ func (suite *HandlerTestSuite) TestUpdateTask_NotFound() {
 func (suite *HandlerTestSuite) TestUpdateTask_DuplicateTitle() {
 	futureDate := time.Now().Add(24 * time.Hour)
 	suite.createTestTask("existing-title", "Description 1", models.TaskStatusPending, &futureDate)
-	suite.createTestTask("task-to-update", "Description 2", models.TaskStatusPending, &futureDate)
+	task := suite.createTestTask("task-to-update", "Description 2", models.TaskStatusPending, &futureDate)
 
 	// Try to update second task's title to match the first
 	existingTitle := "existing-title"
@@ -586,7 +1009,7 @@ func (suite *HandlerTestSuite) TestUpdateTask_DuplicateTitle() {
 		Title: &existingTitle,
 	}
 
-	resp, body := suite.makeRequest("PUT", "/tasks/task-to-update", updateReq)
+	resp, body := suite.makeRequest("PUT", fmt.Sprintf("/tasks/%d", task.ID), updateReq)
 
 	assert.Equal(suite.T(), http.StatusConflict, resp.StatusCode)
 
@@ -598,7 +1021,7 @@ func (suite *HandlerTestSuite) TestUpdateTask_DuplicateTitle() {
 
 func (suite *HandlerTestSuite) TestUpdateTask_SameTitleUpdate() {
 	futureDate := time.Now().Add(24 * time.Hour)
-	suite.createTestTask("same-title-task", "Original description", models.TaskStatusPending, &futureDate)
+	task := suite.createTestTask("same-title-task", "Original description", models.TaskStatusPending, &futureDate)
 
 	// Update with same title (should be allowed)
 	sameTitle := "same-title-task"
@@ -608,7 +1031,7 @@ func (suite *HandlerTestSuite) TestUpdateTask_SameTitleUpdate() {
 		Description: &newDescription,
 	}
 
-	resp, body := suite.makeRequest("PUT", "/tasks/same-title-task", updateReq)
+	resp, body := suite.makeRequest("PUT", fmt.Sprintf("/tasks/%d", task.ID), updateReq)
 
 	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
 
@@ -627,9 +1050,9 @@ func (suite *HandlerTestSuite) TestUpdateTask_SameTitleUpdate() {
 
 func (suite *HandlerTestSuite) TestDeleteTask_Success() {
 	futureDate := time.Now().Add(24 * time.Hour)
-	suite.createTestTask("task-to-delete", "Description", models.TaskStatusPending, &futureDate)
+	task := suite.createTestTask("task-to-delete", "Description", models.TaskStatusPending, &futureDate)
 
-	resp, body := suite.makeRequest("DELETE", "/tasks/task-to-delete", nil)
+	resp, body := suite.makeRequest("DELETE", fmt.Sprintf("/tasks/%d", task.ID), nil)
 
 	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
 
@@ -639,13 +1062,12 @@ func (suite *HandlerTestSuite) TestDeleteTask_Success() {
 	assert.Equal(suite.T(), "Task deleted successfully", response["message"])
 
 	// Verify task is actually deleted
-	var count int64
-	suite.db.Model(&models.Task{}).Where("title = ?", "task-to-delete").Count(&count)
-	assert.Equal(suite.T(), int64(0), count)
+	_, err = suite.store.Tasks.GetByTitle(suite.workspace.ID, "task-to-delete")
+	assert.ErrorIs(suite.T(), err, repository.ErrNotFound)
 }
 
 func (suite *HandlerTestSuite) TestDeleteTask_NotFound() {
-	resp, body := suite.makeRequest("DELETE", "/tasks/non-existent-task", nil)
+	resp, body := suite.makeRequest("DELETE", "/tasks/9999", nil)
 
 	assert.Equal(suite.T(), http.StatusNotFound, resp.StatusCode)
 
@@ -686,7 +1108,7 @@ func (suite *HandlerTestSuite) TestUpdateTask_EmptyUpdate() {
 	// Send empty update request
 	updateReq := models.UpdateTaskRequest{}
 
-	resp, body := suite.makeRequest("PUT", "/tasks/empty-update-task", updateReq)
+	resp, body := suite.makeRequest("PUT", fmt.Sprintf("/tasks/%d", originalTask.ID), updateReq)
 
 	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
 
@@ -706,10 +1128,18 @@ func (suite *HandlerTestSuite) TestUpdateTask_EmptyUpdate() {
 // ============================================================================
 
 func TestHandlerTestSuite(t *testing.T) {
-	// Skip database tests in CI if no database is available
-	if os.Getenv("SKIP_DB_TESTS") == "true" {
-		t.Skip("Skipping database tests")
-	}
+	t.Run(database.DriverMemory, func(t *testing.T) {
+		suite.Run(t, &HandlerTestSuite{driver: database.DriverMemory})
+	})
 
-	suite.Run(t, new(HandlerTestSuite))
+	t.Run(database.DriverSQLite, func(t *testing.T) {
+		suite.Run(t, &HandlerTestSuite{driver: database.DriverSQLite})
+	})
+
+	// PostgreSQL needs a running server, so it only runs when configured
+	if os.Getenv("TEST_DATABASE_DSN") != "" {
+		t.Run(database.DriverPostgres, func(t *testing.T) {
+			suite.Run(t, &HandlerTestSuite{driver: database.DriverPostgres})
+		})
+	}
 }
@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"task/backend/auth"
+	"task/backend/events"
+	"task/backend/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// ============================================================================
+// TRASH TESTS
+// ============================================================================
+
+func (suite *HandlerTestSuite) deleteTask(task models.Task, query string) {
+	resp, body := suite.makeRequest("DELETE", fmt.Sprintf("/tasks/%d%s", task.ID, query), nil)
+	suite.Require().Equal(http.StatusOK, resp.StatusCode, string(body))
+}
+
+func (suite *HandlerTestSuite) restoreTask(task models.Task, body interface{}) (*http.Response, []byte) {
+	return suite.makeRequest("POST", fmt.Sprintf("/tasks/%d/restore", task.ID), body)
+}
+
+func (suite *HandlerTestSuite) TestTrash_DeleteAndRestore() {
+	task := suite.createTaggedTask("write-docs", "docs")
+	suite.deleteTask(task, "")
+
+	resp, _ := suite.getTask(task.ID)
+	assert.Equal(suite.T(), http.StatusNotFound, resp.StatusCode)
+	assert.Empty(suite.T(), suite.listTitles("/tasks"))
+
+	trash := suite.listPage("/trash")
+	suite.Require().Len(trash.Tasks, 1)
+	assert.Equal(suite.T(), int64(1), *trash.Total)
+	assert.Equal(suite.T(), "write-docs", trash.Tasks[0].Title)
+	assert.True(suite.T(), trash.Tasks[0].DeletedAt.Valid)
+
+	resp, body := suite.restoreTask(task, nil)
+	suite.Require().Equal(http.StatusOK, resp.StatusCode, string(body))
+	var restored models.Task
+	suite.Require().NoError(json.Unmarshal(body, &restored))
+	assert.False(suite.T(), restored.DeletedAt.Valid)
+	assert.Equal(suite.T(), []string{"docs"}, tagNames(restored.Tags), "tags survive the trash")
+
+	assert.Equal(suite.T(), []string{"write-docs"}, suite.listTitles("/tasks"))
+	assert.Empty(suite.T(), suite.listPage("/trash").Tasks)
+
+	// Only tasks in the trash can be restored
+	resp, _ = suite.restoreTask(task, nil)
+	assert.Equal(suite.T(), http.StatusNotFound, resp.StatusCode)
+}
+
+func (suite *HandlerTestSuite) TestTrash_PaginationLimits() {
+	task := suite.createTaggedTask("task")
+	suite.deleteTask(task, "")
+
+	trash := suite.listPage("/trash?size=9223372036854775807")
+	assert.Equal(suite.T(), 100, trash.Size)
+	assert.Len(suite.T(), trash.Tasks, 1)
+
+	trash = suite.listPage("/trash?page=1000000000000000000")
+	assert.Empty(suite.T(), trash.Tasks)
+}
+
+func (suite *HandlerTestSuite) TestTrash_TitleReuse() {
+	task := suite.createTaggedTask("report")
+	suite.deleteTask(task, "")
+
+	// The title is free while the task is in the trash
+	suite.createTaggedTask("report")
+
+	resp, body := suite.restoreTask(task, nil)
+	assert.Equal(suite.T(), http.StatusConflict, resp.StatusCode, string(body))
+
+	title := "old-report"
+	resp, body = suite.restoreTask(task, models.RestoreTaskRequest{Title: &title})
+	suite.Require().Equal(http.StatusOK, resp.StatusCode, string(body))
+	assert.ElementsMatch(suite.T(), []string{"report", "old-report"}, suite.listTitles("/tasks"))
+}
+
+func (suite *HandlerTestSuite) TestTrash_Subtasks() {
+	parent := suite.createTaggedTask("launch")
+	child := suite.createSubtask("press-release", parent.ID)
+	suite.createSubtask("tweet", child.ID)
+
+	suite.deleteTask(parent, "?children=cascade")
+	assert.Len(suite.T(), suite.listPage("/trash").Tasks, 3)
+
+	// A subtask can't come back before its parent
+	resp, _ := suite.restoreTask(child, nil)
+	assert.Equal(suite.T(), http.StatusConflict, resp.StatusCode)
+
+	// Restoring the parent brings the whole tree back
+	resp, body := suite.restoreTask(parent, nil)
+	suite.Require().Equal(http.StatusOK, resp.StatusCode, string(body))
+	resp, body = suite.makeRequest("GET", fmt.Sprintf("/tasks/%d/tree", parent.ID), nil)
+	suite.Require().Equal(http.StatusOK, resp.StatusCode)
+	var tree models.TaskNode
+	suite.Require().NoError(json.Unmarshal(body, &tree))
+	suite.Require().Len(tree.Children, 1)
+	assert.Equal(suite.T(), []string{"tweet"}, taskTitles([]models.Task{tree.Children[0].Children[0].Task}))
+
+	// Orphaned subtasks stay behind when their parent goes to the trash
+	suite.deleteTask(parent, "?children=orphan")
+	_, fetched := suite.getTask(child.ID)
+	assert.Nil(suite.T(), fetched.ParentID)
+}
+
+func (suite *HandlerTestSuite) TestTrash_RestoreOnlyWhatWasDeletedTogether() {
+	parent := suite.createTaggedTask("launch")
+	suite.createSubtask("press-release", parent.ID)
+	dropped := suite.createSubtask("tweet", parent.ID)
+	suite.createSubtask("tweet-draft", dropped.ID)
+
+	// A subtask trashed on its own stays in the trash when its parent comes back
+	suite.deleteTask(dropped, "?children=cascade")
+	suite.deleteTask(parent, "?children=cascade")
+	resp, body := suite.restoreTask(parent, nil)
+	suite.Require().Equal(http.StatusOK, resp.StatusCode, string(body))
+	assert.Equal(suite.T(), []string{"launch", "press-release"}, suite.listTitles("/tasks?sort=title"))
+	assert.Equal(suite.T(), []string{"tweet", "tweet-draft"}, suite.listTitles("/trash"))
+
+	resp, body = suite.restoreTask(dropped, nil)
+	suite.Require().Equal(http.StatusOK, resp.StatusCode, string(body))
+	assert.Empty(suite.T(), suite.listPage("/trash").Tasks)
+	_, fetched := suite.getTask(dropped.ID)
+	suite.Require().NotNil(fetched.ParentID)
+	assert.Equal(suite.T(), parent.ID, *fetched.ParentID)
+}
+
+func (suite *HandlerTestSuite) TestTrash_Purge() {
+	parent := suite.createTaggedTask("launch")
+	child := suite.createSubtask("press-release", parent.ID)
+	live := suite.createTaggedTask("live")
+
+	// Tasks outside the trash can't be purged
+	resp, _ := suite.makeRequest("DELETE", fmt.Sprintf("/trash/%d", live.ID), nil)
+	assert.Equal(suite.T(), http.StatusNotFound, resp.StatusCode)
+
+	suite.deleteTask(parent, "?children=cascade")
+	resp, body := suite.makeRequest("DELETE", fmt.Sprintf("/trash/%d", parent.ID), nil)
+	suite.Require().Equal(http.StatusOK, resp.StatusCode, string(body))
+
+	assert.Empty(suite.T(), suite.listPage("/trash").Tasks)
+	resp, _ = suite.restoreTask(child, nil)
+	assert.Equal(suite.T(), http.StatusNotFound, resp.StatusCode)
+	assert.Equal(suite.T(), []string{"live"}, suite.listTitles("/tasks"))
+}
+
+func (suite *HandlerTestSuite) TestTrash_PurgeRecordsEveryTask() {
+	parent := suite.createTaggedTask("launch")
+	child := suite.createSubtask("press-release", parent.ID)
+	suite.deleteTask(parent, "?children=cascade")
+	suite.relayedTypes()
+
+	resp, body := suite.makeRequest("DELETE", fmt.Sprintf("/trash/%d", parent.ID), nil)
+	suite.Require().Equal(http.StatusOK, resp.StatusCode, string(body))
+	assert.Equal(suite.T(), []events.Type{events.TypeTaskPurged, events.TypeTaskPurged}, suite.relayedTypes())
+
+	for _, task := range []models.Task{parent, child} {
+		entries, _, err := suite.store.Audit.ListForTask(task.ID, 1, 1)
+		suite.Require().NoError(err)
+		suite.Require().Len(entries, 1, task.Title)
+		assert.Equal(suite.T(), models.AuditPurged, entries[0].Action, task.Title)
+		assert.Equal(suite.T(), suite.user.ID, entries[0].ActorID, task.Title)
+	}
+}
+
+func (suite *HandlerTestSuite) TestTrash_PurgeExpired() {
+	old := suite.createTaggedTask("old")
+	suite.deleteTask(old, "")
+	suite.relayedTypes()
+
+	purged, err := suite.tasks.PurgeExpired(time.Now().Add(-time.Hour))
+	suite.Require().NoError(err)
+	assert.Zero(suite.T(), purged)
+
+	purged, err = suite.tasks.PurgeExpired(time.Now().Add(time.Minute))
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), 1, purged)
+	assert.Empty(suite.T(), suite.listPage("/trash").Tasks)
+	assert.Equal(suite.T(), []events.Type{events.TypeTaskPurged}, suite.relayedTypes())
+
+	entries, _, err := suite.store.Audit.ListForTask(old.ID, 1, 1)
+	suite.Require().NoError(err)
+	suite.Require().Len(entries, 1)
+	assert.Equal(suite.T(), models.AuditPurged, entries[0].Action)
+	assert.Equal(suite.T(), suite.workspace.ID, entries[0].WorkspaceID)
+	assert.Zero(suite.T(), entries[0].ActorID, "the server purges on its own")
+}
+
+func (suite *HandlerTestSuite) TestTrash_DependenciesAreRemoved() {
+	blocker := suite.createTaggedTask("blocker")
+	task := suite.createTaggedTask("task")
+	resp, _ := suite.addDependency(task, blocker)
+	suite.Require().Equal(http.StatusCreated, resp.StatusCode)
+
+	suite.deleteTask(blocker, "")
+	_, fetched := suite.getTask(task.ID)
+	assert.False(suite.T(), fetched.Blocked)
+
+	resp, _ = suite.restoreTask(blocker, nil)
+	suite.Require().Equal(http.StatusOK, resp.StatusCode)
+	_, fetched = suite.getTask(task.ID)
+	assert.False(suite.T(), fetched.Blocked, "dependencies don't come back")
+}
+
+func (suite *HandlerTestSuite) TestTrash_Permissions() {
+	team := suite.createWorkspace("Team")
+	resp, body := suite.makeRequestWithHeaders("POST", "/tasks", newTaskRequest("shared"), suite.token, inWorkspace(team.ID))
+	suite.Require().Equal(http.StatusCreated, resp.StatusCode, string(body))
+	var task models.Task
+	suite.Require().NoError(json.Unmarshal(body, &task))
+
+	_, adminToken := suite.joinWorkspace(team, "admin@example.com", models.RoleAdmin)
+	resp, _ = suite.makeRequestWithHeaders("DELETE", fmt.Sprintf("/tasks/%d", task.ID), nil, adminToken, inWorkspace(team.ID))
+	suite.Require().Equal(http.StatusOK, resp.StatusCode)
+
+	// Trashed tasks of other workspaces stay hidden
+	resp, _ = suite.restoreTask(task, nil)
+	assert.Equal(suite.T(), http.StatusNotFound, resp.StatusCode)
+
+	// Admins may delete tasks, but only owners purge them for good
+	resp, body = suite.makeRequestWithHeaders("DELETE", fmt.Sprintf("/trash/%d", task.ID), nil, adminToken, inWorkspace(team.ID))
+	suite.assertForbidden(resp, body, auth.ActionPurgeTask, models.RoleOwner)
+
+	resp, _ = suite.makeRequestWithHeaders("POST", fmt.Sprintf("/tasks/%d/restore", task.ID), nil, adminToken, inWorkspace(team.ID))
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+}
@@ -0,0 +1,250 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"task/backend/auth"
+	"task/backend/models"
+	"task/backend/webhook"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// ============================================================================
+// WEBHOOK TESTS
+// ============================================================================
+
+const webhookSecret = "a-long-enough-secret"
+
+// webhookReceiver collects the payloads posted to it after checking their
+// signature.
+type webhookReceiver struct {
+	mu       sync.Mutex
+	status   int
+	payloads []models.WebhookPayload
+}
+
+func (suite *HandlerTestSuite) startReceiver(status int) (*webhookReceiver, string) {
+	recv := &webhookReceiver{status: status}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !webhook.Verify(webhookSecret, body, r.Header.Get(webhook.SignatureHeader)) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var payload models.WebhookPayload
+		if err := json.Unmarshal(body, &payload); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		recv.mu.Lock()
+		defer recv.mu.Unlock()
+		recv.payloads = append(recv.payloads, payload)
+		w.WriteHeader(recv.status)
+	}))
+	suite.T().Cleanup(server.Close)
+	return recv, server.URL
+}
+
+func (recv *webhookReceiver) events() []models.WebhookEvent {
+	recv.mu.Lock()
+	defer recv.mu.Unlock()
+	events := make([]models.WebhookEvent, len(recv.payloads))
+	for i, payload := range recv.payloads {
+		events[i] = payload.Event
+	}
+	return events
+}
+
+func (suite *HandlerTestSuite) createWebhook(url string, events ...models.WebhookEvent) models.Webhook {
+	resp, body := suite.makeRequest("POST", "/webhooks", models.CreateWebhookRequest{URL: url, Events: events, Secret: webhookSecret})
+	suite.Require().Equal(http.StatusCreated, resp.StatusCode, string(body))
+
+	var hook models.Webhook
+	suite.Require().NoError(json.Unmarshal(body, &hook))
+	return hook
+}
+
+// deliverWebhooks sends every delivery that is due by now.
+func (suite *HandlerTestSuite) deliverWebhooks() {
+	dispatcher := webhook.NewDispatcher(suite.store.Webhooks)
+	dispatcher.Client = webhook.NewClient(true)
+	_, err := dispatcher.DeliverDue(time.Now())
+	suite.Require().NoError(err)
+}
+
+func (suite *HandlerTestSuite) deliveries(hook models.Webhook, query string) models.WebhookDeliveriesResponse {
+	resp, body := suite.makeRequest("GET", fmt.Sprintf("/webhooks/%d/deliveries%s", hook.ID, query), nil)
+	suite.Require().Equal(http.StatusOK, resp.StatusCode, string(body))
+
+	var page models.WebhookDeliveriesResponse
+	suite.Require().NoError(json.Unmarshal(body, &page))
+	return page
+}
+
+func (suite *HandlerTestSuite) TestWebhooks_CreateUpdateDelete() {
+	hook := suite.createWebhook("https://example.com/hooks", models.EventTaskCreated)
+	assert.Equal(suite.T(), suite.workspace.ID, hook.WorkspaceID)
+	assert.Equal(suite.T(), []models.WebhookEvent{models.EventTaskCreated}, hook.Events)
+
+	resp, body := suite.makeRequest("GET", "/webhooks", nil)
+	suite.Require().Equal(http.StatusOK, resp.StatusCode)
+	assert.NotContains(suite.T(), string(body), webhookSecret, "secrets are never returned")
+	var hooks []models.Webhook
+	suite.Require().NoError(json.Unmarshal(body, &hooks))
+	suite.Require().Len(hooks, 1)
+
+	url := "https://example.com/other"
+	events := []models.WebhookEvent{}
+	resp, body = suite.makeRequest("PUT", fmt.Sprintf("/webhooks/%d", hook.ID), models.UpdateWebhookRequest{URL: &url, Events: &events})
+	suite.Require().Equal(http.StatusOK, resp.StatusCode, string(body))
+	suite.Require().NoError(json.Unmarshal(body, &hook))
+	assert.Equal(suite.T(), url, hook.URL)
+	assert.Empty(suite.T(), hook.Events)
+
+	resp, _ = suite.makeRequest("DELETE", fmt.Sprintf("/webhooks/%d", hook.ID), nil)
+	suite.Require().Equal(http.StatusOK, resp.StatusCode)
+	resp, _ = suite.makeRequest("GET", fmt.Sprintf("/webhooks/%d/deliveries", hook.ID), nil)
+	assert.Equal(suite.T(), http.StatusNotFound, resp.StatusCode)
+}
+
+func (suite *HandlerTestSuite) TestWebhooks_ValidationErrors() {
+	requests := []models.CreateWebhookRequest{
+		{URL: "", Secret: webhookSecret},
+		{URL: "ftp://example.com", Secret: webhookSecret},
+		{URL: "https://example.com", Secret: "short"},
+		{URL: "https://example.com", Secret: webhookSecret, Events: []models.WebhookEvent{"task.renamed"}},
+		{URL: "https://example.com", Secret: webhookSecret, Events: []models.WebhookEvent{models.EventTaskCreated, models.EventTaskCreated}},
+	}
+	for _, request := range requests {
+		resp, body := suite.makeRequest("POST", "/webhooks", request)
+		assert.Equal(suite.T(), http.StatusBadRequest, resp.StatusCode, string(body))
+	}
+}
+
+func (suite *HandlerTestSuite) TestWebhooks_PrivateAddresses() {
+	hook := suite.createWebhook("http://127.0.0.1:8080/hooks")
+	suite.webhooks.AllowPrivate = false
+	defer func() { suite.webhooks.AllowPrivate = true }()
+
+	urls := []string{
+		"http://127.0.0.1:8080/hooks",
+		"http://localhost/hooks",
+		"http://169.254.169.254/latest/meta-data",
+		"http://10.0.0.7/hooks",
+		"http://192.168.1.1/hooks",
+		"http://[::1]/hooks",
+		"http://[::ffff:127.0.0.1]/hooks",
+		"http://0.0.0.0/hooks",
+	}
+	for _, url := range urls {
+		resp, body := suite.makeRequest("POST", "/webhooks", models.CreateWebhookRequest{URL: url, Secret: webhookSecret})
+		assert.Equal(suite.T(), http.StatusBadRequest, resp.StatusCode, url)
+		assert.Contains(suite.T(), string(body), "loopback, private or link-local", url)
+
+		resp, _ = suite.makeRequest("PUT", fmt.Sprintf("/webhooks/%d", hook.ID), models.UpdateWebhookRequest{URL: &url})
+		assert.Equal(suite.T(), http.StatusBadRequest, resp.StatusCode, url)
+	}
+
+	resp, body := suite.makeRequest("POST", "/webhooks", models.CreateWebhookRequest{URL: "http://203.0.113.10/hooks", Secret: webhookSecret})
+	assert.Equal(suite.T(), http.StatusCreated, resp.StatusCode, string(body))
+}
+
+func (suite *HandlerTestSuite) TestWebhooks_LifecycleEvents() {
+	all, allURL := suite.startReceiver(http.StatusOK)
+	completed, completedURL := suite.startReceiver(http.StatusOK)
+	suite.createWebhook(allURL)
+	suite.createWebhook(completedURL, models.EventTaskCompleted)
+
+	task := suite.createTaggedTask("write-docs")
+	priority := models.TaskPriorityHigh
+	resp, _ := suite.makeRequest("PUT", fmt.Sprintf("/tasks/%d", task.ID), models.UpdateTaskRequest{Priority: &priority})
+	suite.Require().Equal(http.StatusOK, resp.StatusCode)
+	suite.completeTask(task)
+	suite.deleteTask(task, "")
+
+	suite.deliverWebhooks()
+	assert.Equal(suite.T(), []models.WebhookEvent{
+		models.EventTaskCreated,
+		models.EventTaskUpdated,
+		models.EventTaskUpdated,
+		models.EventTaskCompleted,
+		models.EventTaskDeleted,
+	}, all.events())
+	assert.Equal(suite.T(), []models.WebhookEvent{models.EventTaskCompleted}, completed.events())
+
+	payload := completed.payloads[0]
+	assert.Equal(suite.T(), suite.workspace.ID, payload.WorkspaceID)
+	assert.Equal(suite.T(), suite.user.ID, payload.ActorID)
+	assert.Equal(suite.T(), task.ID, payload.Task.ID)
+	assert.Equal(suite.T(), models.TaskStatusCompleted, payload.Task.Status)
+	assert.Equal(suite.T(), map[string][2]any{"status": {"pending", "completed"}}, changedFields(models.AuditEntry{Changes: payload.Changes}))
+}
+
+func (suite *HandlerTestSuite) TestWebhooks_DeliveryLog() {
+	recv, url := suite.startReceiver(http.StatusServiceUnavailable)
+	hook := suite.createWebhook(url)
+	suite.createTaggedTask("write-docs")
+
+	page := suite.deliveries(hook, "")
+	suite.Require().Len(page.Deliveries, 1)
+	assert.Equal(suite.T(), models.DeliveryPending, page.Deliveries[0].Status)
+	assert.Zero(suite.T(), page.Deliveries[0].Attempts)
+
+	suite.deliverWebhooks()
+	delivery := suite.deliveries(hook, "").Deliveries[0]
+	assert.Equal(suite.T(), models.DeliveryPending, delivery.Status, "failed deliveries are retried")
+	assert.Equal(suite.T(), 1, delivery.Attempts)
+	assert.Equal(suite.T(), http.StatusServiceUnavailable, delivery.ResponseStatus)
+	assert.True(suite.T(), delivery.NextAttemptAt.After(time.Now()))
+
+	var payload models.WebhookPayload
+	suite.Require().NoError(json.Unmarshal(delivery.Payload, &payload))
+	assert.Equal(suite.T(), models.EventTaskCreated, payload.Event)
+
+	// The retry isn't due yet
+	recv.mu.Lock()
+	recv.status = http.StatusOK
+	recv.mu.Unlock()
+	suite.deliverWebhooks()
+	assert.Len(suite.T(), recv.events(), 1)
+}
+
+func (suite *HandlerTestSuite) TestWebhooks_DeliveryLogPaginationLimits() {
+	hook := suite.createWebhook("https://example.com/hooks")
+	suite.createTaggedTask("write-docs")
+
+	page := suite.deliveries(hook, "?size=9223372036854775807")
+	assert.Equal(suite.T(), 100, page.Size)
+	assert.Len(suite.T(), page.Deliveries, 1)
+
+	page = suite.deliveries(hook, "?page=1000000000000000000")
+	assert.Equal(suite.T(), 100000, page.Page)
+	assert.Empty(suite.T(), page.Deliveries)
+}
+
+func (suite *HandlerTestSuite) TestWebhooks_Permissions() {
+	team := suite.createWorkspace("Team")
+	_, editorToken := suite.joinWorkspace(team, "editor@example.com", models.RoleEditor)
+
+	resp, body := suite.makeRequestWithHeaders("GET", "/webhooks", nil, editorToken, inWorkspace(team.ID))
+	suite.assertForbidden(resp, body, auth.ActionManageWebhooks, models.RoleAdmin)
+
+	// Webhooks of other workspaces stay hidden
+	hook := suite.createWebhook("https://example.com/hooks")
+	_, adminToken := suite.joinWorkspace(team, "admin@example.com", models.RoleAdmin)
+	resp, _ = suite.makeRequestWithHeaders("DELETE", fmt.Sprintf("/webhooks/%d", hook.ID), nil, adminToken, inWorkspace(team.ID))
+	assert.Equal(suite.T(), http.StatusNotFound, resp.StatusCode)
+
+	// Tasks of other workspaces don't reach the webhook
+	resp, _ = suite.makeRequestWithHeaders("POST", "/tasks", newTaskRequest("shared"), adminToken, inWorkspace(team.ID))
+	suite.Require().Equal(http.StatusCreated, resp.StatusCode)
+	assert.Empty(suite.T(), suite.deliveries(hook, "").Deliveries)
+}
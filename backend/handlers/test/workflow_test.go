@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"task/backend/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// ============================================================================
+// WORKFLOW TESTS
+// ============================================================================
+
+// reviewWorkflow requires tasks to pass review before completion.
+func reviewWorkflow() models.UpdateWorkflowRequest {
+	return models.UpdateWorkflowRequest{
+		Initial: models.TaskStatusPending,
+		Statuses: []models.TaskStatus{
+			models.TaskStatusPending, models.TaskStatusInProgress, models.TaskStatusReview,
+			models.TaskStatusCompleted, models.TaskStatusCancelled,
+		},
+		Transitions: map[models.TaskStatus][]models.TaskStatus{
+			models.TaskStatusPending:    {models.TaskStatusInProgress, models.TaskStatusCancelled},
+			models.TaskStatusInProgress: {models.TaskStatusReview, models.TaskStatusPending},
+			models.TaskStatusReview:     {models.TaskStatusCompleted, models.TaskStatusInProgress},
+		},
+	}
+}
+
+func (suite *HandlerTestSuite) setWorkflow(request models.UpdateWorkflowRequest) models.Workflow {
+	resp, body := suite.makeRequest("PUT", "/workflow", request)
+	suite.Require().Equal(http.StatusOK, resp.StatusCode, string(body))
+
+	var workflow models.Workflow
+	suite.Require().NoError(json.Unmarshal(body, &workflow))
+	return workflow
+}
+
+func (suite *HandlerTestSuite) TestWorkflow_Default() {
+	resp, body := suite.makeRequest("GET", "/workflow", nil)
+	suite.Require().Equal(http.StatusOK, resp.StatusCode)
+	var workflow models.Workflow
+	suite.Require().NoError(json.Unmarshal(body, &workflow))
+	assert.True(suite.T(), workflow.Default)
+	assert.Equal(suite.T(), models.TaskStatusPending, workflow.Initial)
+	assert.Equal(suite.T(), []models.TaskStatus{models.TaskStatusPending, models.TaskStatusInProgress, models.TaskStatusCompleted}, workflow.Statuses)
+
+	// Statuses outside the workflow are rejected
+	request := newTaskRequest("odd-status")
+	request.Status = "someday"
+	resp, body = suite.makeRequest("POST", "/tasks", request)
+	assert.Equal(suite.T(), http.StatusUnprocessableEntity, resp.StatusCode)
+	assert.Contains(suite.T(), string(body), `"allowed":["pending","in_progress","completed"]`)
+
+	task := suite.createTaggedTask("task")
+	status := models.TaskStatus("someday")
+	resp, _ = suite.makeRequest("PUT", fmt.Sprintf("/tasks/%d", task.ID), models.UpdateTaskRequest{Status: &status})
+	assert.Equal(suite.T(), http.StatusUnprocessableEntity, resp.StatusCode)
+}
+
+func (suite *HandlerTestSuite) TestWorkflow_EnforcesTransitions() {
+	workflow := suite.setWorkflow(reviewWorkflow())
+	assert.False(suite.T(), workflow.Default)
+
+	task := suite.createTaggedTask("task")
+	assert.Equal(suite.T(), models.TaskStatusPending, task.Status)
+
+	resp, body := suite.setStatus(task, models.TaskStatusCompleted, false)
+	assert.Equal(suite.T(), http.StatusUnprocessableEntity, resp.StatusCode)
+	var rejected struct {
+		From    models.TaskStatus   `json:"from"`
+		Allowed []models.TaskStatus `json:"allowed"`
+	}
+	suite.Require().NoError(json.Unmarshal(body, &rejected))
+	assert.Equal(suite.T(), models.TaskStatusPending, rejected.From)
+	assert.Equal(suite.T(), []models.TaskStatus{models.TaskStatusInProgress, models.TaskStatusCancelled}, rejected.Allowed)
+
+	for _, status := range []models.TaskStatus{models.TaskStatusInProgress, models.TaskStatusReview, models.TaskStatusCompleted} {
+		resp, body = suite.setStatus(task, status, false)
+		suite.Require().Equal(http.StatusOK, resp.StatusCode, string(body))
+	}
+
+	// Completed has no way out in this workflow
+	resp, body = suite.setStatus(task, models.TaskStatusPending, false)
+	assert.Equal(suite.T(), http.StatusUnprocessableEntity, resp.StatusCode)
+	assert.Contains(suite.T(), string(body), `"allowed":[]`)
+
+	// Keeping the status is not a transition
+	resp, _ = suite.setStatus(task, models.TaskStatusCompleted, false)
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+
+	// New tasks may start in any status of the workflow
+	request := newTaskRequest("in-review")
+	request.Status = models.TaskStatusReview
+	resp, _ = suite.makeRequest("POST", "/tasks", request)
+	assert.Equal(suite.T(), http.StatusCreated, resp.StatusCode)
+}
+
+func (suite *HandlerTestSuite) TestWorkflow_InitialStatus() {
+	workflow := reviewWorkflow()
+	workflow.Initial = models.TaskStatusInProgress
+	suite.setWorkflow(workflow)
+
+	request := newTaskRequest("task")
+	request.Status = ""
+	resp, body := suite.makeRequest("POST", "/tasks", request)
+	suite.Require().Equal(http.StatusCreated, resp.StatusCode, string(body))
+	var task models.Task
+	suite.Require().NoError(json.Unmarshal(body, &task))
+	assert.Equal(suite.T(), models.TaskStatusInProgress, task.Status)
+}
+
+func (suite *HandlerTestSuite) TestWorkflow_Validation() {
+	cases := map[string]func(*models.UpdateWorkflowRequest){
+		"unknown initial": func(r *models.UpdateWorkflowRequest) { r.Initial = "draft" },
+		"no completed":    func(r *models.UpdateWorkflowRequest) { r.Statuses = r.Statuses[:3] },
+		"duplicate":       func(r *models.UpdateWorkflowRequest) { r.Statuses = append(r.Statuses, models.TaskStatusReview) },
+		"bad name":        func(r *models.UpdateWorkflowRequest) { r.Statuses = append(r.Statuses, "On Hold") },
+		"unknown target": func(r *models.UpdateWorkflowRequest) {
+			r.Transitions[models.TaskStatusReview] = []models.TaskStatus{"shipped"}
+		},
+	}
+	for name, change := range cases {
+		request := reviewWorkflow()
+		change(&request)
+		resp, _ := suite.makeRequest("PUT", "/workflow", request)
+		assert.Equal(suite.T(), http.StatusBadRequest, resp.StatusCode, name)
+	}
+}
+
+func (suite *HandlerTestSuite) TestWorkflow_StatusesInUse() {
+	suite.setWorkflow(reviewWorkflow())
+	task := suite.createTaggedTask("task")
+	suite.setStatus(task, models.TaskStatusInProgress, false)
+	resp, body := suite.setStatus(task, models.TaskStatusReview, false)
+	suite.Require().Equal(http.StatusOK, resp.StatusCode, string(body))
+
+	// Review can't go away while a task is in it
+	resp, _ = suite.makeRequest("DELETE", "/workflow", nil)
+	assert.Equal(suite.T(), http.StatusConflict, resp.StatusCode)
+
+	suite.setStatus(task, models.TaskStatusCompleted, false)
+	resp, body = suite.makeRequest("DELETE", "/workflow", nil)
+	suite.Require().Equal(http.StatusOK, resp.StatusCode, string(body))
+	var workflow models.Workflow
+	suite.Require().NoError(json.Unmarshal(body, &workflow))
+	assert.True(suite.T(), workflow.Default)
+
+	resp, body = suite.makeRequest("GET", "/workflow", nil)
+	suite.Require().Equal(http.StatusOK, resp.StatusCode)
+	assert.Contains(suite.T(), string(body), `"default":true`)
+}
+
+func (suite *HandlerTestSuite) TestWorkflow_StatusesInTrash() {
+	suite.setWorkflow(reviewWorkflow())
+	task := suite.createTaggedTask("task")
+	suite.setStatus(task, models.TaskStatusInProgress, false)
+	resp, body := suite.setStatus(task, models.TaskStatusReview, false)
+	suite.Require().Equal(http.StatusOK, resp.StatusCode, string(body))
+	suite.deleteTask(task, "")
+
+	// A trashed task could come back with a status the workflow dropped
+	resp, body = suite.makeRequest("DELETE", "/workflow", nil)
+	assert.Equal(suite.T(), http.StatusConflict, resp.StatusCode)
+	assert.Contains(suite.T(), string(body), "Tasks in the trash")
+
+	resp, body = suite.makeRequest("DELETE", fmt.Sprintf("/trash/%d", task.ID), nil)
+	suite.Require().Equal(http.StatusOK, resp.StatusCode, string(body))
+	resp, body = suite.makeRequest("DELETE", "/workflow", nil)
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode, string(body))
+}
+
+func (suite *HandlerTestSuite) TestWorkflow_PerWorkspace() {
+	suite.setWorkflow(reviewWorkflow())
+
+	team := suite.createWorkspace("Team")
+	resp, body := suite.makeRequestWithHeaders("GET", "/workflow", nil, suite.token, inWorkspace(team.ID))
+	suite.Require().Equal(http.StatusOK, resp.StatusCode)
+	assert.Contains(suite.T(), string(body), `"default":true`)
+
+	// Only admins may change a workflow
+	_, editorToken := suite.joinWorkspace(team, "editor@example.com", models.RoleEditor)
+	resp, body = suite.makeRequestWithHeaders("PUT", "/workflow", reviewWorkflow(), editorToken, inWorkspace(team.ID))
+	suite.assertForbidden(resp, body, "manage_workflow", models.RoleAdmin)
+}
@@ -0,0 +1,258 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"task/backend/auth"
+	"task/backend/database"
+	"task/backend/models"
+	"task/backend/repository"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// ============================================================================
+// WORKSPACE TESTS
+// ============================================================================
+
+// createWorkspace creates a shared workspace owned by the suite user.
+func (suite *HandlerTestSuite) createWorkspace(name string) models.Workspace {
+	resp, body := suite.makeRequest("POST", "/workspaces", models.CreateWorkspaceRequest{Name: name})
+	suite.Require().Equal(http.StatusCreated, resp.StatusCode, string(body))
+
+	var workspace models.Workspace
+	suite.Require().NoError(json.Unmarshal(body, &workspace))
+	return workspace
+}
+
+// newTaskRequest returns a valid create request for title.
+func newTaskRequest(title string) models.CreateTaskRequest {
+	dueDate := time.Now().Add(24 * time.Hour)
+	return models.CreateTaskRequest{Title: title, Status: models.TaskStatusPending, DueDate: &dueDate}
+}
+
+// inWorkspace returns the headers selecting workspace for a request.
+func inWorkspace(workspaceID int) map[string]string {
+	return map[string]string{auth.WorkspaceHeader: strconv.Itoa(workspaceID)}
+}
+
+func (suite *HandlerTestSuite) TestWorkspace_CreateAndList() {
+	team := suite.createWorkspace("Team")
+	assert.False(suite.T(), team.Personal)
+	assert.Equal(suite.T(), suite.user.ID, team.OwnerID)
+
+	resp, body := suite.makeRequest("GET", "/workspaces", nil)
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+
+	var workspaces []models.Workspace
+	suite.Require().NoError(json.Unmarshal(body, &workspaces))
+	suite.Require().Len(workspaces, 2)
+	assert.Equal(suite.T(), suite.workspace.ID, workspaces[0].ID)
+	assert.True(suite.T(), workspaces[0].Personal)
+	assert.Equal(suite.T(), team.ID, workspaces[1].ID)
+}
+
+func (suite *HandlerTestSuite) TestWorkspace_CreateIsAtomic() {
+	if suite.driver != database.DriverSQLite {
+		suite.T().Skip("needs a membership row without its workspace")
+	}
+	// Take the owner's membership of the next workspace, so adding it fails
+	nextID := suite.workspace.ID + 1
+	suite.Require().NoError(suite.store.Workspaces.AddMember(&models.WorkspaceMember{WorkspaceID: nextID, UserID: suite.user.ID, Role: models.RoleViewer}))
+
+	resp, _ := suite.makeRequest("POST", "/workspaces", models.CreateWorkspaceRequest{Name: "Team"})
+	assert.Equal(suite.T(), http.StatusInternalServerError, resp.StatusCode)
+	_, err := suite.store.Workspaces.GetByID(nextID)
+	assert.ErrorIs(suite.T(), err, repository.ErrNotFound, "the workspace is rolled back with its owner")
+}
+
+func (suite *HandlerTestSuite) TestWorkspace_SameTitleInDifferentWorkspaces() {
+	team := suite.createWorkspace("Team")
+	request := newTaskRequest("deploy")
+
+	resp, _ := suite.makeRequest("POST", "/tasks", request)
+	assert.Equal(suite.T(), http.StatusCreated, resp.StatusCode)
+
+	resp, body := suite.makeRequestWithHeaders("POST", "/tasks", request, suite.token, inWorkspace(team.ID))
+	assert.Equal(suite.T(), http.StatusCreated, resp.StatusCode, string(body))
+	var teamTask models.Task
+	suite.Require().NoError(json.Unmarshal(body, &teamTask))
+	assert.Equal(suite.T(), team.ID, teamTask.WorkspaceID)
+
+	// Titles are still unique within a workspace
+	resp, _ = suite.makeRequestWithHeaders("POST", "/tasks", request, suite.token, inWorkspace(team.ID))
+	assert.Equal(suite.T(), http.StatusConflict, resp.StatusCode)
+
+	// Each workspace only lists its own task
+	resp, body = suite.makeRequestWithHeaders("GET", "/tasks?title=deploy", nil, suite.token, inWorkspace(team.ID))
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+	var tasksResp models.TasksResponse
+	suite.Require().NoError(json.Unmarshal(body, &tasksResp))
+	suite.Require().Len(tasksResp.Tasks, 1)
+	assert.Equal(suite.T(), teamTask.ID, tasksResp.Tasks[0].ID)
+
+	// A task is not reachable through another workspace
+	resp, _ = suite.makeRequest("GET", fmt.Sprintf("/tasks/%d", teamTask.ID), nil)
+	assert.Equal(suite.T(), http.StatusNotFound, resp.StatusCode)
+}
+
+func (suite *HandlerTestSuite) TestWorkspace_MembersShareTasks() {
+	team := suite.createWorkspace("Team")
+	other, otherToken := suite.createTestUser("other@example.com")
+
+	resp, body := suite.makeRequestWithHeaders("POST", "/tasks", newTaskRequest("shared"), suite.token, inWorkspace(team.ID))
+	suite.Require().Equal(http.StatusCreated, resp.StatusCode, string(body))
+	var task models.Task
+	suite.Require().NoError(json.Unmarshal(body, &task))
+	taskURL := fmt.Sprintf("/tasks/%d", task.ID)
+
+	// Non-members can't tell the workspace exists
+	resp, _ = suite.makeRequestWithHeaders("GET", taskURL, nil, otherToken, inWorkspace(team.ID))
+	assert.Equal(suite.T(), http.StatusNotFound, resp.StatusCode)
+
+	resp, body = suite.makeRequest("POST", fmt.Sprintf("/workspaces/%d/members", team.ID), models.AddMemberRequest{Email: "Other@Example.com"})
+	suite.Require().Equal(http.StatusCreated, resp.StatusCode, string(body))
+
+	resp, _ = suite.makeRequestWithHeaders("GET", taskURL, nil, otherToken, inWorkspace(team.ID))
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+
+	resp, body = suite.makeRequest("GET", fmt.Sprintf("/workspaces/%d/members", team.ID), nil)
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+	var members []models.WorkspaceMember
+	suite.Require().NoError(json.Unmarshal(body, &members))
+	assert.Len(suite.T(), members, 2)
+
+	resp, _ = suite.makeRequest("DELETE", fmt.Sprintf("/workspaces/%d/members/%d", team.ID, other.ID), nil)
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+
+	resp, _ = suite.makeRequestWithHeaders("GET", taskURL, nil, otherToken, inWorkspace(team.ID))
+	assert.Equal(suite.T(), http.StatusNotFound, resp.StatusCode)
+}
+
+func (suite *HandlerTestSuite) TestWorkspace_MemberErrors() {
+	team := suite.createWorkspace("Team")
+	suite.createTestUser("other@example.com")
+	_, strangerToken := suite.createTestUser("stranger@example.com")
+	membersURL := fmt.Sprintf("/workspaces/%d/members", team.ID)
+
+	resp, _ := suite.makeRequest("POST", membersURL, models.AddMemberRequest{Email: "nobody@example.com"})
+	assert.Equal(suite.T(), http.StatusNotFound, resp.StatusCode)
+
+	resp, _ = suite.makeRequest("POST", membersURL, models.AddMemberRequest{Email: "other@example.com"})
+	assert.Equal(suite.T(), http.StatusCreated, resp.StatusCode)
+	resp, _ = suite.makeRequest("POST", membersURL, models.AddMemberRequest{Email: "other@example.com"})
+	assert.Equal(suite.T(), http.StatusConflict, resp.StatusCode)
+
+	resp, _ = suite.makeRequest("DELETE", fmt.Sprintf("%s/%d", membersURL, suite.user.ID), nil)
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.StatusCode)
+
+	resp, _ = suite.makeRequest("POST", fmt.Sprintf("/workspaces/%d/members", suite.workspace.ID), models.AddMemberRequest{Email: "other@example.com"})
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.StatusCode)
+
+	resp, _ = suite.makeRequestWithToken("GET", membersURL, nil, strangerToken)
+	assert.Equal(suite.T(), http.StatusNotFound, resp.StatusCode)
+
+	resp, _ = suite.makeRequestWithHeaders("GET", "/tasks", nil, suite.token, map[string]string{auth.WorkspaceHeader: "abc"})
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.StatusCode)
+}
+
+// ============================================================================
+// ROLE TESTS
+// ============================================================================
+
+// joinWorkspace adds a new user to workspace with role and returns their
+// access token.
+func (suite *HandlerTestSuite) joinWorkspace(workspace models.Workspace, email string, role models.WorkspaceRole) (models.User, string) {
+	user, token := suite.createTestUser(email)
+	resp, body := suite.makeRequest("POST", fmt.Sprintf("/workspaces/%d/members", workspace.ID), models.AddMemberRequest{Email: email, Role: role})
+	suite.Require().Equal(http.StatusCreated, resp.StatusCode, string(body))
+	return user, token
+}
+
+func (suite *HandlerTestSuite) assertForbidden(resp *http.Response, body []byte, action auth.Action, requiredRole models.WorkspaceRole) {
+	assert.Equal(suite.T(), http.StatusForbidden, resp.StatusCode)
+	var denial map[string]string
+	suite.Require().NoError(json.Unmarshal(body, &denial))
+	assert.Equal(suite.T(), map[string]string{
+		"error":         "Insufficient permissions",
+		"action":        string(action),
+		"required_role": string(requiredRole),
+	}, denial)
+}
+
+func (suite *HandlerTestSuite) TestRoles_TaskPermissions() {
+	team := suite.createWorkspace("Team")
+	_, viewerToken := suite.joinWorkspace(team, "viewer@example.com", models.RoleViewer)
+	_, editorToken := suite.joinWorkspace(team, "editor@example.com", models.RoleEditor)
+	_, adminToken := suite.joinWorkspace(team, "admin@example.com", models.RoleAdmin)
+	headers := inWorkspace(team.ID)
+
+	resp, body := suite.makeRequestWithHeaders("POST", "/tasks", newTaskRequest("team-task"), suite.token, headers)
+	suite.Require().Equal(http.StatusCreated, resp.StatusCode, string(body))
+	var task models.Task
+	suite.Require().NoError(json.Unmarshal(body, &task))
+	taskURL := fmt.Sprintf("/tasks/%d", task.ID)
+	newTitle := "renamed"
+
+	// Viewers can only read
+	resp, _ = suite.makeRequestWithHeaders("GET", "/tasks", nil, viewerToken, headers)
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+	resp, _ = suite.makeRequestWithHeaders("GET", taskURL, nil, viewerToken, headers)
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+	resp, body = suite.makeRequestWithHeaders("POST", "/tasks", newTaskRequest("viewer-task"), viewerToken, headers)
+	suite.assertForbidden(resp, body, auth.ActionCreateTask, models.RoleEditor)
+	resp, body = suite.makeRequestWithHeaders("PUT", taskURL, models.UpdateTaskRequest{Title: &newTitle}, viewerToken, headers)
+	suite.assertForbidden(resp, body, auth.ActionUpdateTask, models.RoleEditor)
+
+	// Editors can create and update but not delete
+	resp, _ = suite.makeRequestWithHeaders("POST", "/tasks", newTaskRequest("editor-task"), editorToken, headers)
+	assert.Equal(suite.T(), http.StatusCreated, resp.StatusCode)
+	resp, _ = suite.makeRequestWithHeaders("PUT", taskURL, models.UpdateTaskRequest{Title: &newTitle}, editorToken, headers)
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+	resp, body = suite.makeRequestWithHeaders("DELETE", taskURL, nil, editorToken, headers)
+	suite.assertForbidden(resp, body, auth.ActionDeleteTask, models.RoleAdmin)
+
+	// Admins can delete
+	resp, _ = suite.makeRequestWithHeaders("DELETE", taskURL, nil, adminToken, headers)
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+}
+
+func (suite *HandlerTestSuite) TestRoles_MemberManagement() {
+	team := suite.createWorkspace("Team")
+	editor, editorToken := suite.joinWorkspace(team, "editor@example.com", models.RoleEditor)
+	_, adminToken := suite.joinWorkspace(team, "admin@example.com", models.RoleAdmin)
+	suite.createTestUser("new@example.com")
+	membersURL := fmt.Sprintf("/workspaces/%d/members", team.ID)
+	editorURL := fmt.Sprintf("%s/%d", membersURL, editor.ID)
+
+	// Editors can see members but not manage them
+	resp, _ := suite.makeRequestWithToken("GET", membersURL, nil, editorToken)
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+	resp, body := suite.makeRequestWithToken("POST", membersURL, models.AddMemberRequest{Email: "new@example.com"}, editorToken)
+	suite.assertForbidden(resp, body, auth.ActionManageMembers, models.RoleAdmin)
+
+	// New members default to editor
+	resp, body = suite.makeRequestWithToken("POST", membersURL, models.AddMemberRequest{Email: "new@example.com"}, adminToken)
+	suite.Require().Equal(http.StatusCreated, resp.StatusCode, string(body))
+	var member models.WorkspaceMember
+	suite.Require().NoError(json.Unmarshal(body, &member))
+	assert.Equal(suite.T(), models.RoleEditor, member.Role)
+
+	resp, body = suite.makeRequestWithToken("PUT", editorURL, models.UpdateMemberRequest{Role: models.RoleViewer}, adminToken)
+	suite.Require().Equal(http.StatusOK, resp.StatusCode, string(body))
+	suite.Require().NoError(json.Unmarshal(body, &member))
+	assert.Equal(suite.T(), models.RoleViewer, member.Role)
+
+	resp, body = suite.makeRequestWithHeaders("POST", "/tasks", newTaskRequest("demoted"), editorToken, inWorkspace(team.ID))
+	suite.assertForbidden(resp, body, auth.ActionCreateTask, models.RoleEditor)
+
+	// The owner role can neither be granted nor changed
+	resp, _ = suite.makeRequestWithToken("PUT", editorURL, models.UpdateMemberRequest{Role: models.RoleOwner}, adminToken)
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.StatusCode)
+	resp, _ = suite.makeRequestWithToken("PUT", fmt.Sprintf("%s/%d", membersURL, suite.user.ID), models.UpdateMemberRequest{Role: models.RoleViewer}, adminToken)
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.StatusCode)
+}
@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"errors"
+	"time"
+
+	"task/backend/auth"
+	"task/backend/events"
+	"task/backend/models"
+	"task/backend/repository"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ListTrash returns the deleted tasks of the workspace that haven't been
+// purged yet.
+func (h *TaskHandler) ListTrash(c *fiber.Ctx) error {
+	page, size := pagination(c)
+	result, err := h.Repo.List(repository.TaskFilter{
+		WorkspaceID: auth.WorkspaceID(c),
+		Deleted:     true,
+		Page:        page,
+		Size:        size,
+		SkipCount:   !c.QueryBool("count", true),
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve trash"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.TasksResponse{
+		Tasks: result.Tasks,
+		Total: result.Total,
+		Page:  page,
+		Size:  size,
+	})
+}
+
+// RestoreTask takes a task out of the trash together with the subtasks that
+// were deleted with it. The body may give the task a new title.
+func (h *TaskHandler) RestoreTask(c *fiber.Ctx) error {
+	request := new(models.RestoreTaskRequest)
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(request); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid body"})
+		}
+		if err := h.validate.Struct(request); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+	}
+
+	task, ok := h.trashedFromPath(c)
+	if !ok {
+		return nil
+	}
+
+	// A subtask can't come back under a parent that is still in the trash
+	if task.ParentID != nil {
+		if _, err := h.Repo.GetByID(*task.ParentID); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+					"error":     "Parent task is in the trash. Restore it first",
+					"parent_id": *task.ParentID,
+				})
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not restore task"})
+		}
+	}
+
+	before := *task
+	if request.Title != nil {
+		task.Title = *request.Title
+	}
+
+	var restored *models.Task
+	err := h.Events.Transaction(func(tx *repository.Store) error {
+		if err := tx.Tasks.Restore(task); err != nil {
+			return err
+		}
+
+		var err error
+		if restored, err = tx.Tasks.GetByID(task.ID); err != nil {
+			return err
+		}
+
+		// The subtasks that came back with the task are its tree now
+		tree, err := buildTree(tx.Tasks, *restored)
+		if err != nil {
+			return err
+		}
+		published := []events.Event{h.event(c, events.TaskRestored{Task: *restored, Changes: events.Diff(&before, restored)})}
+		for _, subtask := range treeTasks(tree)[1:] {
+			published = append(published, h.event(c, events.TaskRestored{Task: subtask}))
+		}
+		return h.Events.Publish(tx, published...)
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrDuplicateTitle) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Another task has taken the title of this task or one of its subtasks. Restore it with a new title"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not restore task"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(restored)
+}
+
+// PurgeTask permanently deletes a task from the trash together with its
+// trashed subtasks.
+func (h *TaskHandler) PurgeTask(c *fiber.Ctx) error {
+	task, ok := h.trashedFromPath(c)
+	if !ok {
+		return nil
+	}
+
+	err := h.Events.Transaction(func(tx *repository.Store) error {
+		purged, err := tx.Tasks.Purge(task)
+		if err != nil {
+			return err
+		}
+		published := make([]events.Event, len(purged))
+		for i, task := range purged {
+			published[i] = h.event(c, events.TaskPurged{Task: task})
+		}
+		return h.Events.Publish(tx, published...)
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not purge task"})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Task permanently deleted"})
+}
+
+// PurgeExpired permanently deletes the tasks moved to the trash before
+// cutoff in every workspace and returns how many there were. Each one is
+// published as events.TaskPurged without an actor, as the server purges
+// them on its own.
+func (h *TaskHandler) PurgeExpired(cutoff time.Time) (int, error) {
+	var purged []models.Task
+	err := h.Events.Transaction(func(tx *repository.Store) error {
+		var err error
+		if purged, err = tx.Tasks.PurgeDeletedBefore(cutoff); err != nil {
+			return err
+		}
+		published := make([]events.Event, len(purged))
+		for i, task := range purged {
+			published[i] = events.New(task.WorkspaceID, 0, events.TaskPurged{Task: task})
+		}
+		return h.Events.Publish(tx, published...)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(purged), nil
+}
+
+// trashedFromPath loads the trashed task named by the :id parameter, like
+// taskFromPath does for the others.
+func (h *TaskHandler) trashedFromPath(c *fiber.Ctx) (*models.Task, bool) {
+	taskID, err := c.ParamsInt("id")
+	if err != nil || taskID <= 0 {
+		c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid task ID"})
+		return nil, false
+	}
+
+	task, err := h.Repo.GetDeleted(taskID)
+	if err == nil && task.WorkspaceID != auth.WorkspaceID(c) {
+		err = repository.ErrNotFound
+	}
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Task not found in trash"})
+			return nil, false
+		}
+		c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve task"})
+		return nil, false
+	}
+	return task, true
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// newValidator returns a validator with the custom rules used by the request
+// models.
+func newValidator() *validator.Validate {
+	validate := validator.New()
+
+	// Custom validation for future date
+	validate.RegisterValidation("future", func(fl validator.FieldLevel) bool {
+		if date, ok := fl.Field().Interface().(time.Time); ok {
+			return date.After(time.Now())
+		}
+		return false
+	})
+
+	// Custom validation for no spaces
+	validate.RegisterValidation("nospaces", func(fl validator.FieldLevel) bool {
+		return !strings.Contains(fl.Field().String(), " ")
+	})
+
+	// Custom validation for workflow status names
+	validate.RegisterValidation("statusname", func(fl validator.FieldLevel) bool {
+		return statusName.MatchString(fl.Field().String())
+	})
+
+	return validate
+}
+
+// statusName matches lowercase identifiers like in_progress.
+var statusName = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
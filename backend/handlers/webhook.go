@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"errors"
+	"time"
+
+	"task/backend/auth"
+	"task/backend/models"
+	"task/backend/repository"
+	"task/backend/webhook"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+// WebhookHandler manages the webhooks of the request's workspace.
+type WebhookHandler struct {
+	Repo repository.WebhookRepository
+	// AllowPrivate accepts URLs of loopback, private and link-local
+	// addresses, which are refused otherwise
+	AllowPrivate bool
+	validate     *validator.Validate
+}
+
+func NewWebhookHandler(repo repository.WebhookRepository, allowPrivate bool) *WebhookHandler {
+	return &WebhookHandler{Repo: repo, AllowPrivate: allowPrivate, validate: newValidator()}
+}
+
+func (h *WebhookHandler) ListWebhooks(c *fiber.Ctx) error {
+	hooks, err := h.Repo.List(auth.WorkspaceID(c))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve webhooks"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(hooks)
+}
+
+func (h *WebhookHandler) CreateWebhook(c *fiber.Ctx) error {
+	request := new(models.CreateWebhookRequest)
+	if err := c.BodyParser(request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid body"})
+	}
+
+	if err := h.validate.Struct(request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err := h.checkURL(c, request.URL); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	hook := models.Webhook{
+		WorkspaceID: auth.WorkspaceID(c),
+		URL:         request.URL,
+		Events:      request.Events,
+		Secret:      request.Secret,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	if hook.Events == nil {
+		hook.Events = []models.WebhookEvent{}
+	}
+
+	if err := h.Repo.Create(&hook); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not create webhook"})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(hook)
+}
+
+func (h *WebhookHandler) UpdateWebhook(c *fiber.Ctx) error {
+	hook, ok := h.findWebhook(c)
+	if !ok {
+		return nil
+	}
+
+	request := new(models.UpdateWebhookRequest)
+	if err := c.BodyParser(request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid body"})
+	}
+
+	if err := h.validate.Struct(request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if request.URL != nil {
+		if err := h.checkURL(c, *request.URL); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		hook.URL = *request.URL
+	}
+	if request.Events != nil {
+		hook.Events = *request.Events
+	}
+	if request.Secret != nil {
+		hook.Secret = *request.Secret
+	}
+	hook.UpdatedAt = time.Now()
+
+	if err := h.Repo.Update(hook); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not update webhook"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(hook)
+}
+
+func (h *WebhookHandler) DeleteWebhook(c *fiber.Ctx) error {
+	hook, ok := h.findWebhook(c)
+	if !ok {
+		return nil
+	}
+
+	if err := h.Repo.Delete(hook); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not delete webhook"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Webhook deleted successfully"})
+}
+
+// ListDeliveries returns the delivery log of a webhook, newest first:
+// pending deliveries with their next attempt, and finished ones with the
+// outcome of their last attempt.
+func (h *WebhookHandler) ListDeliveries(c *fiber.Ctx) error {
+	hook, ok := h.findWebhook(c)
+	if !ok {
+		return nil
+	}
+
+	page, size := pagination(c)
+	deliveries, total, err := h.Repo.ListDeliveries(hook.ID, page, size)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve deliveries"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.WebhookDeliveriesResponse{
+		Deliveries: deliveries,
+		Total:      total,
+		Page:       page,
+		Size:       size,
+	})
+}
+
+// findWebhook loads the webhook addressed by the :id route parameter from
+// the request's workspace. Otherwise it writes the error response and
+// returns false.
+func (h *WebhookHandler) findWebhook(c *fiber.Ctx) (*models.Webhook, bool) {
+	hookID, err := c.ParamsInt("id")
+	if err != nil || hookID <= 0 {
+		c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid webhook ID"})
+		return nil, false
+	}
+
+	hook, err := h.Repo.GetByID(hookID)
+	if err == nil && hook.WorkspaceID != auth.WorkspaceID(c) {
+		err = repository.ErrNotFound
+	}
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Webhook not found"})
+		} else {
+			c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve webhook"})
+		}
+		return nil, false
+	}
+	return hook, true
+}
+
+// checkURL refuses webhook URLs that reach the server's own network unless
+// AllowPrivate is set.
+func (h *WebhookHandler) checkURL(c *fiber.Ctx, url string) error {
+	if h.AllowPrivate {
+		return nil
+	}
+	return webhook.CheckURL(c.UserContext(), url)
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"fmt"
+	"slices"
+
+	"task/backend/auth"
+	"task/backend/models"
+	"task/backend/repository"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+// WorkflowHandler manages the status workflow of the request's workspace.
+type WorkflowHandler struct {
+	Repo     repository.WorkflowRepository
+	Tasks    repository.TaskRepository
+	validate *validator.Validate
+}
+
+func NewWorkflowHandler(repo repository.WorkflowRepository, tasks repository.TaskRepository) *WorkflowHandler {
+	return &WorkflowHandler{Repo: repo, Tasks: tasks, validate: newValidator()}
+}
+
+// GetWorkflow returns the workflow in effect, which is the default one until
+// the workspace defines its own.
+func (h *WorkflowHandler) GetWorkflow(c *fiber.Ctx) error {
+	workflow, err := repository.WorkflowFor(h.Repo, auth.WorkspaceID(c))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve workflow"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(workflow)
+}
+
+// UpdateWorkflow replaces the workspace's workflow.
+func (h *WorkflowHandler) UpdateWorkflow(c *fiber.Ctx) error {
+	request := new(models.UpdateWorkflowRequest)
+	if err := c.BodyParser(request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid body"})
+	}
+
+	if err := h.validate.Struct(request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err := checkWorkflow(request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	workflow := &models.Workflow{
+		WorkspaceID: auth.WorkspaceID(c),
+		Initial:     request.Initial,
+		Statuses:    request.Statuses,
+		Transitions: make(map[models.TaskStatus][]models.TaskStatus, len(request.Transitions)),
+	}
+	for from, to := range request.Transitions {
+		if to = uniqueStatuses(to, from); len(to) > 0 {
+			workflow.Transitions[from] = to
+		}
+	}
+
+	return h.replace(c, workflow)
+}
+
+// ResetWorkflow reverts the workspace to the default workflow.
+func (h *WorkflowHandler) ResetWorkflow(c *fiber.Ctx) error {
+	return h.replace(c, models.DefaultWorkflow(auth.WorkspaceID(c)))
+}
+
+// replace makes workflow the one in effect, refusing to drop statuses that
+// tasks still have, including the tasks in the trash as they could be
+// restored.
+func (h *WorkflowHandler) replace(c *fiber.Ctx, workflow *models.Workflow) error {
+	current, err := repository.WorkflowFor(h.Repo, workflow.WorkspaceID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not update workflow"})
+	}
+
+	for _, status := range current.Statuses {
+		if workflow.HasStatus(status) {
+			continue
+		}
+		for _, deleted := range []bool{false, true} {
+			page, err := h.Tasks.List(repository.TaskFilter{
+				WorkspaceID: workflow.WorkspaceID,
+				Status:      string(status),
+				Page:        1,
+				Size:        1,
+				SkipCount:   true,
+				Deleted:     deleted,
+			})
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not update workflow"})
+			}
+			if len(page.Tasks) == 0 {
+				continue
+			}
+			message := fmt.Sprintf("Tasks still have status %q. Move them to another status first", status)
+			if deleted {
+				message = fmt.Sprintf("Tasks in the trash still have status %q. Restore and move them, or purge them first", status)
+			}
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": message, "status": status})
+		}
+	}
+
+	if workflow.Default {
+		err = h.Repo.Delete(workflow.WorkspaceID)
+	} else {
+		err = h.Repo.Save(workflow)
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not update workflow"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(workflow)
+}
+
+// checkWorkflow verifies that a workflow request is consistent: statuses
+// are unique, and the initial status and every transition use them.
+// Completed must be included as subtask progress and dependencies rely on it.
+func checkWorkflow(request *models.UpdateWorkflowRequest) error {
+	for i, status := range request.Statuses {
+		if slices.Contains(request.Statuses[:i], status) {
+			return fmt.Errorf("status %q is listed twice", status)
+		}
+	}
+
+	workflow := models.Workflow{Statuses: request.Statuses}
+	if !workflow.HasStatus(request.Initial) {
+		return fmt.Errorf("initial status %q is not one of the statuses", request.Initial)
+	}
+	if !workflow.HasStatus(models.TaskStatusCompleted) {
+		return fmt.Errorf("statuses must include %q", models.TaskStatusCompleted)
+	}
+	for from, targets := range request.Transitions {
+		if !workflow.HasStatus(from) {
+			return fmt.Errorf("transition from unknown status %q", from)
+		}
+		for _, to := range targets {
+			if !workflow.HasStatus(to) {
+				return fmt.Errorf("transition from %q to unknown status %q", from, to)
+			}
+		}
+	}
+	return nil
+}
+
+// uniqueStatuses drops repeated statuses and from itself, which is always
+// allowed anyway.
+func uniqueStatuses(statuses []models.TaskStatus, from models.TaskStatus) []models.TaskStatus {
+	unique := make([]models.TaskStatus, 0, len(statuses))
+	for _, status := range statuses {
+		if status != from && !slices.Contains(unique, status) {
+			unique = append(unique, status)
+		}
+	}
+	return unique
+}
+
+// statusNotAllowed writes the 422 response for a status the workflow
+// doesn't accept, listing the statuses that are.
+func statusNotAllowed(c *fiber.Ctx, message string, from models.TaskStatus, allowed []models.TaskStatus) error {
+	body := fiber.Map{"error": message, "allowed": allowed}
+	if from != "" {
+		body["from"] = from
+	}
+	return c.Status(fiber.StatusUnprocessableEntity).JSON(body)
+}
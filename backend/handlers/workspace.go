@@ -0,0 +1,230 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"task/backend/auth"
+	"task/backend/models"
+	"task/backend/repository"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+// WorkspaceHandler manages workspaces and their membership.
+type WorkspaceHandler struct {
+	Repo     repository.WorkspaceRepository
+	Users    repository.UserRepository
+	validate *validator.Validate
+}
+
+func NewWorkspaceHandler(repo repository.WorkspaceRepository, users repository.UserRepository) *WorkspaceHandler {
+	return &WorkspaceHandler{Repo: repo, Users: users, validate: newValidator()}
+}
+
+func (h *WorkspaceHandler) CreateWorkspace(c *fiber.Ctx) error {
+	request := new(models.CreateWorkspaceRequest)
+	if err := c.BodyParser(request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid body"})
+	}
+
+	if err := h.validate.Struct(request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	workspace := models.Workspace{
+		Name:      request.Name,
+		OwnerID:   auth.UserID(c),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	if err := h.Repo.Create(&workspace); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not create workspace"})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(workspace)
+}
+
+func (h *WorkspaceHandler) ListWorkspaces(c *fiber.Ctx) error {
+	// Make sure the personal workspace shows up even before first use
+	if _, err := repository.EnsurePersonalWorkspace(h.Repo, auth.UserID(c)); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve workspaces"})
+	}
+
+	workspaces, err := h.Repo.ListForUser(auth.UserID(c))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve workspaces"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(workspaces)
+}
+
+func (h *WorkspaceHandler) ListMembers(c *fiber.Ctx) error {
+	workspace, _, ok := h.findWorkspace(c)
+	if !ok {
+		return nil
+	}
+
+	members, err := h.Repo.ListMembers(workspace.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve members"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(members)
+}
+
+func (h *WorkspaceHandler) AddMember(c *fiber.Ctx) error {
+	workspace, caller, ok := h.findWorkspace(c)
+	if !ok {
+		return nil
+	}
+
+	if !auth.Can(caller.Role, auth.ActionManageMembers) {
+		return auth.Forbidden(c, auth.ActionManageMembers)
+	}
+
+	if workspace.Personal {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Personal workspaces cannot be shared"})
+	}
+
+	request := new(models.AddMemberRequest)
+	if err := c.BodyParser(request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid body"})
+	}
+
+	request.Email = strings.ToLower(strings.TrimSpace(request.Email))
+	if err := h.validate.Struct(request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if request.Role == "" {
+		request.Role = models.RoleEditor
+	}
+
+	user, err := h.Users.GetByEmail(request.Email)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "User not found"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not add member"})
+	}
+
+	member := models.WorkspaceMember{
+		WorkspaceID: workspace.ID,
+		UserID:      user.ID,
+		Role:        request.Role,
+		CreatedAt:   time.Now(),
+	}
+	if err := h.Repo.AddMember(&member); err != nil {
+		if errors.Is(err, repository.ErrAlreadyMember) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "User is already a member of this workspace"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not add member"})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(member)
+}
+
+func (h *WorkspaceHandler) UpdateMember(c *fiber.Ctx) error {
+	workspace, caller, ok := h.findWorkspace(c)
+	if !ok {
+		return nil
+	}
+
+	if !auth.Can(caller.Role, auth.ActionManageMembers) {
+		return auth.Forbidden(c, auth.ActionManageMembers)
+	}
+
+	userID, err := c.ParamsInt("userId")
+	if err != nil || userID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid user ID"})
+	}
+
+	request := new(models.UpdateMemberRequest)
+	if err := c.BodyParser(request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid body"})
+	}
+
+	if err := h.validate.Struct(request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if userID == workspace.OwnerID {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "The workspace owner's role cannot be changed"})
+	}
+
+	member, err := h.Repo.GetMember(workspace.ID, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Member not found"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not update member"})
+	}
+
+	member.Role = request.Role
+	if err := h.Repo.UpdateMember(member); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not update member"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(member)
+}
+
+func (h *WorkspaceHandler) RemoveMember(c *fiber.Ctx) error {
+	workspace, caller, ok := h.findWorkspace(c)
+	if !ok {
+		return nil
+	}
+
+	if !auth.Can(caller.Role, auth.ActionManageMembers) {
+		return auth.Forbidden(c, auth.ActionManageMembers)
+	}
+
+	userID, err := c.ParamsInt("userId")
+	if err != nil || userID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid user ID"})
+	}
+
+	if userID == workspace.OwnerID {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "The workspace owner cannot be removed"})
+	}
+
+	if err := h.Repo.RemoveMember(workspace.ID, userID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Member not found"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not remove member"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Member removed successfully"})
+}
+
+// findWorkspace loads the workspace addressed by the :id route parameter
+// together with the caller's membership. If it is missing or the caller is
+// not a member, it writes the error response and returns false.
+func (h *WorkspaceHandler) findWorkspace(c *fiber.Ctx) (*models.Workspace, *models.WorkspaceMember, bool) {
+	workspaceID, err := c.ParamsInt("id")
+	if err != nil || workspaceID <= 0 {
+		c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid workspace ID"})
+		return nil, nil, false
+	}
+
+	caller, err := h.Repo.GetMember(workspaceID, auth.UserID(c))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Workspace not found"})
+		} else {
+			c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve workspace"})
+		}
+		return nil, nil, false
+	}
+
+	workspace, err := h.Repo.GetByID(workspaceID)
+	if err != nil {
+		c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Could not retrieve workspace"})
+		return nil, nil, false
+	}
+	return workspace, caller, true
+}
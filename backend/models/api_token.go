@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+type TokenScope string
+
+const (
+	TokenScopeRead      TokenScope = "read"
+	TokenScopeReadWrite TokenScope = "read_write"
+)
+
+// APIToken is a long-lived personal token for scripts and CI. Only a hash of
+// the secret is stored; the plaintext is shown once on creation.
+type APIToken struct {
+	ID         int        `json:"id" gorm:"primaryKey"`
+	UserID     int        `json:"-" gorm:"index;not null"`
+	Name       string     `json:"name" gorm:"not null"`
+	Prefix     string     `json:"prefix" gorm:"not null"`
+	TokenHash  string     `json:"-" gorm:"uniqueIndex;not null"`
+	Scope      TokenScope `json:"scope" gorm:"not null"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type CreateAPITokenRequest struct {
+	Name      string     `json:"name" validate:"required,max=100"`
+	Scope     TokenScope `json:"scope" validate:"required,oneof=read read_write"`
+	ExpiresAt *time.Time `json:"expires_at" validate:"omitempty,future"`
+}
+
+type CreateAPITokenResponse struct {
+	APIToken
+	Token string `json:"token"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+type AuditAction string
+
+const (
+	AuditCreated  AuditAction = "created"
+	AuditUpdated  AuditAction = "updated"
+	AuditDeleted  AuditAction = "deleted"
+	AuditRestored AuditAction = "restored"
+	AuditPurged   AuditAction = "purged"
+)
+
+// AuditEntry records one change to a task: who made it, when, and which
+// fields it changed. Entries are never changed or removed, and outlive the
+// task they describe.
+type AuditEntry struct {
+	ID          int           `json:"id" gorm:"primaryKey"`
+	TaskID      int           `json:"task_id" gorm:"not null;index"`
+	WorkspaceID int           `json:"workspace_id" gorm:"not null"`
+	ActorID     int           `json:"actor_id" gorm:"not null"`
+	Action      AuditAction   `json:"action" gorm:"not null"`
+	Changes     []FieldChange `json:"changes" gorm:"not null;serializer:json"`
+	CreatedAt   time.Time     `json:"created_at"`
+}
+
+// FieldChange is the value of one task field before and after a change.
+// Before is null for created tasks.
+type FieldChange struct {
+	Field  string `json:"field"`
+	Before any    `json:"before"`
+	After  any    `json:"after"`
+}
+
+// AuditResponse is one page of a task's history, newest entries first.
+type AuditResponse struct {
+	Entries []AuditEntry `json:"entries"`
+	Total   int64        `json:"total"`
+	Page    int          `json:"page"`
+	Size    int          `json:"size"`
+}
@@ -0,0 +1,43 @@
+package models
+
+import "encoding/json"
+
+// Types of the messages on the board WebSocket besides task events.
+const (
+	BoardUpdateTask = "update_task"
+	BoardPresence   = "presence"
+	BoardResult     = "result"
+	BoardError      = "error"
+)
+
+// BoardViewer is a user looking at a board.
+type BoardViewer struct {
+	UserID int    `json:"user_id"`
+	Email  string `json:"email"`
+}
+
+// BoardCommand is a message from a board client. The only command is
+// update_task, which changes a task like PUT /tasks/:id.
+type BoardCommand struct {
+	Type string `json:"type"`
+	// Ref is echoed in the reply so clients can match the two up
+	Ref    string `json:"ref"`
+	TaskID int    `json:"task_id"`
+	// Changes is the request body of PUT /tasks/:id
+	Changes json.RawMessage `json:"changes"`
+}
+
+// BoardMessage is a message to board clients. Task events carry ID and
+// Data like the task stream; presence carries Viewers; result answers a
+// command with the Status and Body of the matching REST response; error
+// reports a message that isn't a valid command.
+type BoardMessage struct {
+	Type    string          `json:"type"`
+	Ref     string          `json:"ref,omitempty"`
+	ID      uint64          `json:"id,omitempty"`
+	Data    json.RawMessage `json:"data,omitempty"`
+	Viewers []BoardViewer   `json:"viewers,omitempty"`
+	Status  int             `json:"status,omitempty"`
+	Body    json.RawMessage `json:"body,omitempty"`
+	Error   string          `json:"error,omitempty"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// TaskDependency records that TaskID cannot start before DependsOnID is
+// completed. Both tasks belong to the same workspace.
+type TaskDependency struct {
+	TaskID      int       `json:"task_id" gorm:"primaryKey;autoIncrement:false"`
+	DependsOnID int       `json:"depends_on_id" gorm:"primaryKey;autoIncrement:false;index"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type AddDependencyRequest struct {
+	DependsOnID int `json:"depends_on_id" validate:"required,gt=0"`
+}
+
+// TaskDependencies lists the tasks a task waits for and the tasks waiting
+// for it.
+type TaskDependencies struct {
+	DependsOn  []Task `json:"depends_on"`
+	Dependents []Task `json:"dependents"`
+}
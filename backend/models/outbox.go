@@ -0,0 +1,18 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// OutboxEvent is a domain event stored by the transaction that caused it.
+// It stays in the outbox until it has been handed to the asynchronous
+// subscribers, so events survive a crash right after the commit.
+type OutboxEvent struct {
+	ID          int             `gorm:"primaryKey"`
+	Type        string          `gorm:"not null"`
+	WorkspaceID int             `gorm:"not null"`
+	ActorID     int             `gorm:"not null"`
+	Payload     json.RawMessage `gorm:"serializer:json;not null"`
+	OccurredAt  time.Time       `gorm:"not null"`
+}
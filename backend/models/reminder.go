@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// TaskReminder records that a reminder window of a task has passed, so that
+// each window is handled once per due date, across restarts and servers.
+type TaskReminder struct {
+	ID     int `json:"id" gorm:"primaryKey"`
+	TaskID int `json:"task_id" gorm:"not null;uniqueIndex:idx_task_reminders_once,priority:1"`
+	// DueDate is the due date the window was measured from. Moving the due
+	// date opens the task's windows again.
+	DueDate time.Time `json:"due_date" gorm:"not null;uniqueIndex:idx_task_reminders_once,priority:2"`
+	// MinutesBefore is the window, in minutes before the due date. 0 is
+	// the window that opens when the task becomes overdue.
+	MinutesBefore int `json:"minutes_before" gorm:"not null;uniqueIndex:idx_task_reminders_once,priority:3"`
+	// Notified is false for windows that passed while a closer one was
+	// open too, which are recorded without a reminder of their own
+	Notified  bool      `json:"notified" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// TaskStreamEvent is the data of an event on the live task stream.
+type TaskStreamEvent struct {
+	Task       Task          `json:"task"`
+	Changes    []FieldChange `json:"changes,omitempty"`
+	ActorID    int           `json:"actor_id"`
+	OccurredAt time.Time     `json:"occurred_at"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// Tag is a workspace-wide label that can be attached to any number of tasks.
+type Tag struct {
+	ID          int       `json:"id" gorm:"primaryKey"`
+	WorkspaceID int       `json:"workspace_id" gorm:"not null;uniqueIndex:idx_tags_workspace_name,priority:1"`
+	Name        string    `json:"name" gorm:"not null;uniqueIndex:idx_tags_workspace_name,priority:2"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Tag names are used comma-separated in the tag filter, so they can't
+// contain commas.
+type CreateTagRequest struct {
+	Name string `json:"name" validate:"required,max=50,excludesall=0x2C"`
+}
+
+type RenameTagRequest struct {
+	Name string `json:"name" validate:"required,max=50,excludesall=0x2C"`
+}
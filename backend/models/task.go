@@ -3,6 +3,8 @@ package models
 import (
 	"database/sql/driver"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 type TaskStatus string
@@ -13,36 +15,127 @@ const (
 	TaskStatusCompleted  TaskStatus = "completed"
 )
 
+type TaskPriority string
+
+const (
+	TaskPriorityLow    TaskPriority = "low"
+	TaskPriorityMedium TaskPriority = "medium"
+	TaskPriorityHigh   TaskPriority = "high"
+	TaskPriorityUrgent TaskPriority = "urgent"
+)
+
+// TaskPriorities lists every priority from least to most urgent.
+var TaskPriorities = []TaskPriority{TaskPriorityLow, TaskPriorityMedium, TaskPriorityHigh, TaskPriorityUrgent}
+
+// Rank returns the priority's position in TaskPriorities starting at 1, so
+// more urgent priorities rank higher. Unknown priorities rank 0.
+func (p TaskPriority) Rank() int {
+	for i, priority := range TaskPriorities {
+		if p == priority {
+			return i + 1
+		}
+	}
+	return 0
+}
 
 type Task struct {
 	ID          int          `json:"id" gorm:"primaryKey"`
-	Title       string       `json:"title" gorm:"unique;not null"`
+	Title       string       `json:"title" gorm:"not null;uniqueIndex:idx_tasks_workspace_title,priority:2,where:deleted_at IS NULL"`
 	Description string       `json:"description"`
 	Status      TaskStatus   `json:"status" gorm:"default:'pending'"`
+	Priority    TaskPriority `json:"priority" gorm:"not null;default:'medium'"`
 	DueDate     *time.Time   `json:"due_date"`
-	CreatedAt   time.Time    `json:"created_at"`
-	UpdatedAt   time.Time    `json:"updated_at"`
+	OwnerID     int          `json:"owner_id" gorm:"index;not null;default:0"`
+	WorkspaceID int          `json:"workspace_id" gorm:"not null;default:0;uniqueIndex:idx_tasks_workspace_title,priority:1"`
+	// ParentID makes the task a subtask of another task in the workspace
+	ParentID *int `json:"parent_id" gorm:"index"`
+	// Recurrence is an RRULE such as FREQ=WEEKLY;BYDAY=MO. Completing the
+	// task creates the next occurrence, which takes the rule over.
+	Recurrence string `json:"recurrence,omitempty" gorm:"not null;default:''"`
+	// SeriesID is the ID of the first task of a recurring series and
+	// Occurrence the position of the task in it, counting from 1
+	SeriesID   *int `json:"series_id,omitempty" gorm:"index"`
+	Occurrence int  `json:"occurrence,omitempty" gorm:"not null;default:0"`
+	// Reminders are the minutes before the due date at which reminders are
+	// sent, in ascending order. When empty the server's defaults apply.
+	Reminders []int         `json:"reminders,omitempty" gorm:"serializer:json"`
+	Tags      []Tag         `json:"tags" gorm:"many2many:task_tags"`
+	Progress  *TaskProgress `json:"progress,omitempty" gorm:"-"`
+	// Blocked is set while any task this one depends on is neither completed
+	// nor cancelled
+	Blocked   bool      `json:"blocked" gorm:"-"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// DeletedAt is set while the task is in the trash. Trashed tasks don't
+	// hold on to their titles.
+	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+}
+
+// TaskProgress rolls the completion of a task's subtasks up into the task.
+// It is only reported for tasks that have subtasks.
+type TaskProgress struct {
+	// Completed and Total count the direct subtasks
+	Completed int `json:"completed"`
+	Total     int `json:"total"`
+	// Percent averages over the direct subtasks, where a completed subtask
+	// counts fully and any other one by its own progress
+	Percent float64 `json:"percent"`
+}
+
+// TaskNode is a task together with its subtasks, recursively.
+type TaskNode struct {
+	Task
+	Children []TaskNode `json:"children"`
 }
 
 type CreateTaskRequest struct {
 	Title       string       `json:"title" validate:"required,min=1,max=200,nospaces"`
 	Description string       `json:"description"`
 	Status      TaskStatus   `json:"status"`
+	Priority    TaskPriority `json:"priority" validate:"omitempty,oneof=low medium high urgent"`
 	DueDate     *time.Time   `json:"due_date" validate:"required,future"`
+	ParentID    *int         `json:"parent_id" validate:"omitempty,gt=0"`
+	Recurrence  string       `json:"recurrence" validate:"max=200"`
+	// Tags are tag names. Tags that don't exist yet are created.
+	Tags []string `json:"tags" validate:"max=20,dive,required,max=50,excludesall=0x2C"`
+	// Reminders are minutes before the due date, up to a week
+	Reminders []int `json:"reminders" validate:"max=10,unique,dive,min=1,max=10080"`
 }
 
 type UpdateTaskRequest struct {
 	Title       *string       `json:"title,omitempty"`
 	Description *string       `json:"description,omitempty"`
 	Status      *TaskStatus   `json:"status,omitempty"`
+	Priority    *TaskPriority `json:"priority,omitempty" validate:"omitempty,oneof=low medium high urgent"`
 	DueDate     *time.Time    `json:"due_date,omitempty"`
+	// ParentID moves the task under another task; 0 makes it a top-level task
+	ParentID *int `json:"parent_id,omitempty" validate:"omitempty,gte=0"`
+	// Recurrence replaces the task's RRULE; an empty string stops it repeating
+	Recurrence *string `json:"recurrence,omitempty" validate:"omitempty,max=200"`
+	// Tags replaces the task's tags when present; an empty list removes all.
+	Tags *[]string `json:"tags,omitempty" validate:"omitempty,max=20,dive,required,max=50,excludesall=0x2C"`
+	// Reminders replaces the task's reminders; an empty list restores the
+	// server's defaults
+	Reminders *[]int `json:"reminders,omitempty" validate:"omitempty,max=10,unique,dive,min=1,max=10080"`
+	// IgnoreDependencies lets a blocked task start or complete anyway
+	IgnoreDependencies bool `json:"ignore_dependencies,omitempty"`
+}
+
+// RestoreTaskRequest optionally renames a task on its way out of the trash,
+// for when another task has taken its title in the meantime.
+type RestoreTaskRequest struct {
+	Title *string `json:"title,omitempty" validate:"omitempty,min=1,max=200,nospaces"`
 }
 
+// TasksResponse is one page of tasks. Page is omitted when paging by
+// cursor and Total when the count was skipped with count=false.
 type TasksResponse struct {
-	Tasks []Task `json:"tasks"`
-	Total int64  `json:"total"`
-	Page  int    `json:"page"`
-	Size  int    `json:"size"`
+	Tasks      []Task `json:"tasks"`
+	Total      *int64 `json:"total,omitempty"`
+	Page       int    `json:"page,omitempty"`
+	Size       int    `json:"size"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
 }
 
 // Implement driver.Valuer interface for TaskStatus
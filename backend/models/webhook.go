@@ -0,0 +1,101 @@
+package models
+
+import (
+	"encoding/json"
+	"slices"
+	"time"
+)
+
+// WebhookEvent names a task lifecycle event that webhooks can subscribe to.
+type WebhookEvent string
+
+const (
+	EventTaskCreated   WebhookEvent = "task.created"
+	EventTaskUpdated   WebhookEvent = "task.updated"
+	EventTaskCompleted WebhookEvent = "task.completed"
+	EventTaskDeleted   WebhookEvent = "task.deleted"
+	EventTaskDueSoon   WebhookEvent = "task.due_soon"
+	EventTaskOverdue   WebhookEvent = "task.overdue"
+)
+
+// Webhook subscribes a URL to the task events of a workspace.
+type Webhook struct {
+	ID          int    `json:"id" gorm:"primaryKey"`
+	WorkspaceID int    `json:"workspace_id" gorm:"not null;index"`
+	URL         string `json:"url" gorm:"not null"`
+	// Events the webhook receives. Empty means all of them.
+	Events []WebhookEvent `json:"events" gorm:"serializer:json;not null"`
+	// Secret signs the payloads sent to the webhook. It is never returned.
+	Secret    string    `json:"-" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Wants reports whether the webhook subscribes to event.
+func (w *Webhook) Wants(event WebhookEvent) bool {
+	return len(w.Events) == 0 || slices.Contains(w.Events, event)
+}
+
+type CreateWebhookRequest struct {
+	URL    string         `json:"url" validate:"required,http_url,max=2000"`
+	Events []WebhookEvent `json:"events" validate:"unique,dive,oneof=task.created task.updated task.completed task.deleted task.due_soon task.overdue"`
+	Secret string         `json:"secret" validate:"required,min=16,max=200"`
+}
+
+type UpdateWebhookRequest struct {
+	URL    *string         `json:"url" validate:"omitempty,http_url,max=2000"`
+	Events *[]WebhookEvent `json:"events" validate:"omitempty,unique,dive,oneof=task.created task.updated task.completed task.deleted task.due_soon task.overdue"`
+	Secret *string         `json:"secret" validate:"omitempty,min=16,max=200"`
+}
+
+// WebhookPayload is the JSON body sent for an event.
+type WebhookPayload struct {
+	Event       WebhookEvent `json:"event"`
+	OccurredAt  time.Time    `json:"occurred_at"`
+	WorkspaceID int          `json:"workspace_id"`
+	ActorID     int          `json:"actor_id"`
+	Task        *Task        `json:"task"`
+	// Changes lists the changed fields, like the task history does
+	Changes []FieldChange `json:"changes"`
+	// MinutesBefore is how long before the due date a task.due_soon
+	// reminder is sent
+	MinutesBefore int `json:"minutes_before,omitempty"`
+}
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliverySucceeded DeliveryStatus = "succeeded"
+	DeliveryFailed    DeliveryStatus = "failed"
+)
+
+// WebhookDelivery is an event queued for a webhook. It stays pending, with
+// its next attempt further out after every failure, until it is accepted or
+// runs out of attempts, and is kept afterwards as the delivery log.
+type WebhookDelivery struct {
+	ID            int             `json:"id" gorm:"primaryKey"`
+	WebhookID     int             `json:"webhook_id" gorm:"not null;index"`
+	Event         WebhookEvent    `json:"event" gorm:"not null"`
+	Payload       json.RawMessage `json:"payload" gorm:"serializer:json;not null"`
+	Status        DeliveryStatus  `json:"status" gorm:"not null;index:idx_webhook_deliveries_due,priority:1"`
+	Attempts      int             `json:"attempts" gorm:"not null"`
+	NextAttemptAt time.Time       `json:"next_attempt_at" gorm:"not null;index:idx_webhook_deliveries_due,priority:2"`
+	// ResponseStatus is the HTTP status of the last attempt, 0 if the
+	// receiver couldn't be reached
+	ResponseStatus int `json:"response_status"`
+	// Error describes why the last attempt failed
+	Error       string     `json:"error"`
+	DeliveredAt *time.Time `json:"delivered_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// WebhookDeliveriesResponse is one page of a webhook's delivery log, newest
+// deliveries first.
+type WebhookDeliveriesResponse struct {
+	Deliveries []WebhookDelivery `json:"deliveries"`
+	Total      int64             `json:"total"`
+	Page       int               `json:"page"`
+	Size       int               `json:"size"`
+}
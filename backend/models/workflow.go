@@ -0,0 +1,85 @@
+package models
+
+import "slices"
+
+// Optional statuses that custom workflows can use next to the built-in ones.
+const (
+	TaskStatusReview    TaskStatus = "review"
+	TaskStatusBlocked   TaskStatus = "blocked"
+	TaskStatusCancelled TaskStatus = "cancelled"
+)
+
+// ResolvedStatuses are the statuses in which a task no longer holds up the
+// tasks that depend on it.
+var ResolvedStatuses = []TaskStatus{TaskStatusCompleted, TaskStatusCancelled}
+
+// Resolved reports whether the status is one of ResolvedStatuses.
+func (s TaskStatus) Resolved() bool {
+	return slices.Contains(ResolvedStatuses, s)
+}
+
+// Workflow defines the statuses the tasks of a workspace can have and which
+// status changes are allowed. Workspaces without one use DefaultWorkflow.
+type Workflow struct {
+	WorkspaceID int `json:"workspace_id" gorm:"primaryKey;autoIncrement:false"`
+	// Initial is the status of new tasks that don't ask for one
+	Initial  TaskStatus   `json:"initial" gorm:"not null"`
+	Statuses []TaskStatus `json:"statuses" gorm:"serializer:json;not null"`
+	// Transitions maps a status to the statuses tasks may move to from it
+	Transitions map[TaskStatus][]TaskStatus `json:"transitions" gorm:"serializer:json;not null"`
+	// Default is set on DefaultWorkflow, which is not stored
+	Default bool `json:"default" gorm:"-"`
+}
+
+// DefaultWorkflow lets tasks move freely between the built-in statuses.
+func DefaultWorkflow(workspaceID int) *Workflow {
+	statuses := []TaskStatus{TaskStatusPending, TaskStatusInProgress, TaskStatusCompleted}
+	transitions := make(map[TaskStatus][]TaskStatus, len(statuses))
+	for _, from := range statuses {
+		for _, to := range statuses {
+			if to != from {
+				transitions[from] = append(transitions[from], to)
+			}
+		}
+	}
+	return &Workflow{
+		WorkspaceID: workspaceID,
+		Initial:     TaskStatusPending,
+		Statuses:    statuses,
+		Transitions: transitions,
+		Default:     true,
+	}
+}
+
+// HasStatus reports whether status is part of the workflow.
+func (w *Workflow) HasStatus(status TaskStatus) bool {
+	return slices.Contains(w.Statuses, status)
+}
+
+// Next returns the statuses a task may move to from status.
+func (w *Workflow) Next(status TaskStatus) []TaskStatus {
+	next := w.Transitions[status]
+	if next == nil {
+		return []TaskStatus{}
+	}
+	return next
+}
+
+// Starts reports whether moving a task to status counts as starting it,
+// which blocked tasks may not do. That is every status but the initial
+// one, blocked and cancelled, so that custom statuses like review count too.
+func (w *Workflow) Starts(status TaskStatus) bool {
+	return status != w.Initial && status != TaskStatusBlocked && status != TaskStatusCancelled
+}
+
+// Allows reports whether a task may move from one status to another.
+// Keeping the current status is always allowed.
+func (w *Workflow) Allows(from, to TaskStatus) bool {
+	return from == to || slices.Contains(w.Transitions[from], to)
+}
+
+type UpdateWorkflowRequest struct {
+	Initial     TaskStatus                  `json:"initial" validate:"required"`
+	Statuses    []TaskStatus                `json:"statuses" validate:"required,min=1,max=20,dive,required,max=30,statusname"`
+	Transitions map[TaskStatus][]TaskStatus `json:"transitions" validate:"required"`
+}
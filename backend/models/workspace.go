@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// Workspace is a tenant that owns tasks. Every user has a personal
+// workspace and can be a member of any number of shared ones.
+type Workspace struct {
+	ID        int       `json:"id" gorm:"primaryKey"`
+	Name      string    `json:"name" gorm:"not null"`
+	OwnerID   int       `json:"owner_id" gorm:"index;not null"`
+	Personal  bool      `json:"personal" gorm:"not null;default:false"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// WorkspaceRole is a member's role within a workspace. What each role may
+// do is defined by the policy in the auth package.
+type WorkspaceRole string
+
+const (
+	RoleOwner  WorkspaceRole = "owner"
+	RoleAdmin  WorkspaceRole = "admin"
+	RoleEditor WorkspaceRole = "editor"
+	RoleViewer WorkspaceRole = "viewer"
+)
+
+type WorkspaceMember struct {
+	WorkspaceID int           `json:"workspace_id" gorm:"primaryKey;autoIncrement:false"`
+	UserID      int           `json:"user_id" gorm:"primaryKey;autoIncrement:false;index"`
+	Role        WorkspaceRole `json:"role" gorm:"not null;default:'editor'"`
+	CreatedAt   time.Time     `json:"created_at"`
+}
+
+type CreateWorkspaceRequest struct {
+	Name string `json:"name" validate:"required,min=1,max=100"`
+}
+
+// AddMemberRequest invites an existing user. Role defaults to editor; the
+// owner role cannot be granted.
+type AddMemberRequest struct {
+	Email string        `json:"email" validate:"required,email"`
+	Role  WorkspaceRole `json:"role" validate:"omitempty,oneof=admin editor viewer"`
+}
+
+type UpdateMemberRequest struct {
+	Role WorkspaceRole `json:"role" validate:"required,oneof=admin editor viewer"`
+}
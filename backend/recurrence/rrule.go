@@ -0,0 +1,309 @@
+// Package recurrence implements the subset of RFC 5545 recurrence rules used
+// by recurring tasks: FREQ=DAILY, WEEKLY or MONTHLY with INTERVAL, BYDAY,
+// COUNT and UNTIL. Weeks start on Monday.
+package recurrence
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidRule = errors.New("invalid recurrence rule")
+
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+)
+
+// Weekday is one BYDAY entry. N selects the Nth such day of the month,
+// counting from the end when negative; 0 means every such day.
+type Weekday struct {
+	N   int
+	Day time.Weekday
+}
+
+// Rule is a parsed recurrence rule. The first occurrence is the task's own
+// due date, like DTSTART.
+type Rule struct {
+	Freq     Frequency
+	Interval int
+	ByDay    []Weekday
+	// Count limits the series to this many occurrences; 0 means no limit
+	Count int
+	// Until is the last instant an occurrence may fall on
+	Until *time.Time
+}
+
+var dayCodes = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// maxSteps bounds the search for the next occurrence, so rules that can
+// never match again, like FREQ=DAILY;INTERVAL=7;BYDAY=MO,TU, end instead of
+// looping forever.
+const maxSteps = 1000
+
+// Parse parses a rule such as "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE". An
+// "RRULE:" prefix is accepted.
+func Parse(value string) (*Rule, error) {
+	value = strings.TrimSpace(value)
+	if len(value) >= 6 && strings.EqualFold(value[:6], "RRULE:") {
+		value = value[6:]
+	}
+	if value == "" {
+		return nil, fmt.Errorf("%w: empty rule", ErrInvalidRule)
+	}
+
+	rule := &Rule{Interval: 1}
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(value, ";") {
+		name, arg, ok := strings.Cut(part, "=")
+		name = strings.ToUpper(strings.TrimSpace(name))
+		arg = strings.ToUpper(strings.TrimSpace(arg))
+		if !ok || arg == "" {
+			return nil, fmt.Errorf("%w: malformed part %q", ErrInvalidRule, part)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("%w: %s given twice", ErrInvalidRule, name)
+		}
+		seen[name] = true
+
+		var err error
+		switch name {
+		case "FREQ":
+			rule.Freq = Frequency(arg)
+			if rule.Freq != Daily && rule.Freq != Weekly && rule.Freq != Monthly {
+				err = fmt.Errorf("%w: FREQ must be DAILY, WEEKLY or MONTHLY", ErrInvalidRule)
+			}
+		case "INTERVAL":
+			rule.Interval, err = positive(name, arg)
+		case "COUNT":
+			rule.Count, err = positive(name, arg)
+		case "UNTIL":
+			rule.Until, err = parseUntil(arg)
+		case "BYDAY":
+			rule.ByDay, err = parseByDay(arg)
+		default:
+			err = fmt.Errorf("%w: unsupported part %s", ErrInvalidRule, name)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if rule.Freq == "" {
+		return nil, fmt.Errorf("%w: FREQ is required", ErrInvalidRule)
+	}
+	if rule.Count > 0 && rule.Until != nil {
+		return nil, fmt.Errorf("%w: use either COUNT or UNTIL, not both", ErrInvalidRule)
+	}
+	if rule.Freq != Monthly && slices.ContainsFunc(rule.ByDay, func(day Weekday) bool { return day.N != 0 }) {
+		return nil, fmt.Errorf("%w: numbered BYDAY days need FREQ=MONTHLY", ErrInvalidRule)
+	}
+	return rule, nil
+}
+
+func positive(name, arg string) (int, error) {
+	n, err := strconv.Atoi(arg)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("%w: %s must be a positive number", ErrInvalidRule, name)
+	}
+	return n, nil
+}
+
+func parseUntil(arg string) (*time.Time, error) {
+	if until, err := time.Parse("20060102T150405Z", arg); err == nil {
+		return &until, nil
+	}
+	until, err := time.Parse("20060102", arg)
+	if err != nil {
+		return nil, fmt.Errorf("%w: UNTIL must look like 20261231 or 20261231T235959Z", ErrInvalidRule)
+	}
+	// A date includes the whole day
+	until = until.Add(24*time.Hour - time.Second)
+	return &until, nil
+}
+
+func parseByDay(arg string) ([]Weekday, error) {
+	var days []Weekday
+	for _, entry := range strings.Split(arg, ",") {
+		entry = strings.TrimSpace(entry)
+		if len(entry) < 2 {
+			return nil, fmt.Errorf("%w: unknown BYDAY day %q", ErrInvalidRule, entry)
+		}
+
+		code, number := entry[len(entry)-2:], entry[:len(entry)-2]
+		day, ok := dayCodes[code]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown BYDAY day %q", ErrInvalidRule, entry)
+		}
+		n := 0
+		if number != "" {
+			var err error
+			if n, err = strconv.Atoi(number); err != nil || n == 0 || n < -5 || n > 5 {
+				return nil, fmt.Errorf("%w: unknown BYDAY day %q", ErrInvalidRule, entry)
+			}
+		}
+
+		weekday := Weekday{N: n, Day: day}
+		if !slices.Contains(days, weekday) {
+			days = append(days, weekday)
+		}
+	}
+	return days, nil
+}
+
+// String returns the rule in canonical form.
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			days[i] = day.String()
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	return strings.Join(parts, ";")
+}
+
+func (d Weekday) String() string {
+	code := strings.ToUpper(d.Day.String()[:2])
+	if d.N != 0 {
+		return strconv.Itoa(d.N) + code
+	}
+	return code
+}
+
+// Next returns the occurrence following prev, which is occurrence number
+// n of the series counting from 1. It reports false when the series has
+// ended. Occurrences keep the time of day of prev.
+//
+// Starting from any earlier occurrence gives the same result as starting
+// from the first, so the series doesn't need to remember where it began.
+func (r *Rule) Next(prev time.Time, n int) (time.Time, bool) {
+	if r.Count > 0 && n >= r.Count {
+		return time.Time{}, false
+	}
+
+	var next time.Time
+	var ok bool
+	switch r.Freq {
+	case Daily:
+		next, ok = r.nextDaily(prev)
+	case Weekly:
+		next, ok = r.nextWeekly(prev)
+	case Monthly:
+		next, ok = r.nextMonthly(prev)
+	}
+	if !ok || (r.Until != nil && next.After(*r.Until)) {
+		return time.Time{}, false
+	}
+	return next, true
+}
+
+func (r *Rule) nextDaily(prev time.Time) (time.Time, bool) {
+	for step := 1; step <= maxSteps; step++ {
+		candidate := prev.AddDate(0, 0, step*r.Interval)
+		if len(r.ByDay) == 0 || r.onDay(candidate.Weekday()) {
+			return candidate, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func (r *Rule) nextWeekly(prev time.Time) (time.Time, bool) {
+	days := []time.Weekday{prev.Weekday()}
+	if len(r.ByDay) > 0 {
+		days = days[:0]
+		for _, day := range r.ByDay {
+			days = append(days, day.Day)
+		}
+		slices.SortFunc(days, func(a, b time.Weekday) int { return mondayIndex(a) - mondayIndex(b) })
+	}
+
+	monday := prev.AddDate(0, 0, -mondayIndex(prev.Weekday()))
+	for step := 0; step <= maxSteps; step++ {
+		week := monday.AddDate(0, 0, 7*step*r.Interval)
+		for _, day := range days {
+			if candidate := week.AddDate(0, 0, mondayIndex(day)); candidate.After(prev) {
+				return candidate, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+func (r *Rule) nextMonthly(prev time.Time) (time.Time, bool) {
+	for step := 0; step <= maxSteps; step++ {
+		// Day 1 avoids normalisation: January 31 plus one month is March 3
+		month := time.Date(prev.Year(), prev.Month()+time.Month(step*r.Interval), 1,
+			prev.Hour(), prev.Minute(), prev.Second(), prev.Nanosecond(), prev.Location())
+		for _, candidate := range r.monthDays(month, prev.Day()) {
+			if candidate.After(prev) {
+				return candidate, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// monthDays returns the occurrences within the month starting at first in
+// chronological order. Without BYDAY that is the given day of the month,
+// which months too short for it skip.
+func (r *Rule) monthDays(first time.Time, day int) []time.Time {
+	length := first.AddDate(0, 1, -1).Day()
+	if len(r.ByDay) == 0 {
+		if day > length {
+			return nil
+		}
+		return []time.Time{first.AddDate(0, 0, day-1)}
+	}
+
+	var days []time.Time
+	for d := 1; d <= length; d++ {
+		candidate := first.AddDate(0, 0, d-1)
+		for _, byDay := range r.ByDay {
+			if byDay.Day != candidate.Weekday() {
+				continue
+			}
+			// Position of this weekday in the month from the start and end
+			fromStart, fromEnd := (d-1)/7+1, -((length-d)/7 + 1)
+			if byDay.N == 0 || byDay.N == fromStart || byDay.N == fromEnd {
+				days = append(days, candidate)
+				break
+			}
+		}
+	}
+	return days
+}
+
+func (r *Rule) onDay(day time.Weekday) bool {
+	return slices.ContainsFunc(r.ByDay, func(byDay Weekday) bool { return byDay.Day == day })
+}
+
+// mondayIndex numbers weekdays from Monday = 0.
+func mondayIndex(day time.Weekday) int {
+	return (int(day) + 6) % 7
+}
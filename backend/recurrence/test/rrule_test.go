@@ -0,0 +1,133 @@
+package recurrence
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"task/backend/recurrence"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 9, 30, 0, 0, time.UTC)
+}
+
+// occurrences returns up to limit occurrences following start, which is
+// occurrence 1.
+func occurrences(t *testing.T, value string, start time.Time, limit int) []time.Time {
+	rule, err := recurrence.Parse(value)
+	require.NoError(t, err)
+
+	var dates []time.Time
+	prev := start
+	for n := 1; len(dates) < limit; n++ {
+		next, ok := rule.Next(prev, n)
+		if !ok {
+			break
+		}
+		dates = append(dates, next)
+		prev = next
+	}
+	return dates
+}
+
+func TestParse_Canonical(t *testing.T) {
+	rule, err := recurrence.Parse("RRULE:freq=weekly;byday=MO,we;interval=2;COUNT=4")
+	require.NoError(t, err)
+	assert.Equal(t, recurrence.Weekly, rule.Freq)
+	assert.Equal(t, 2, rule.Interval)
+	assert.Equal(t, "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE;COUNT=4", rule.String())
+
+	rule, err = recurrence.Parse("FREQ=MONTHLY;BYDAY=-1FR;UNTIL=20261231")
+	require.NoError(t, err)
+	assert.Equal(t, []recurrence.Weekday{{N: -1, Day: time.Friday}}, rule.ByDay)
+	assert.Equal(t, "FREQ=MONTHLY;BYDAY=-1FR;UNTIL=20261231T235959Z", rule.String())
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, value := range []string{
+		"",
+		"INTERVAL=2",
+		"FREQ=YEARLY",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;COUNT=x",
+		"FREQ=DAILY;FREQ=WEEKLY",
+		"FREQ=WEEKLY;BYDAY=XX",
+		"FREQ=WEEKLY;BYDAY=1MO",
+		"FREQ=MONTHLY;BYDAY=6MO",
+		"FREQ=DAILY;COUNT=3;UNTIL=20261231",
+		"FREQ=DAILY;UNTIL=tomorrow",
+		"FREQ=DAILY;BYMONTH=1",
+		"FREQ",
+	} {
+		_, err := recurrence.Parse(value)
+		assert.True(t, errors.Is(err, recurrence.ErrInvalidRule), value)
+	}
+}
+
+func TestNext_Daily(t *testing.T) {
+	start := date(2026, time.October, 30)
+	assert.Equal(t, []time.Time{
+		date(2026, time.November, 1),
+		date(2026, time.November, 3),
+		date(2026, time.November, 5),
+	}, occurrences(t, "FREQ=DAILY;INTERVAL=2", start, 3))
+
+	// BYDAY limits daily rules to working days
+	friday := date(2026, time.October, 16)
+	assert.Equal(t, []time.Time{
+		date(2026, time.October, 19),
+		date(2026, time.October, 20),
+	}, occurrences(t, "FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR", friday, 2))
+}
+
+func TestNext_Weekly(t *testing.T) {
+	// Thursday, so the rest of the week comes first
+	start := date(2026, time.October, 15)
+	assert.Equal(t, []time.Time{
+		date(2026, time.October, 16),
+		date(2026, time.October, 26),
+		date(2026, time.October, 30),
+		date(2026, time.November, 9),
+	}, occurrences(t, "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR", start, 4))
+
+	// Without BYDAY the weekday of the due date repeats
+	assert.Equal(t, []time.Time{
+		date(2026, time.October, 22),
+		date(2026, time.October, 29),
+	}, occurrences(t, "FREQ=WEEKLY", start, 2))
+}
+
+func TestNext_Monthly(t *testing.T) {
+	// Months without a 31st are skipped rather than clamped
+	start := date(2026, time.January, 31)
+	assert.Equal(t, []time.Time{
+		date(2026, time.March, 31),
+		date(2026, time.May, 31),
+		date(2026, time.July, 31),
+	}, occurrences(t, "FREQ=MONTHLY", start, 3))
+
+	// Last Friday and first Monday of each month
+	assert.Equal(t, []time.Time{
+		date(2026, time.October, 30),
+		date(2026, time.November, 2),
+		date(2026, time.November, 27),
+		date(2026, time.December, 7),
+	}, occurrences(t, "FREQ=MONTHLY;BYDAY=-1FR,1MO", date(2026, time.October, 5), 4))
+}
+
+func TestNext_EndsWithCountAndUntil(t *testing.T) {
+	start := date(2026, time.October, 1)
+	assert.Len(t, occurrences(t, "FREQ=DAILY;COUNT=3", start, 10), 2)
+	assert.Len(t, occurrences(t, "FREQ=DAILY;COUNT=1", start, 10), 0)
+	assert.Equal(t, []time.Time{
+		date(2026, time.October, 2),
+		date(2026, time.October, 3),
+	}, occurrences(t, "FREQ=DAILY;UNTIL=20261003", start, 10))
+
+	// Rules that can never match again end too
+	assert.Empty(t, occurrences(t, "FREQ=DAILY;INTERVAL=7;BYDAY=TU", start, 10))
+}
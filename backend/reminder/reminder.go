@@ -0,0 +1,124 @@
+// Package reminder reminds of tasks that are about to be due or overdue.
+// Each reminder window of a task is handled once per due date: passed
+// windows are recorded in the store, so restarts and other servers don't
+// send them again, and moving a task's due date opens its windows again.
+package reminder
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"task/backend/events"
+	"task/backend/models"
+	"task/backend/repository"
+)
+
+// maxTaskOffset is the earliest reminder a task can ask for, as allowed by
+// models.CreateTaskRequest.
+const maxTaskOffset = 7 * 24 * time.Hour
+
+// Scheduler publishes events.TaskReminder for tasks whose reminder windows
+// have opened.
+type Scheduler struct {
+	Bus       *events.Bus
+	Reminders repository.ReminderRepository
+	// Windows are how long before their due date tasks without reminders
+	// of their own are reminded of
+	Windows []time.Duration
+}
+
+func NewScheduler(bus *events.Bus, reminders repository.ReminderRepository, windows []time.Duration) *Scheduler {
+	return &Scheduler{Bus: bus, Reminders: reminders, Windows: windows}
+}
+
+// SendDue sends the reminders that are due at now and returns how many it
+// sent. A task that has several windows open at once, e.g. because it was
+// created shortly before its due date, gets a single reminder for the
+// closest one. Failing tasks don't hold up the others; their errors are
+// returned together.
+func (s *Scheduler) SendDue(now time.Time) (int, error) {
+	horizon := maxTaskOffset
+	for _, window := range s.Windows {
+		horizon = max(horizon, window)
+	}
+	tasks, err := s.Reminders.Pending(now.Add(horizon))
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	var errs []error
+	for _, task := range tasks {
+		ok, err := s.remind(task, now)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("task %d: %w", task.ID, err))
+		} else if ok {
+			sent++
+		}
+	}
+	return sent, errors.Join(errs...)
+}
+
+// remind records the windows of task that are open at now and haven't been
+// recorded yet, and reminds of the closest one unless a closer one was
+// reminded of before. It reports whether it sent a reminder.
+func (s *Scheduler) remind(task models.Task, now time.Time) (bool, error) {
+	dueDate := *task.DueDate
+	recorded, err := s.Reminders.Recorded(task.ID, dueDate)
+	if err != nil {
+		return false, err
+	}
+
+	var open []int
+	for _, minutes := range s.offsets(task) {
+		if !now.Before(dueDate.Add(-time.Duration(minutes)*time.Minute)) && !slices.Contains(recorded, minutes) {
+			open = append(open, minutes)
+		}
+	}
+	if len(open) == 0 {
+		return false, nil
+	}
+	closest := slices.Min(open)
+	notify := len(recorded) == 0 || closest < slices.Min(recorded)
+
+	err = s.Bus.Transaction(func(tx *repository.Store) error {
+		for _, minutes := range open {
+			reminder := models.TaskReminder{
+				TaskID:        task.ID,
+				DueDate:       dueDate,
+				MinutesBefore: minutes,
+				Notified:      notify && minutes == closest,
+				CreatedAt:     now,
+			}
+			if err := tx.Reminders.Record(&reminder); err != nil {
+				return err
+			}
+		}
+		if !notify {
+			return nil
+		}
+		return s.Bus.Publish(tx, events.New(task.WorkspaceID, 0, events.TaskReminder{Task: task, MinutesBefore: closest}))
+	})
+	if errors.Is(err, repository.ErrReminderRecorded) {
+		// Another server got there first
+		return false, nil
+	}
+	return notify && err == nil, err
+}
+
+// offsets returns the windows of task in minutes before its due date,
+// including 0 for becoming overdue.
+func (s *Scheduler) offsets(task models.Task) []int {
+	offsets := []int{0}
+	if len(task.Reminders) > 0 {
+		offsets = append(offsets, task.Reminders...)
+	} else {
+		for _, window := range s.Windows {
+			offsets = append(offsets, int(window/time.Minute))
+		}
+	}
+	slices.Sort(offsets)
+	return slices.Compact(offsets)
+}
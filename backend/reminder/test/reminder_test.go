@@ -0,0 +1,139 @@
+package reminder
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"task/backend/database"
+	"task/backend/events"
+	"task/backend/models"
+	"task/backend/reminder"
+	"task/backend/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm/logger"
+)
+
+func openSQLite(t *testing.T) *repository.Store {
+	db, err := database.Open(database.DriverSQLite, ":memory:")
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	db.Logger = logger.Default.LogMode(logger.Silent)
+	require.NoError(t, database.NewMigrator(db).Up())
+	return repository.NewGormStore(db)
+}
+
+func createTask(t *testing.T, store *repository.Store, title string, dueDate time.Time) models.Task {
+	task := models.Task{
+		Title:       title,
+		Status:      models.TaskStatusPending,
+		Priority:    models.TaskPriorityMedium,
+		DueDate:     &dueDate,
+		OwnerID:     1,
+		WorkspaceID: 1,
+	}
+	require.NoError(t, store.Tasks.Create(&task))
+	return task
+}
+
+// reminders relays the outbox and returns the reminders in it.
+func reminders(t *testing.T, bus *events.Bus) []events.TaskReminder {
+	var relayed []events.TaskReminder
+	bus.SubscribeAsync(func(event events.Event) {
+		if payload, ok := event.Payload.(events.TaskReminder); ok {
+			relayed = append(relayed, payload)
+		}
+	})
+	_, err := bus.Relay()
+	require.NoError(t, err)
+	return relayed
+}
+
+func TestScheduler(t *testing.T) {
+	stores := map[string]func(t *testing.T) *repository.Store{
+		"memory": func(*testing.T) *repository.Store { return repository.NewMemoryStore() },
+		"sqlite": openSQLite,
+	}
+	for name, open := range stores {
+		t.Run(name, func(t *testing.T) {
+			t.Run("ConcurrentServers", func(t *testing.T) {
+				store := open(t)
+				bus := events.NewBus(store)
+				now := time.Now().Truncate(time.Second)
+				task := createTask(t, store, "write-docs", now.Add(30*time.Minute))
+
+				// Every server runs its own scheduler on the shared store
+				var wg sync.WaitGroup
+				var mu sync.Mutex
+				total := 0
+				for range 4 {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						sent, err := reminder.NewScheduler(bus, store.Reminders, []time.Duration{time.Hour}).SendDue(now)
+						assert.NoError(t, err)
+						mu.Lock()
+						total += sent
+						mu.Unlock()
+					}()
+				}
+				wg.Wait()
+
+				assert.Equal(t, 1, total)
+				relayed := reminders(t, bus)
+				require.Len(t, relayed, 1)
+				assert.Equal(t, task.ID, relayed[0].Task.ID)
+				assert.Equal(t, 60, relayed[0].MinutesBefore)
+			})
+
+			t.Run("Pending", func(t *testing.T) {
+				store := open(t)
+				bus := events.NewBus(store)
+				now := time.Now().Truncate(time.Second)
+				later := createTask(t, store, "later", now.Add(2*time.Hour))
+				sooner := createTask(t, store, "sooner", now.Add(time.Hour))
+				createTask(t, store, "next-month", now.Add(30*24*time.Hour))
+
+				pending, err := store.Reminders.Pending(now.Add(3 * time.Hour))
+				require.NoError(t, err)
+				require.Len(t, pending, 2)
+				assert.Equal(t, []int{sooner.ID, later.ID}, []int{pending[0].ID, pending[1].ID})
+
+				// Tasks drop out once they have been reminded of being overdue
+				scheduler := reminder.NewScheduler(bus, store.Reminders, nil)
+				sent, err := scheduler.SendDue(now.Add(90 * time.Minute))
+				require.NoError(t, err)
+				assert.Equal(t, 1, sent)
+				pending, err = store.Reminders.Pending(now.Add(3 * time.Hour))
+				require.NoError(t, err)
+				require.Len(t, pending, 1)
+				assert.Equal(t, later.ID, pending[0].ID)
+
+				err = store.Reminders.Record(&models.TaskReminder{TaskID: sooner.ID, DueDate: *sooner.DueDate, MinutesBefore: 0})
+				assert.ErrorIs(t, err, repository.ErrReminderRecorded)
+			})
+
+			t.Run("ResolvedTasks", func(t *testing.T) {
+				store := open(t)
+				now := time.Now().Truncate(time.Second)
+				active := createTask(t, store, "active", now.Add(time.Hour))
+				for _, status := range []models.TaskStatus{models.TaskStatusCompleted, models.TaskStatusCancelled} {
+					dueDate := now.Add(time.Hour)
+					task := models.Task{Title: string(status), Status: status, Priority: models.TaskPriorityMedium, DueDate: &dueDate, OwnerID: 1, WorkspaceID: 1}
+					require.NoError(t, store.Tasks.Create(&task))
+				}
+
+				// Completed and cancelled tasks get no reminders
+				pending, err := store.Reminders.Pending(now.Add(2 * time.Hour))
+				require.NoError(t, err)
+				require.Len(t, pending, 1)
+				assert.Equal(t, active.ID, pending[0].ID)
+			})
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"time"
+
+	"task/backend/models"
+)
+
+// APITokenRepository abstracts storage of personal API tokens.
+type APITokenRepository interface {
+	Create(token *models.APIToken) error
+	GetByHash(hash string) (*models.APIToken, error)
+	ListByUser(userID int) ([]models.APIToken, error)
+	// Delete removes the user's token, returning ErrNotFound if the user
+	// has no token with that ID.
+	Delete(userID, id int) error
+	TouchLastUsed(id int, at time.Time) error
+}
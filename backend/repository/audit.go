@@ -0,0 +1,12 @@
+package repository
+
+import "task/backend/models"
+
+// AuditRepository stores the change history of tasks. It only ever adds
+// entries.
+type AuditRepository interface {
+	Record(entry *models.AuditEntry) error
+	// ListForTask returns one page of the task's entries, newest first,
+	// together with the total number of entries.
+	ListForTask(taskID, page, size int) ([]models.AuditEntry, int64, error)
+}
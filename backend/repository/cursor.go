@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"task/backend/models"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// cursor is the JSON payload of an opaque pagination token. It holds the ID
+// and the sort key values of the task at the edge of a page. A nil DueDate
+// is meaningful: undated tasks sort after all dated ones.
+type cursor struct {
+	Sort      string              `json:"s,omitempty"`
+	ID        int                 `json:"id"`
+	Title     string              `json:"title,omitempty"`
+	Status    models.TaskStatus   `json:"status,omitempty"`
+	Priority  models.TaskPriority `json:"priority,omitempty"`
+	DueDate   *time.Time          `json:"due_date,omitempty"`
+	CreatedAt *time.Time          `json:"created_at,omitempty"`
+	UpdatedAt *time.Time          `json:"updated_at,omitempty"`
+}
+
+// EncodeCursor returns a token addressing the position of task in a listing
+// ordered by keys.
+func EncodeCursor(task models.Task, keys []SortKey) string {
+	c := cursor{Sort: sortSignature(keys), ID: task.ID}
+	for _, key := range keys {
+		switch key.Field {
+		case SortTitle:
+			c.Title = task.Title
+		case SortStatus:
+			c.Status = task.Status
+		case SortPriority:
+			c.Priority = task.Priority
+			c.DueDate = task.DueDate
+		case SortDueDate:
+			c.DueDate = task.DueDate
+		case SortCreatedAt:
+			createdAt := task.CreatedAt
+			c.CreatedAt = &createdAt
+		case SortUpdatedAt:
+			updatedAt := task.UpdatedAt
+			c.UpdatedAt = &updatedAt
+		}
+	}
+
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses a token created by EncodeCursor into the task it
+// points at. Only the ID and the fields used by keys are set. Tokens created
+// for a different sort order are rejected.
+func DecodeCursor(token string, keys []SortKey) (*models.Task, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID <= 0 {
+		return nil, ErrInvalidCursor
+	}
+	if c.Sort != sortSignature(keys) {
+		return nil, ErrInvalidCursor
+	}
+
+	task := &models.Task{
+		ID:       c.ID,
+		Title:    c.Title,
+		Status:   c.Status,
+		Priority: c.Priority,
+		DueDate:  c.DueDate,
+	}
+	for _, key := range keys {
+		switch key.Field {
+		case SortCreatedAt:
+			if c.CreatedAt == nil {
+				return nil, ErrInvalidCursor
+			}
+			task.CreatedAt = *c.CreatedAt
+		case SortUpdatedAt:
+			if c.UpdatedAt == nil {
+				return nil, ErrInvalidCursor
+			}
+			task.UpdatedAt = *c.UpdatedAt
+		}
+	}
+	return task, nil
+}
+
+// sortSignature is the canonical form of keys as accepted by ParseSort.
+func sortSignature(keys []SortKey) string {
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = string(key.Field)
+		if key.Desc {
+			parts[i] = "-" + parts[i]
+		}
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// translateError maps driver-specific errors to repository errors. Unique
+// violations are normalised to gorm.ErrDuplicatedKey through the dialector,
+// even if the connection was opened without TranslateError.
+func translateError(db *gorm.DB, err error) error {
+	if err == nil {
+		return nil
+	}
+	if translator, ok := db.Dialector.(gorm.ErrorTranslator); ok {
+		err = translator.Translate(err)
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"time"
+
+	"task/backend/models"
+
+	"gorm.io/gorm"
+)
+
+type gormAPITokenRepository struct {
+	db *gorm.DB
+}
+
+// NewGormAPITokenRepository returns an APITokenRepository backed by GORM.
+func NewGormAPITokenRepository(db *gorm.DB) APITokenRepository {
+	return &gormAPITokenRepository{db: db}
+}
+
+func (r *gormAPITokenRepository) Create(token *models.APIToken) error {
+	return translateError(r.db, r.db.Create(token).Error)
+}
+
+func (r *gormAPITokenRepository) GetByHash(hash string) (*models.APIToken, error) {
+	var token models.APIToken
+	if err := r.db.Where("token_hash = ?", hash).First(&token).Error; err != nil {
+		return nil, translateError(r.db, err)
+	}
+	return &token, nil
+}
+
+func (r *gormAPITokenRepository) ListByUser(userID int) ([]models.APIToken, error) {
+	tokens := make([]models.APIToken, 0)
+	if err := r.db.Where("user_id = ?", userID).Order("id").Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+func (r *gormAPITokenRepository) Delete(userID, id int) error {
+	result := r.db.Where("user_id = ?", userID).Delete(&models.APIToken{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *gormAPITokenRepository) TouchLastUsed(id int, at time.Time) error {
+	return r.db.Model(&models.APIToken{}).Where("id = ?", id).Update("last_used_at", at).Error
+}
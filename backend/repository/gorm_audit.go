@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"task/backend/models"
+
+	"gorm.io/gorm"
+)
+
+type gormAuditRepository struct {
+	db *gorm.DB
+}
+
+// NewGormAuditRepository returns an AuditRepository backed by a GORM
+// connection.
+func NewGormAuditRepository(db *gorm.DB) AuditRepository {
+	return &gormAuditRepository{db: db}
+}
+
+func (r *gormAuditRepository) Record(entry *models.AuditEntry) error {
+	return r.db.Create(entry).Error
+}
+
+func (r *gormAuditRepository) ListForTask(taskID, page, size int) ([]models.AuditEntry, int64, error) {
+	query := r.db.Model(&models.AuditEntry{}).Where("task_id = ?", taskID)
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	entries := make([]models.AuditEntry, 0)
+	if err := query.Order("id DESC").Offset((page - 1) * size).Limit(size).Find(&entries).Error; err != nil {
+		return nil, 0, err
+	}
+	return entries, total, nil
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"task/backend/models"
+
+	"gorm.io/gorm"
+)
+
+type gormOutboxRepository struct {
+	db *gorm.DB
+}
+
+// NewGormOutboxRepository returns an OutboxRepository backed by GORM.
+func NewGormOutboxRepository(db *gorm.DB) OutboxRepository {
+	return &gormOutboxRepository{db: db}
+}
+
+func (r *gormOutboxRepository) Add(event *models.OutboxEvent) error {
+	return r.db.Create(event).Error
+}
+
+func (r *gormOutboxRepository) Pending(limit int) ([]models.OutboxEvent, error) {
+	events := make([]models.OutboxEvent, 0)
+	if err := r.db.Order("id").Limit(limit).Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (r *gormOutboxRepository) Remove(ids []int) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.Delete(&models.OutboxEvent{}, ids).Error
+}
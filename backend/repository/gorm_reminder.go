@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"task/backend/models"
+
+	"gorm.io/gorm"
+)
+
+type gormReminderRepository struct {
+	db *gorm.DB
+}
+
+// NewGormReminderRepository returns a ReminderRepository backed by GORM.
+func NewGormReminderRepository(db *gorm.DB) ReminderRepository {
+	return &gormReminderRepository{db: db}
+}
+
+func (r *gormReminderRepository) Pending(before time.Time) ([]models.Task, error) {
+	overdue := r.db.Model(&models.TaskReminder{}).Select("1").
+		Where("task_reminders.task_id = tasks.id AND task_reminders.due_date = tasks.due_date AND task_reminders.minutes_before = 0")
+
+	tasks := make([]models.Task, 0)
+	err := r.db.Preload("Tags", orderTags).
+		Where("due_date <= ? AND status NOT IN ?", before, models.ResolvedStatuses).
+		Where("NOT EXISTS (?)", overdue).
+		Order("due_date, id").
+		Find(&tasks).Error
+	if err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+func (r *gormReminderRepository) Recorded(taskID int, dueDate time.Time) ([]int, error) {
+	minutes := make([]int, 0)
+	err := r.db.Model(&models.TaskReminder{}).
+		Where("task_id = ? AND due_date = ?", taskID, dueDate).
+		Order("minutes_before").
+		Pluck("minutes_before", &minutes).Error
+	if err != nil {
+		return nil, err
+	}
+	return minutes, nil
+}
+
+func (r *gormReminderRepository) Record(reminder *models.TaskReminder) error {
+	if err := r.db.Create(reminder).Error; err != nil {
+		if err = translateError(r.db, err); errors.Is(err, gorm.ErrDuplicatedKey) {
+			return ErrReminderRecorded
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"errors"
+
+	"task/backend/models"
+
+	"gorm.io/gorm"
+)
+
+type gormTagRepository struct {
+	db *gorm.DB
+}
+
+// NewGormTagRepository returns a TagRepository backed by GORM.
+func NewGormTagRepository(db *gorm.DB) TagRepository {
+	return &gormTagRepository{db: db}
+}
+
+func (r *gormTagRepository) Create(tag *models.Tag) error {
+	if err := r.db.Create(tag).Error; err != nil {
+		if err = translateError(r.db, err); errors.Is(err, gorm.ErrDuplicatedKey) {
+			return ErrDuplicateTag
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *gormTagRepository) GetByID(id int) (*models.Tag, error) {
+	var tag models.Tag
+	if err := r.db.First(&tag, id).Error; err != nil {
+		return nil, translateError(r.db, err)
+	}
+	return &tag, nil
+}
+
+func (r *gormTagRepository) GetByNames(workspaceID int, names []string) ([]models.Tag, error) {
+	tags := make([]models.Tag, 0, len(names))
+	if len(names) == 0 {
+		return tags, nil
+	}
+	err := r.db.Where("workspace_id = ? AND name IN ?", workspaceID, names).Order("name").Find(&tags).Error
+	if err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+func (r *gormTagRepository) List(workspaceID int) ([]models.Tag, error) {
+	tags := make([]models.Tag, 0)
+	if err := r.db.Where("workspace_id = ?", workspaceID).Order("name").Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+func (r *gormTagRepository) Update(tag *models.Tag) error {
+	if err := r.db.Save(tag).Error; err != nil {
+		if err = translateError(r.db, err); errors.Is(err, gorm.ErrDuplicatedKey) {
+			return ErrDuplicateTag
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *gormTagRepository) Delete(tag *models.Tag) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM task_tags WHERE tag_id = ?", tag.ID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(tag).Error
+	})
+}
@@ -0,0 +1,593 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"task/backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type gormTaskRepository struct {
+	db *gorm.DB
+}
+
+// NewGormTaskRepository returns a TaskRepository backed by a GORM connection.
+func NewGormTaskRepository(db *gorm.DB) TaskRepository {
+	return &gormTaskRepository{db: db}
+}
+
+func (r *gormTaskRepository) Create(task *models.Task) error {
+	// Link the tags without writing the tag rows themselves
+	if err := r.db.Omit("Tags.*").Create(task).Error; err != nil {
+		if err = translateError(r.db, err); errors.Is(err, gorm.ErrDuplicatedKey) {
+			return ErrDuplicateTitle
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *gormTaskRepository) GetByTitle(workspaceID int, title string) (*models.Task, error) {
+	var task models.Task
+	if err := r.withTags().Where("workspace_id = ? AND title = ?", workspaceID, title).First(&task).Error; err != nil {
+		return nil, translateError(r.db, err)
+	}
+	return r.loaded(task)
+}
+
+func (r *gormTaskRepository) GetByID(id int) (*models.Task, error) {
+	var task models.Task
+	if err := r.withTags().First(&task, id).Error; err != nil {
+		return nil, translateError(r.db, err)
+	}
+	return r.loaded(task)
+}
+
+func (r *gormTaskRepository) List(filter TaskFilter) (*TaskPage, error) {
+	query := r.db.Model(&models.Task{})
+	if filter.Deleted {
+		query = r.db.Unscoped().Model(&models.Task{}).Where("deleted_at IS NOT NULL")
+	}
+
+	if filter.WorkspaceID != 0 {
+		query = query.Where("workspace_id = ?", filter.WorkspaceID)
+	}
+
+	if filter.Title != "" {
+		query = query.Where("title = ?", filter.Title)
+	}
+
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	if filter.Priority != "" {
+		query = query.Where("priority = ?", filter.Priority)
+	}
+
+	if filter.SeriesID != 0 {
+		query = query.Where("series_id = ?", filter.SeriesID)
+	}
+
+	if filter.DueBefore != nil {
+		query = query.Where("due_date <= ?", *filter.DueBefore)
+	}
+
+	if filter.Search != "" {
+		// LOWER/LIKE instead of ILIKE so the query works on every driver
+		query = query.Where("LOWER(title) LIKE ?", "%"+strings.ToLower(filter.Search)+"%")
+	}
+
+	if len(filter.Tags) > 0 {
+		tagged := r.db.Table("task_tags").
+			Select("task_tags.task_id").
+			Joins("JOIN tags ON tags.id = task_tags.tag_id").
+			Where("tags.name IN ?", filter.Tags)
+		if filter.AllTags {
+			tagged = tagged.Group("task_tags.task_id").Having("COUNT(DISTINCT tags.name) = ?", len(filter.Tags))
+		}
+		query = query.Where("id IN (?)", tagged)
+	}
+
+	page := &TaskPage{}
+
+	if !filter.SkipCount {
+		var total int64
+		if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+			return nil, err
+		}
+		page.Total = &total
+	}
+
+	columns := orderColumns(filter.Sort)
+	backward := filter.Before != nil
+
+	switch {
+	case filter.After != nil:
+		sql, args := keysetCondition(columns, *filter.After, false)
+		query = query.Where(sql, args...)
+	case filter.Before != nil:
+		sql, args := keysetCondition(columns, *filter.Before, true)
+		query = query.Where(sql, args...)
+	default:
+		query = query.Offset((filter.Page - 1) * filter.Size)
+	}
+
+	// Pages before a cursor are read in reverse and flipped afterwards
+	for _, column := range columns {
+		query = query.Order(column.order(backward))
+	}
+
+	// One extra row tells whether another page follows
+	tasks := make([]models.Task, 0)
+	if err := query.Preload("Tags", orderTags).Limit(filter.Size + 1).Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+
+	more := len(tasks) > filter.Size
+	if more {
+		tasks = tasks[:filter.Size]
+	}
+	if err := r.markBlocked(tasks); err != nil {
+		return nil, err
+	}
+
+	if backward {
+		slices.Reverse(tasks)
+		page.HasPrev, page.HasNext = more, true
+	} else {
+		page.HasPrev, page.HasNext = filter.After != nil || filter.Page > 1, more
+	}
+
+	page.Tasks = tasks
+	return page, nil
+}
+
+func (r *gormTaskRepository) Update(task *models.Task) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(task).Error; err != nil {
+			return err
+		}
+		if len(task.Tags) == 0 {
+			return tx.Model(task).Association("Tags").Clear()
+		}
+		return tx.Model(task).Omit("Tags.*").Association("Tags").Replace(task.Tags)
+	})
+	if err != nil {
+		if err = translateError(r.db, err); errors.Is(err, gorm.ErrDuplicatedKey) {
+			return ErrDuplicateTitle
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *gormTaskRepository) ListChildren(parentIDs []int) ([]models.Task, error) {
+	tasks := make([]models.Task, 0)
+	if len(parentIDs) == 0 {
+		return tasks, nil
+	}
+	if err := r.withTags().Where("parent_id IN ?", parentIDs).Order("id").Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+	if err := r.markBlocked(tasks); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+func (r *gormTaskRepository) Delete(task *models.Task) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Model(&models.Task{}).Where("parent_id = ?", task.ID).Update("parent_id", nil).Error
+		if err != nil {
+			return err
+		}
+		if err := deleteDependencies(tx, []int{task.ID}); err != nil {
+			return err
+		}
+		// Tags stay linked so a restored task gets them back
+		return tx.Delete(task).Error
+	})
+}
+
+func (r *gormTaskRepository) DeleteTree(task *models.Task) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		ids, err := subtree(tx, task.ID)
+		if err != nil {
+			return err
+		}
+		if err := deleteDependencies(tx, ids); err != nil {
+			return err
+		}
+		return tx.Where("id IN ?", ids).Delete(&models.Task{}).Error
+	})
+}
+
+func (r *gormTaskRepository) GetDeleted(id int) (*models.Task, error) {
+	var task models.Task
+	if err := r.withTags().Unscoped().Where("deleted_at IS NOT NULL").First(&task, id).Error; err != nil {
+		return nil, translateError(r.db, err)
+	}
+	return r.loaded(task)
+}
+
+func (r *gormTaskRepository) Restore(task *models.Task) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// Descendants deleted with the task share its deletion time
+		deletedAt := tx.Unscoped().Model(&models.Task{}).Select("deleted_at").Where("id = ?", task.ID)
+		ids, err := subtree(tx.Unscoped().Where("deleted_at = (?)", deletedAt), task.ID)
+		if err != nil {
+			return err
+		}
+		err = tx.Unscoped().Model(&models.Task{}).Where("id = ?", task.ID).
+			Updates(map[string]any{"title": task.Title, "deleted_at": nil}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Unscoped().Model(&models.Task{}).Where("id IN ?", ids).Update("deleted_at", nil).Error
+	})
+	if err != nil {
+		if err = translateError(r.db, err); errors.Is(err, gorm.ErrDuplicatedKey) {
+			return ErrDuplicateTitle
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *gormTaskRepository) Purge(task *models.Task) ([]models.Task, error) {
+	var purged []models.Task
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		ids, err := subtree(tx.Unscoped().Where("deleted_at IS NOT NULL"), task.ID)
+		if err != nil {
+			return err
+		}
+		purged, err = purge(tx, ids)
+		return err
+	})
+	return purged, err
+}
+
+func (r *gormTaskRepository) PurgeDeletedBefore(cutoff time.Time) ([]models.Task, error) {
+	purged := make([]models.Task, 0)
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var ids []int
+		err := tx.Unscoped().Model(&models.Task{}).Where("deleted_at < ?", cutoff).Pluck("id", &ids).Error
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+		purged, err = purge(tx, ids)
+		return err
+	})
+	return purged, err
+}
+
+// subtree returns the ID of the task followed by the IDs of its descendants
+// that query, a scope on the tasks table, selects.
+func subtree(query *gorm.DB, id int) ([]int, error) {
+	// Collect the subtree level by level; parents never form cycles
+	ids := []int{id}
+	for level := ids; len(level) > 0; {
+		var children []int
+		err := query.Session(&gorm.Session{}).Model(&models.Task{}).Where("parent_id IN ?", level).Pluck("id", &children).Error
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, children...)
+		level = children
+	}
+	return ids, nil
+}
+
+// purge permanently deletes the tasks with everything linked to them and
+// returns them in ID order as they were last stored. Tasks outside the
+// purged set that name one of them as parent become top-level.
+func purge(tx *gorm.DB, ids []int) ([]models.Task, error) {
+	tasks := make([]models.Task, 0, len(ids))
+	if err := tx.Unscoped().Preload("Tags", orderTags).Where("id IN ?", ids).Order("id").Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Exec("DELETE FROM task_tags WHERE task_id IN ?", ids).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("task_id IN ?", ids).Delete(&models.TaskReminder{}).Error; err != nil {
+		return nil, err
+	}
+	if err := deleteDependencies(tx, ids); err != nil {
+		return nil, err
+	}
+	if err := tx.Unscoped().Where("id IN ?", ids).Delete(&models.Task{}).Error; err != nil {
+		return nil, err
+	}
+	err := tx.Unscoped().Model(&models.Task{}).Where("parent_id IN ?", ids).Update("parent_id", nil).Error
+	if err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// dependsOnQuery counts whether the first task waits for the second,
+// directly or through other tasks.
+const dependsOnQuery = `WITH RECURSIVE reachable(id) AS (
+	SELECT depends_on_id FROM task_dependencies WHERE task_id = ?
+	UNION
+	SELECT task_dependencies.depends_on_id FROM task_dependencies JOIN reachable ON task_dependencies.task_id = reachable.id
+) SELECT COUNT(*) FROM reachable WHERE id = ?`
+
+func (r *gormTaskRepository) AddDependency(taskID, dependsOnID int) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// Two additions checked side by side could each pass the cycle check
+		// and close a cycle together, so they queue on the workspace's row.
+		// SQLite ignores the lock but only has one writer anyway.
+		var locked []int
+		workspace := tx.Model(&models.Task{}).Select("workspace_id").Where("id = ?", taskID)
+		err := tx.Model(&models.Workspace{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN (?)", workspace).Pluck("id", &locked).Error
+		if err != nil {
+			return err
+		}
+
+		var cycles int64
+		if err := tx.Raw(dependsOnQuery, dependsOnID, taskID).Scan(&cycles).Error; err != nil {
+			return err
+		}
+		if cycles > 0 {
+			return ErrDependencyCycle
+		}
+
+		dependency := models.TaskDependency{TaskID: taskID, DependsOnID: dependsOnID, CreatedAt: time.Now()}
+		return tx.Create(&dependency).Error
+	})
+	if err != nil {
+		if err = translateError(r.db, err); errors.Is(err, gorm.ErrDuplicatedKey) {
+			return ErrDuplicateDependency
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *gormTaskRepository) RemoveDependency(taskID, dependsOnID int) error {
+	result := r.db.Where("task_id = ? AND depends_on_id = ?", taskID, dependsOnID).Delete(&models.TaskDependency{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *gormTaskRepository) Dependencies(taskID int) ([]models.Task, error) {
+	edges := r.db.Model(&models.TaskDependency{}).Select("depends_on_id").Where("task_id = ?", taskID)
+	return r.findLinked(edges)
+}
+
+func (r *gormTaskRepository) Dependents(taskID int) ([]models.Task, error) {
+	edges := r.db.Model(&models.TaskDependency{}).Select("task_id").Where("depends_on_id = ?", taskID)
+	return r.findLinked(edges)
+}
+
+// findLinked loads the tasks whose IDs the subquery selects.
+func (r *gormTaskRepository) findLinked(ids *gorm.DB) ([]models.Task, error) {
+	tasks := make([]models.Task, 0)
+	if err := r.withTags().Where("id IN (?)", ids).Order("id").Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+	if err := r.markBlocked(tasks); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// loaded sets the Blocked flag of a single task.
+func (r *gormTaskRepository) loaded(task models.Task) (*models.Task, error) {
+	tasks := []models.Task{task}
+	if err := r.markBlocked(tasks); err != nil {
+		return nil, err
+	}
+	return &tasks[0], nil
+}
+
+// markBlocked sets Blocked on the tasks that depend on an unresolved task.
+func (r *gormTaskRepository) markBlocked(tasks []models.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+	ids := make([]int, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+
+	var blocked []int
+	err := r.db.Model(&models.TaskDependency{}).
+		Joins("JOIN tasks ON tasks.id = task_dependencies.depends_on_id").
+		Where("task_dependencies.task_id IN ? AND tasks.status NOT IN ?", ids, models.ResolvedStatuses).
+		Distinct().
+		Pluck("task_dependencies.task_id", &blocked).Error
+	if err != nil {
+		return err
+	}
+
+	for i := range tasks {
+		tasks[i].Blocked = slices.Contains(blocked, tasks[i].ID)
+	}
+	return nil
+}
+
+// deleteDependencies removes every dependency from or to the tasks.
+func deleteDependencies(tx *gorm.DB, ids []int) error {
+	return tx.Where("task_id IN ? OR depends_on_id IN ?", ids, ids).Delete(&models.TaskDependency{}).Error
+}
+
+// withTags returns a query that loads each task's tags.
+func (r *gormTaskRepository) withTags() *gorm.DB {
+	return r.db.Preload("Tags", orderTags)
+}
+
+func orderTags(db *gorm.DB) *gorm.DB {
+	return db.Order("tags.name")
+}
+
+type columnKind int
+
+const (
+	plainColumn columnKind = iota
+	// dueDateNullColumn and dueDateColumn together sort undated tasks last
+	// in ascending order on every driver; SQLite would otherwise put NULLs
+	// first.
+	dueDateNullColumn
+	dueDateColumn
+)
+
+// orderColumn is one ORDER BY term of a task listing.
+type orderColumn struct {
+	kind  columnKind
+	expr  string
+	desc  bool
+	value func(task models.Task) any
+}
+
+// orderColumns translates sort keys into ORDER BY terms ending with the ID
+// tie-breaker. Fields come from the SortFields whitelist, never from user
+// input directly.
+func orderColumns(keys []SortKey) []orderColumn {
+	var columns []orderColumn
+	for _, key := range keys {
+		switch key.Field {
+		case SortDueDate:
+			columns = append(columns, dueDateColumns(key.Desc)...)
+		case SortPriority:
+			// Most urgent first means descending rank
+			columns = append(columns, orderColumn{
+				expr:  priorityRankSQL(),
+				desc:  !key.Desc,
+				value: func(task models.Task) any { return task.Priority.Rank() },
+			})
+			columns = append(columns, dueDateColumns(key.Desc)...)
+		default:
+			columns = append(columns, orderColumn{
+				expr:  string(key.Field),
+				desc:  key.Desc,
+				value: fieldValue(key.Field),
+			})
+		}
+	}
+	return append(columns, orderColumn{
+		expr:  "id",
+		value: func(task models.Task) any { return task.ID },
+	})
+}
+
+func dueDateColumns(desc bool) []orderColumn {
+	return []orderColumn{
+		{kind: dueDateNullColumn, expr: "due_date IS NULL", desc: desc},
+		{kind: dueDateColumn, expr: "due_date", desc: desc, value: func(task models.Task) any { return *task.DueDate }},
+	}
+}
+
+func fieldValue(field SortField) func(models.Task) any {
+	switch field {
+	case SortTitle:
+		return func(task models.Task) any { return task.Title }
+	case SortStatus:
+		return func(task models.Task) any { return string(task.Status) }
+	case SortCreatedAt:
+		return func(task models.Task) any { return task.CreatedAt }
+	case SortUpdatedAt:
+		return func(task models.Task) any { return task.UpdatedAt }
+	}
+	panic("repository: no value for sort field " + string(field))
+}
+
+func (c orderColumn) order(reverse bool) string {
+	if c.desc != reverse {
+		return c.expr + " DESC"
+	}
+	return c.expr + " ASC"
+}
+
+// keysetCondition returns a WHERE clause selecting the rows that come after
+// boundary in the order of columns, or before it when reverse is set:
+//
+//	(a > ?) OR (a = ? AND b > ?) OR (a = ? AND b = ? AND id > ?)
+func keysetCondition(columns []orderColumn, boundary models.Task, reverse bool) (string, []any) {
+	var terms, equal []string
+	var args, equalArgs []any
+
+	for _, column := range columns {
+		if sql, arg, ok := column.beyond(boundary, column.desc != reverse); ok {
+			term := append(append([]string(nil), equal...), sql)
+			terms = append(terms, "("+strings.Join(term, " AND ")+")")
+			args = append(args, equalArgs...)
+			args = append(args, arg...)
+		}
+		if sql, arg := column.same(boundary); sql != "" {
+			equal = append(equal, sql)
+			equalArgs = append(equalArgs, arg...)
+		}
+	}
+	return strings.Join(terms, " OR "), args
+}
+
+// beyond returns the condition for rows strictly past boundary on this
+// column, or false if no row can be.
+func (c orderColumn) beyond(boundary models.Task, desc bool) (string, []any, bool) {
+	undated := boundary.DueDate == nil
+	switch c.kind {
+	case dueDateNullColumn:
+		// Dated tasks come before undated ones in ascending order
+		if !desc && !undated {
+			return "due_date IS NULL", nil, true
+		}
+		if desc && undated {
+			return "due_date IS NOT NULL", nil, true
+		}
+		return "", nil, false
+	case dueDateColumn:
+		if undated {
+			return "", nil, false
+		}
+	}
+
+	op := " > ?"
+	if desc {
+		op = " < ?"
+	}
+	return c.expr + op, []any{c.value(boundary)}, true
+}
+
+// same returns the condition for rows equal to boundary on this column.
+func (c orderColumn) same(boundary models.Task) (string, []any) {
+	undated := boundary.DueDate == nil
+	switch c.kind {
+	case dueDateNullColumn:
+		if undated {
+			return "due_date IS NULL", nil
+		}
+		return "due_date IS NOT NULL", nil
+	case dueDateColumn:
+		// Already covered by the NULL check
+		if undated {
+			return "", nil
+		}
+	}
+	return c.expr + " = ?", []any{c.value(boundary)}
+}
+
+// priorityRankSQL returns a CASE expression computing TaskPriority.Rank.
+func priorityRankSQL() string {
+	var expr strings.Builder
+	expr.WriteString("CASE priority")
+	for _, priority := range models.TaskPriorities {
+		fmt.Fprintf(&expr, " WHEN '%s' THEN %d", priority, priority.Rank())
+	}
+	expr.WriteString(" ELSE 0 END")
+	return expr.String()
+}
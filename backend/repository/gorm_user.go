@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"errors"
+
+	"task/backend/models"
+
+	"gorm.io/gorm"
+)
+
+type gormUserRepository struct {
+	db *gorm.DB
+}
+
+// NewGormUserRepository returns a UserRepository backed by a GORM connection.
+func NewGormUserRepository(db *gorm.DB) UserRepository {
+	return &gormUserRepository{db: db}
+}
+
+func (r *gormUserRepository) Create(user *models.User) error {
+	if err := r.db.Create(user).Error; err != nil {
+		if err = translateError(r.db, err); errors.Is(err, gorm.ErrDuplicatedKey) {
+			return ErrDuplicateEmail
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *gormUserRepository) GetByEmail(email string) (*models.User, error) {
+	var user models.User
+	if err := r.db.Where("email = ?", email).First(&user).Error; err != nil {
+		return nil, translateError(r.db, err)
+	}
+	return &user, nil
+}
+
+func (r *gormUserRepository) GetByID(id int) (*models.User, error) {
+	var user models.User
+	if err := r.db.First(&user, id).Error; err != nil {
+		return nil, translateError(r.db, err)
+	}
+	return &user, nil
+}
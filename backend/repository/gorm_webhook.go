@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"time"
+
+	"task/backend/models"
+
+	"gorm.io/gorm"
+)
+
+type gormWebhookRepository struct {
+	db *gorm.DB
+}
+
+// NewGormWebhookRepository returns a WebhookRepository backed by GORM.
+func NewGormWebhookRepository(db *gorm.DB) WebhookRepository {
+	return &gormWebhookRepository{db: db}
+}
+
+func (r *gormWebhookRepository) Create(hook *models.Webhook) error {
+	return r.db.Create(hook).Error
+}
+
+func (r *gormWebhookRepository) GetByID(id int) (*models.Webhook, error) {
+	var hook models.Webhook
+	if err := r.db.First(&hook, id).Error; err != nil {
+		return nil, translateError(r.db, err)
+	}
+	return &hook, nil
+}
+
+func (r *gormWebhookRepository) List(workspaceID int) ([]models.Webhook, error) {
+	hooks := make([]models.Webhook, 0)
+	if err := r.db.Where("workspace_id = ?", workspaceID).Order("id").Find(&hooks).Error; err != nil {
+		return nil, err
+	}
+	return hooks, nil
+}
+
+func (r *gormWebhookRepository) Update(hook *models.Webhook) error {
+	return r.db.Save(hook).Error
+}
+
+func (r *gormWebhookRepository) Delete(hook *models.Webhook) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("webhook_id = ?", hook.ID).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(hook).Error
+	})
+}
+
+func (r *gormWebhookRepository) CreateDelivery(delivery *models.WebhookDelivery) error {
+	return r.db.Create(delivery).Error
+}
+
+func (r *gormWebhookRepository) UpdateDelivery(delivery *models.WebhookDelivery) error {
+	return r.db.Save(delivery).Error
+}
+
+func (r *gormWebhookRepository) DueDeliveries(now time.Time, limit int) ([]models.WebhookDelivery, error) {
+	deliveries := make([]models.WebhookDelivery, 0)
+	err := r.db.Where("status = ? AND next_attempt_at <= ?", models.DeliveryPending, now).
+		Order("next_attempt_at, id").
+		Limit(limit).
+		Find(&deliveries).Error
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func (r *gormWebhookRepository) ListDeliveries(webhookID, page, size int) ([]models.WebhookDelivery, int64, error) {
+	query := r.db.Model(&models.WebhookDelivery{}).Where("webhook_id = ?", webhookID)
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	deliveries := make([]models.WebhookDelivery, 0)
+	if err := query.Order("id DESC").Offset((page - 1) * size).Limit(size).Find(&deliveries).Error; err != nil {
+		return nil, 0, err
+	}
+	return deliveries, total, nil
+}
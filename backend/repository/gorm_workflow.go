@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"task/backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type gormWorkflowRepository struct {
+	db *gorm.DB
+}
+
+// NewGormWorkflowRepository returns a WorkflowRepository backed by a GORM
+// connection.
+func NewGormWorkflowRepository(db *gorm.DB) WorkflowRepository {
+	return &gormWorkflowRepository{db: db}
+}
+
+func (r *gormWorkflowRepository) Get(workspaceID int) (*models.Workflow, error) {
+	var workflow models.Workflow
+	if err := r.db.First(&workflow, "workspace_id = ?", workspaceID).Error; err != nil {
+		return nil, translateError(r.db, err)
+	}
+	return &workflow, nil
+}
+
+func (r *gormWorkflowRepository) Save(workflow *models.Workflow) error {
+	return r.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(workflow).Error
+}
+
+func (r *gormWorkflowRepository) Delete(workspaceID int) error {
+	return r.db.Delete(&models.Workflow{}, "workspace_id = ?", workspaceID).Error
+}
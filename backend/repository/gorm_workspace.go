@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"errors"
+
+	"task/backend/models"
+
+	"gorm.io/gorm"
+)
+
+type gormWorkspaceRepository struct {
+	db *gorm.DB
+}
+
+// NewGormWorkspaceRepository returns a WorkspaceRepository backed by GORM.
+func NewGormWorkspaceRepository(db *gorm.DB) WorkspaceRepository {
+	return &gormWorkspaceRepository{db: db}
+}
+
+func (r *gormWorkspaceRepository) Create(workspace *models.Workspace) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(workspace).Error; err != nil {
+			if err = translateError(tx, err); errors.Is(err, gorm.ErrDuplicatedKey) {
+				return ErrDuplicatePersonal
+			}
+			return err
+		}
+		owner := ownerMember(workspace)
+		return translateError(tx, tx.Create(&owner).Error)
+	})
+}
+
+func (r *gormWorkspaceRepository) GetByID(id int) (*models.Workspace, error) {
+	var workspace models.Workspace
+	if err := r.db.First(&workspace, id).Error; err != nil {
+		return nil, translateError(r.db, err)
+	}
+	return &workspace, nil
+}
+
+func (r *gormWorkspaceRepository) GetPersonal(userID int) (*models.Workspace, error) {
+	var workspace models.Workspace
+	if err := r.db.Where("owner_id = ? AND personal = ?", userID, true).First(&workspace).Error; err != nil {
+		return nil, translateError(r.db, err)
+	}
+	return &workspace, nil
+}
+
+func (r *gormWorkspaceRepository) ListForUser(userID int) ([]models.Workspace, error) {
+	workspaces := make([]models.Workspace, 0)
+	err := r.db.
+		Joins("JOIN workspace_members ON workspace_members.workspace_id = workspaces.id").
+		Where("workspace_members.user_id = ?", userID).
+		Order("workspaces.id").
+		Find(&workspaces).Error
+	if err != nil {
+		return nil, err
+	}
+	return workspaces, nil
+}
+
+func (r *gormWorkspaceRepository) AddMember(member *models.WorkspaceMember) error {
+	if err := r.db.Create(member).Error; err != nil {
+		if err = translateError(r.db, err); errors.Is(err, gorm.ErrDuplicatedKey) {
+			return ErrAlreadyMember
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *gormWorkspaceRepository) GetMember(workspaceID, userID int) (*models.WorkspaceMember, error) {
+	var member models.WorkspaceMember
+	err := r.db.Where("workspace_id = ? AND user_id = ?", workspaceID, userID).First(&member).Error
+	if err != nil {
+		return nil, translateError(r.db, err)
+	}
+	return &member, nil
+}
+
+func (r *gormWorkspaceRepository) UpdateMember(member *models.WorkspaceMember) error {
+	result := r.db.Model(&models.WorkspaceMember{}).
+		Where("workspace_id = ? AND user_id = ?", member.WorkspaceID, member.UserID).
+		Update("role", member.Role)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *gormWorkspaceRepository) ListMembers(workspaceID int) ([]models.WorkspaceMember, error) {
+	members := make([]models.WorkspaceMember, 0)
+	if err := r.db.Where("workspace_id = ?", workspaceID).Order("user_id").Find(&members).Error; err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+func (r *gormWorkspaceRepository) RemoveMember(workspaceID, userID int) error {
+	result := r.db.Where("workspace_id = ? AND user_id = ?", workspaceID, userID).Delete(&models.WorkspaceMember{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"sync"
+	"time"
+
+	"task/backend/models"
+)
+
+type memoryAPITokenRepository struct {
+	mu     sync.RWMutex
+	tokens map[int]models.APIToken
+	nextID int
+}
+
+// NewMemoryAPITokenRepository returns a concurrency-safe in-memory
+// APITokenRepository.
+func NewMemoryAPITokenRepository() APITokenRepository {
+	return &memoryAPITokenRepository{
+		tokens: make(map[int]models.APIToken),
+		nextID: 1,
+	}
+}
+
+func (r *memoryAPITokenRepository) Create(token *models.APIToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token.ID = r.nextID
+	r.nextID++
+	r.tokens[token.ID] = *token
+	return nil
+}
+
+func (r *memoryAPITokenRepository) GetByHash(hash string) (*models.APIToken, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, token := range r.tokens {
+		if token.TokenHash == hash {
+			return &token, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryAPITokenRepository) ListByUser(userID int) ([]models.APIToken, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tokens := make([]models.APIToken, 0)
+	for id := 1; id < r.nextID; id++ {
+		if token, ok := r.tokens[id]; ok && token.UserID == userID {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens, nil
+}
+
+func (r *memoryAPITokenRepository) Delete(userID, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, ok := r.tokens[id]
+	if !ok || token.UserID != userID {
+		return ErrNotFound
+	}
+	delete(r.tokens, id)
+	return nil
+}
+
+func (r *memoryAPITokenRepository) TouchLastUsed(id int, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, ok := r.tokens[id]
+	if !ok {
+		return ErrNotFound
+	}
+	token.LastUsedAt = &at
+	r.tokens[id] = token
+	return nil
+}
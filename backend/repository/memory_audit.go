@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"slices"
+	"sync"
+
+	"task/backend/models"
+)
+
+type memoryAuditRepository struct {
+	mu      sync.RWMutex
+	entries []models.AuditEntry
+}
+
+// NewMemoryAuditRepository returns a concurrency-safe in-memory
+// AuditRepository.
+func NewMemoryAuditRepository() AuditRepository {
+	return &memoryAuditRepository{}
+}
+
+func (r *memoryAuditRepository) Record(entry *models.AuditEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry.ID = len(r.entries) + 1
+	stored := *entry
+	stored.Changes = slices.Clone(entry.Changes)
+	r.entries = append(r.entries, stored)
+	return nil
+}
+
+func (r *memoryAuditRepository) ListForTask(taskID, page, size int) ([]models.AuditEntry, int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var matched []models.AuditEntry
+	for i := len(r.entries) - 1; i >= 0; i-- {
+		if r.entries[i].TaskID == taskID {
+			matched = append(matched, r.entries[i])
+		}
+	}
+
+	start, end := pageBounds(page, size, len(matched))
+	entries := make([]models.AuditEntry, 0, end-start)
+	for _, entry := range matched[start:end] {
+		entry.Changes = slices.Clone(entry.Changes)
+		entries = append(entries, entry)
+	}
+	return entries, int64(len(matched)), nil
+}
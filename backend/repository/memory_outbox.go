@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"slices"
+	"sync"
+
+	"task/backend/models"
+)
+
+type memoryOutboxRepository struct {
+	mu     sync.Mutex
+	events []models.OutboxEvent
+	nextID int
+}
+
+// NewMemoryOutboxRepository returns a concurrency-safe in-memory
+// OutboxRepository.
+func NewMemoryOutboxRepository() OutboxRepository {
+	return &memoryOutboxRepository{nextID: 1}
+}
+
+func (r *memoryOutboxRepository) Add(event *models.OutboxEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	event.ID = r.nextID
+	r.nextID++
+	stored := *event
+	stored.Payload = slices.Clone(event.Payload)
+	r.events = append(r.events, stored)
+	return nil
+}
+
+func (r *memoryOutboxRepository) Pending(limit int) ([]models.OutboxEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	events := make([]models.OutboxEvent, 0, min(limit, len(r.events)))
+	for _, event := range r.events[:min(limit, len(r.events))] {
+		event.Payload = slices.Clone(event.Payload)
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+func (r *memoryOutboxRepository) Remove(ids []int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events = slices.DeleteFunc(r.events, func(event models.OutboxEvent) bool {
+		return slices.Contains(ids, event.ID)
+	})
+	return nil
+}
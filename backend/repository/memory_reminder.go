@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"task/backend/models"
+)
+
+type memoryReminderRepository struct {
+	mu        sync.Mutex
+	reminders []models.TaskReminder
+	nextID    int
+	// tasks provides the tasks that Pending chooses from
+	tasks TaskRepository
+}
+
+// NewMemoryReminderRepository returns a concurrency-safe in-memory
+// ReminderRepository for the tasks of the given repository.
+func NewMemoryReminderRepository(tasks TaskRepository) ReminderRepository {
+	return &memoryReminderRepository{nextID: 1, tasks: tasks}
+}
+
+func (r *memoryReminderRepository) Pending(before time.Time) ([]models.Task, error) {
+	page, err := r.tasks.List(TaskFilter{DueBefore: &before, Page: 1, Size: math.MaxInt32, SkipCount: true})
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tasks := slices.DeleteFunc(page.Tasks, func(task models.Task) bool {
+		return task.Status.Resolved() || r.recorded(task.ID, *task.DueDate, 0)
+	})
+	slices.SortStableFunc(tasks, func(a, b models.Task) int { return a.DueDate.Compare(*b.DueDate) })
+	return tasks, nil
+}
+
+func (r *memoryReminderRepository) Recorded(taskID int, dueDate time.Time) ([]int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	minutes := make([]int, 0)
+	for _, reminder := range r.reminders {
+		if reminder.TaskID == taskID && reminder.DueDate.Equal(dueDate) {
+			minutes = append(minutes, reminder.MinutesBefore)
+		}
+	}
+	slices.Sort(minutes)
+	return minutes, nil
+}
+
+func (r *memoryReminderRepository) Record(reminder *models.TaskReminder) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.recorded(reminder.TaskID, reminder.DueDate, reminder.MinutesBefore) {
+		return ErrReminderRecorded
+	}
+	reminder.ID = r.nextID
+	r.nextID++
+	r.reminders = append(r.reminders, *reminder)
+	return nil
+}
+
+// recorded reports whether a window has been recorded. r.mu must be held.
+func (r *memoryReminderRepository) recorded(taskID int, dueDate time.Time, minutesBefore int) bool {
+	return slices.ContainsFunc(r.reminders, func(reminder models.TaskReminder) bool {
+		return reminder.TaskID == taskID && reminder.DueDate.Equal(dueDate) && reminder.MinutesBefore == minutesBefore
+	})
+}
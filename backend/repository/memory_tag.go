@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"slices"
+	"sort"
+	"sync"
+
+	"task/backend/models"
+)
+
+type memoryTagRepository struct {
+	mu     sync.RWMutex
+	tags   map[int]models.Tag
+	nextID int
+}
+
+// NewMemoryTagRepository returns a concurrency-safe in-memory TagRepository.
+func NewMemoryTagRepository() TagRepository {
+	return &memoryTagRepository{
+		tags:   make(map[int]models.Tag),
+		nextID: 1,
+	}
+}
+
+func (r *memoryTagRepository) Create(tag *models.Tag) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.nameTaken(tag.WorkspaceID, tag.Name, 0) {
+		return ErrDuplicateTag
+	}
+
+	tag.ID = r.nextID
+	r.nextID++
+	r.tags[tag.ID] = *tag
+	return nil
+}
+
+func (r *memoryTagRepository) GetByID(id int) (*models.Tag, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tag, ok := r.tags[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &tag, nil
+}
+
+func (r *memoryTagRepository) GetByNames(workspaceID int, names []string) ([]models.Tag, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tags := make([]models.Tag, 0, len(names))
+	for _, tag := range r.tags {
+		if tag.WorkspaceID == workspaceID && slices.Contains(names, tag.Name) {
+			tags = append(tags, tag)
+		}
+	}
+	sortTags(tags)
+	return tags, nil
+}
+
+func (r *memoryTagRepository) List(workspaceID int) ([]models.Tag, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tags := make([]models.Tag, 0)
+	for _, tag := range r.tags {
+		if tag.WorkspaceID == workspaceID {
+			tags = append(tags, tag)
+		}
+	}
+	sortTags(tags)
+	return tags, nil
+}
+
+func (r *memoryTagRepository) Update(tag *models.Tag) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.tags[tag.ID]; !ok {
+		return ErrNotFound
+	}
+	if r.nameTaken(tag.WorkspaceID, tag.Name, tag.ID) {
+		return ErrDuplicateTag
+	}
+
+	r.tags[tag.ID] = *tag
+	return nil
+}
+
+// Delete removes the tag. The memory task repository resolves tags on every
+// read, so tasks drop a deleted tag automatically.
+func (r *memoryTagRepository) Delete(tag *models.Tag) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.tags, tag.ID)
+	return nil
+}
+
+// nameTaken reports whether a tag other than exceptID already uses name in
+// the workspace. Callers must hold the lock.
+func (r *memoryTagRepository) nameTaken(workspaceID int, name string, exceptID int) bool {
+	for id, existing := range r.tags {
+		if id != exceptID && existing.WorkspaceID == workspaceID && existing.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func sortTags(tags []models.Tag) {
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+}
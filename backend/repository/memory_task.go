@@ -0,0 +1,442 @@
+package repository
+
+import (
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"task/backend/models"
+
+	"gorm.io/gorm"
+)
+
+type memoryTaskRepository struct {
+	mu     sync.RWMutex
+	tasks  map[int]models.Task
+	nextID int
+	// tags resolves the tag IDs stored with each task, so renamed and
+	// deleted tags show up like they do through a join
+	tags TagRepository
+	// dependencies maps a task ID to the IDs of the tasks it depends on
+	dependencies map[int][]int
+}
+
+// NewMemoryTaskRepository returns a concurrency-safe TaskRepository that keeps
+// tasks in memory. It is intended for tests and local demos.
+func NewMemoryTaskRepository(tags TagRepository) TaskRepository {
+	return &memoryTaskRepository{
+		tasks:        make(map[int]models.Task),
+		nextID:       1,
+		tags:         tags,
+		dependencies: make(map[int][]int),
+	}
+}
+
+func (r *memoryTaskRepository) Create(task *models.Task) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.titleTaken(task.WorkspaceID, task.Title, 0) {
+		return ErrDuplicateTitle
+	}
+
+	if task.Status == "" {
+		task.Status = models.TaskStatusPending
+	}
+	if task.Priority == "" {
+		task.Priority = models.TaskPriorityMedium
+	}
+
+	task.ID = r.nextID
+	r.nextID++
+	r.tasks[task.ID] = r.stored(*task)
+	return nil
+}
+
+func (r *memoryTaskRepository) GetByTitle(workspaceID int, title string) (*models.Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, task := range r.tasks {
+		if !task.DeletedAt.Valid && task.WorkspaceID == workspaceID && task.Title == title {
+			task = r.loaded(task)
+			return &task, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryTaskRepository) GetByID(id int) (*models.Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	task, ok := r.tasks[id]
+	if !ok || task.DeletedAt.Valid {
+		return nil, ErrNotFound
+	}
+	task = r.loaded(task)
+	return &task, nil
+}
+
+func (r *memoryTaskRepository) List(filter TaskFilter) (*TaskPage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	matched := make([]models.Task, 0)
+	for id := 1; id < r.nextID; id++ {
+		task, ok := r.tasks[id]
+		if !ok || task.DeletedAt.Valid != filter.Deleted {
+			continue
+		}
+		task = r.loaded(task)
+		if !filter.Matches(task) {
+			continue
+		}
+		matched = append(matched, task)
+	}
+
+	sort.Slice(matched, func(i, j int) bool {
+		return compareTasks(matched[i], matched[j], filter.Sort) < 0
+	})
+
+	page := &TaskPage{}
+	if !filter.SkipCount {
+		total := int64(len(matched))
+		page.Total = &total
+	}
+
+	var start, end int
+	switch {
+	case filter.After != nil:
+		start = sort.Search(len(matched), func(i int) bool {
+			return compareTasks(matched[i], *filter.After, filter.Sort) > 0
+		})
+		end = min(start+filter.Size, len(matched))
+		page.HasPrev, page.HasNext = true, end < len(matched)
+	case filter.Before != nil:
+		end = sort.Search(len(matched), func(i int) bool {
+			return compareTasks(matched[i], *filter.Before, filter.Sort) >= 0
+		})
+		start = max(end-filter.Size, 0)
+		page.HasPrev, page.HasNext = start > 0, true
+	default:
+		start, end = pageBounds(filter.Page, filter.Size, len(matched))
+		page.HasPrev, page.HasNext = filter.Page > 1, end < len(matched)
+	}
+
+	page.Tasks = append(make([]models.Task, 0, end-start), matched[start:end]...)
+	return page, nil
+}
+
+// pageBounds returns where page starts and ends among n items when pages
+// hold size items. Pages past the end are empty, also when their offset
+// would overflow an int.
+func pageBounds(page, size, n int) (start, end int) {
+	if page < 1 || size < 1 || page-1 > n/size {
+		return n, n
+	}
+	start = (page - 1) * size
+	return start, min(start+size, n)
+}
+
+func (r *memoryTaskRepository) Update(task *models.Task) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.tasks[task.ID]; !ok {
+		return ErrNotFound
+	}
+	if r.titleTaken(task.WorkspaceID, task.Title, task.ID) {
+		return ErrDuplicateTitle
+	}
+
+	r.tasks[task.ID] = r.stored(*task)
+	return nil
+}
+
+func (r *memoryTaskRepository) ListChildren(parentIDs []int) ([]models.Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	children := make([]models.Task, 0)
+	for id := 1; id < r.nextID; id++ {
+		task, ok := r.tasks[id]
+		if ok && !task.DeletedAt.Valid && task.ParentID != nil && slices.Contains(parentIDs, *task.ParentID) {
+			children = append(children, r.loaded(task))
+		}
+	}
+	return children, nil
+}
+
+func (r *memoryTaskRepository) Delete(task *models.Task) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, child := range r.tasks {
+		if child.ParentID != nil && *child.ParentID == task.ID {
+			child.ParentID = nil
+			r.tasks[id] = child
+		}
+	}
+	r.dropDependencies(task.ID)
+	r.trash(task.ID, time.Now())
+	return nil
+}
+
+func (r *memoryTaskRepository) DeleteTree(task *models.Task) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for _, id := range r.subtree(task.ID, false) {
+		r.dropDependencies(id)
+		r.trash(id, now)
+	}
+	return nil
+}
+
+func (r *memoryTaskRepository) GetDeleted(id int) (*models.Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	task, ok := r.tasks[id]
+	if !ok || !task.DeletedAt.Valid {
+		return nil, ErrNotFound
+	}
+	task = r.loaded(task)
+	return &task, nil
+}
+
+func (r *memoryTaskRepository) Restore(task *models.Task) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Descendants deleted with the task share its deletion time
+	deletedAt := r.tasks[task.ID].DeletedAt
+	ids := r.subtreeWhere(task.ID, func(child models.Task) bool {
+		return child.DeletedAt.Valid && child.DeletedAt.Time.Equal(deletedAt.Time)
+	})
+	for _, id := range ids {
+		title := r.tasks[id].Title
+		if id == task.ID {
+			title = task.Title
+		}
+		if r.titleTaken(r.tasks[id].WorkspaceID, title, id) {
+			return ErrDuplicateTitle
+		}
+	}
+
+	for _, id := range ids {
+		restored := r.tasks[id]
+		if id == task.ID {
+			restored.Title = task.Title
+		}
+		restored.DeletedAt = gorm.DeletedAt{}
+		restored.UpdatedAt = time.Now()
+		r.tasks[id] = restored
+	}
+	return nil
+}
+
+func (r *memoryTaskRepository) Purge(task *models.Task) ([]models.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.purge(r.subtree(task.ID, true)), nil
+}
+
+func (r *memoryTaskRepository) PurgeDeletedBefore(cutoff time.Time) ([]models.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var ids []int
+	for id, task := range r.tasks {
+		if task.DeletedAt.Valid && task.DeletedAt.Time.Before(cutoff) {
+			ids = append(ids, id)
+		}
+	}
+	return r.purge(ids), nil
+}
+
+func (r *memoryTaskRepository) AddDependency(taskID, dependsOnID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if slices.Contains(r.dependencies[taskID], dependsOnID) {
+		return ErrDuplicateDependency
+	}
+	if r.dependsOn(dependsOnID, taskID) {
+		return ErrDependencyCycle
+	}
+	r.dependencies[taskID] = append(r.dependencies[taskID], dependsOnID)
+	return nil
+}
+
+// dependsOn reports whether taskID waits for targetID, directly or through
+// other tasks. Callers must hold the lock.
+func (r *memoryTaskRepository) dependsOn(taskID, targetID int) bool {
+	seen := map[int]bool{taskID: true}
+	for pending := []int{taskID}; len(pending) > 0; {
+		id := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		for _, dependencyID := range r.dependencies[id] {
+			if dependencyID == targetID {
+				return true
+			}
+			if !seen[dependencyID] {
+				seen[dependencyID] = true
+				pending = append(pending, dependencyID)
+			}
+		}
+	}
+	return false
+}
+
+func (r *memoryTaskRepository) RemoveDependency(taskID, dependsOnID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := slices.Index(r.dependencies[taskID], dependsOnID)
+	if i < 0 {
+		return ErrNotFound
+	}
+	r.dependencies[taskID] = slices.Delete(r.dependencies[taskID], i, i+1)
+	return nil
+}
+
+func (r *memoryTaskRepository) Dependencies(taskID int) ([]models.Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.linked(func(id int) bool {
+		return slices.Contains(r.dependencies[taskID], id)
+	}), nil
+}
+
+func (r *memoryTaskRepository) Dependents(taskID int) ([]models.Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.linked(func(id int) bool {
+		return slices.Contains(r.dependencies[id], taskID)
+	}), nil
+}
+
+// subtree returns the ID of the task followed by the IDs of its descendants
+// that are in the trash, or with deleted unset those that are not. Callers
+// must hold the lock.
+func (r *memoryTaskRepository) subtree(id int, deleted bool) []int {
+	return r.subtreeWhere(id, func(child models.Task) bool { return child.DeletedAt.Valid == deleted })
+}
+
+// subtreeWhere returns the ID of the task followed by the IDs of its
+// descendants that match, reached through matching tasks only. Callers must
+// hold the lock.
+func (r *memoryTaskRepository) subtreeWhere(id int, match func(models.Task) bool) []int {
+	ids := []int{id}
+	for level := ids; len(level) > 0; {
+		var children []int
+		for childID, child := range r.tasks {
+			if match(child) && child.ParentID != nil && slices.Contains(level, *child.ParentID) {
+				children = append(children, childID)
+			}
+		}
+		ids = append(ids, children...)
+		level = children
+	}
+	return ids
+}
+
+// trash moves the task to the trash. Callers must hold the lock.
+func (r *memoryTaskRepository) trash(id int, now time.Time) {
+	task := r.tasks[id]
+	task.DeletedAt = gorm.DeletedAt{Time: now, Valid: true}
+	r.tasks[id] = task
+}
+
+// purge permanently removes the tasks and returns them in ID order. Tasks
+// that name one of them as parent become top-level. Callers must hold the
+// lock.
+func (r *memoryTaskRepository) purge(ids []int) []models.Task {
+	slices.Sort(ids)
+	for _, id := range ids {
+		r.dropDependencies(id)
+	}
+	tasks := make([]models.Task, 0, len(ids))
+	for _, id := range ids {
+		tasks = append(tasks, r.loaded(r.tasks[id]))
+		delete(r.tasks, id)
+	}
+	for id, task := range r.tasks {
+		if task.ParentID != nil && slices.Contains(ids, *task.ParentID) {
+			task.ParentID = nil
+			r.tasks[id] = task
+		}
+	}
+	return tasks
+}
+
+// linked returns the tasks whose IDs match in ID order. Callers must hold
+// the lock.
+func (r *memoryTaskRepository) linked(match func(id int) bool) []models.Task {
+	tasks := make([]models.Task, 0)
+	for id := 1; id < r.nextID; id++ {
+		if task, ok := r.tasks[id]; ok && !task.DeletedAt.Valid && match(id) {
+			tasks = append(tasks, r.loaded(task))
+		}
+	}
+	return tasks
+}
+
+// dropDependencies removes every dependency from or to the task. Callers
+// must hold the lock.
+func (r *memoryTaskRepository) dropDependencies(id int) {
+	delete(r.dependencies, id)
+	for taskID, dependsOn := range r.dependencies {
+		r.dependencies[taskID] = slices.DeleteFunc(dependsOn, func(other int) bool { return other == id })
+	}
+}
+
+// titleTaken reports whether a task other than exceptID and outside the
+// trash already uses title in the workspace. Callers must hold the lock.
+func (r *memoryTaskRepository) titleTaken(workspaceID int, title string, exceptID int) bool {
+	for id, existing := range r.tasks {
+		if id != exceptID && !existing.DeletedAt.Valid && existing.WorkspaceID == workspaceID && existing.Title == title {
+			return true
+		}
+	}
+	return false
+}
+
+// stored returns the copy of task kept in the map. Only tag IDs matter
+// there; loaded fills in the rest on the way out.
+func (r *memoryTaskRepository) stored(task models.Task) models.Task {
+	tags := make([]models.Tag, len(task.Tags))
+	for i, tag := range task.Tags {
+		tags[i] = models.Tag{ID: tag.ID}
+	}
+	task.Tags = tags
+	return task
+}
+
+// loaded fills in what the stored copy leaves out: the current tags,
+// skipping deleted ones, and the Blocked flag. Callers must hold the lock.
+func (r *memoryTaskRepository) loaded(task models.Task) models.Task {
+	tags := make([]models.Tag, 0, len(task.Tags))
+	for _, ref := range task.Tags {
+		if tag, err := r.tags.GetByID(ref.ID); err == nil {
+			tags = append(tags, *tag)
+		}
+	}
+	sortTags(tags)
+	task.Tags = tags
+
+	task.Blocked = slices.ContainsFunc(r.dependencies[task.ID], func(id int) bool {
+		return !r.tasks[id].Status.Resolved()
+	})
+	return task
+}
+
+// hasTags reports whether task carries any, or with all set every one, of
+// the tag names.
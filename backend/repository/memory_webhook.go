@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"task/backend/models"
+)
+
+type memoryWebhookRepository struct {
+	mu             sync.RWMutex
+	hooks          map[int]models.Webhook
+	deliveries     map[int]models.WebhookDelivery
+	nextID         int
+	nextDeliveryID int
+}
+
+// NewMemoryWebhookRepository returns a concurrency-safe in-memory
+// WebhookRepository. Its queue doesn't survive a restart.
+func NewMemoryWebhookRepository() WebhookRepository {
+	return &memoryWebhookRepository{
+		hooks:          make(map[int]models.Webhook),
+		deliveries:     make(map[int]models.WebhookDelivery),
+		nextID:         1,
+		nextDeliveryID: 1,
+	}
+}
+
+func (r *memoryWebhookRepository) Create(hook *models.Webhook) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	hook.ID = r.nextID
+	r.nextID++
+	r.hooks[hook.ID] = cloneWebhook(*hook)
+	return nil
+}
+
+func (r *memoryWebhookRepository) GetByID(id int) (*models.Webhook, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	hook, ok := r.hooks[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	hook = cloneWebhook(hook)
+	return &hook, nil
+}
+
+func (r *memoryWebhookRepository) List(workspaceID int) ([]models.Webhook, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	hooks := make([]models.Webhook, 0)
+	for _, hook := range r.hooks {
+		if hook.WorkspaceID == workspaceID {
+			hooks = append(hooks, cloneWebhook(hook))
+		}
+	}
+	sort.Slice(hooks, func(i, j int) bool { return hooks[i].ID < hooks[j].ID })
+	return hooks, nil
+}
+
+func (r *memoryWebhookRepository) Update(hook *models.Webhook) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.hooks[hook.ID]; !ok {
+		return ErrNotFound
+	}
+	r.hooks[hook.ID] = cloneWebhook(*hook)
+	return nil
+}
+
+func (r *memoryWebhookRepository) Delete(hook *models.Webhook) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, delivery := range r.deliveries {
+		if delivery.WebhookID == hook.ID {
+			delete(r.deliveries, id)
+		}
+	}
+	delete(r.hooks, hook.ID)
+	return nil
+}
+
+func (r *memoryWebhookRepository) CreateDelivery(delivery *models.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delivery.ID = r.nextDeliveryID
+	r.nextDeliveryID++
+	r.deliveries[delivery.ID] = cloneDelivery(*delivery)
+	return nil
+}
+
+func (r *memoryWebhookRepository) UpdateDelivery(delivery *models.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.deliveries[delivery.ID]; !ok {
+		return ErrNotFound
+	}
+	r.deliveries[delivery.ID] = cloneDelivery(*delivery)
+	return nil
+}
+
+func (r *memoryWebhookRepository) DueDeliveries(now time.Time, limit int) ([]models.WebhookDelivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	due := make([]models.WebhookDelivery, 0)
+	for _, delivery := range r.deliveries {
+		if delivery.Status == models.DeliveryPending && !delivery.NextAttemptAt.After(now) {
+			due = append(due, cloneDelivery(delivery))
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		if !due[i].NextAttemptAt.Equal(due[j].NextAttemptAt) {
+			return due[i].NextAttemptAt.Before(due[j].NextAttemptAt)
+		}
+		return due[i].ID < due[j].ID
+	})
+	return due[:min(limit, len(due))], nil
+}
+
+func (r *memoryWebhookRepository) ListDeliveries(webhookID, page, size int) ([]models.WebhookDelivery, int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var matched []models.WebhookDelivery
+	for _, delivery := range r.deliveries {
+		if delivery.WebhookID == webhookID {
+			matched = append(matched, delivery)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].ID > matched[j].ID })
+
+	start, end := pageBounds(page, size, len(matched))
+	deliveries := make([]models.WebhookDelivery, 0, end-start)
+	for _, delivery := range matched[start:end] {
+		deliveries = append(deliveries, cloneDelivery(delivery))
+	}
+	return deliveries, int64(len(matched)), nil
+}
+
+// cloneWebhook copies the event list so that callers can't change stored
+// webhooks.
+func cloneWebhook(hook models.Webhook) models.Webhook {
+	hook.Events = slices.Clone(hook.Events)
+	return hook
+}
+
+func cloneDelivery(delivery models.WebhookDelivery) models.WebhookDelivery {
+	delivery.Payload = slices.Clone(delivery.Payload)
+	if delivery.DeliveredAt != nil {
+		deliveredAt := *delivery.DeliveredAt
+		delivery.DeliveredAt = &deliveredAt
+	}
+	return delivery
+}
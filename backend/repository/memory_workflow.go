@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"maps"
+	"slices"
+	"sync"
+
+	"task/backend/models"
+)
+
+type memoryWorkflowRepository struct {
+	mu        sync.RWMutex
+	workflows map[int]models.Workflow
+}
+
+// NewMemoryWorkflowRepository returns a concurrency-safe in-memory
+// WorkflowRepository.
+func NewMemoryWorkflowRepository() WorkflowRepository {
+	return &memoryWorkflowRepository{workflows: make(map[int]models.Workflow)}
+}
+
+func (r *memoryWorkflowRepository) Get(workspaceID int) (*models.Workflow, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	workflow, ok := r.workflows[workspaceID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	workflow = cloneWorkflow(workflow)
+	return &workflow, nil
+}
+
+func (r *memoryWorkflowRepository) Save(workflow *models.Workflow) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.workflows[workflow.WorkspaceID] = cloneWorkflow(*workflow)
+	return nil
+}
+
+func (r *memoryWorkflowRepository) Delete(workspaceID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.workflows, workspaceID)
+	return nil
+}
+
+// cloneWorkflow copies the slices and map of workflow so callers can't
+// change the stored copy.
+func cloneWorkflow(workflow models.Workflow) models.Workflow {
+	workflow.Statuses = slices.Clone(workflow.Statuses)
+	transitions := maps.Clone(workflow.Transitions)
+	for from, to := range transitions {
+		transitions[from] = slices.Clone(to)
+	}
+	workflow.Transitions = transitions
+	return workflow
+}
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"sort"
+	"sync"
+
+	"task/backend/models"
+)
+
+type memberKey struct {
+	workspaceID int
+	userID      int
+}
+
+type memoryWorkspaceRepository struct {
+	mu         sync.RWMutex
+	workspaces map[int]models.Workspace
+	members    map[memberKey]models.WorkspaceMember
+	nextID     int
+}
+
+// NewMemoryWorkspaceRepository returns a concurrency-safe in-memory
+// WorkspaceRepository.
+func NewMemoryWorkspaceRepository() WorkspaceRepository {
+	return &memoryWorkspaceRepository{
+		workspaces: make(map[int]models.Workspace),
+		members:    make(map[memberKey]models.WorkspaceMember),
+		nextID:     1,
+	}
+}
+
+func (r *memoryWorkspaceRepository) Create(workspace *models.Workspace) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Mirrors the partial unique index on personal workspaces
+	if workspace.Personal {
+		for _, existing := range r.workspaces {
+			if existing.Personal && existing.OwnerID == workspace.OwnerID {
+				return ErrDuplicatePersonal
+			}
+		}
+	}
+
+	workspace.ID = r.nextID
+	r.nextID++
+	r.workspaces[workspace.ID] = *workspace
+	r.members[memberKey{workspace.ID, workspace.OwnerID}] = ownerMember(workspace)
+	return nil
+}
+
+func (r *memoryWorkspaceRepository) GetByID(id int) (*models.Workspace, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	workspace, ok := r.workspaces[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &workspace, nil
+}
+
+func (r *memoryWorkspaceRepository) GetPersonal(userID int) (*models.Workspace, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, workspace := range r.workspaces {
+		if workspace.Personal && workspace.OwnerID == userID {
+			return &workspace, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryWorkspaceRepository) ListForUser(userID int) ([]models.Workspace, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	workspaces := make([]models.Workspace, 0)
+	for key := range r.members {
+		if key.userID == userID {
+			workspaces = append(workspaces, r.workspaces[key.workspaceID])
+		}
+	}
+	sort.Slice(workspaces, func(i, j int) bool { return workspaces[i].ID < workspaces[j].ID })
+	return workspaces, nil
+}
+
+func (r *memoryWorkspaceRepository) AddMember(member *models.WorkspaceMember) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := memberKey{member.WorkspaceID, member.UserID}
+	if _, ok := r.members[key]; ok {
+		return ErrAlreadyMember
+	}
+	// Mirrors the column default
+	if member.Role == "" {
+		member.Role = models.RoleEditor
+	}
+	r.members[key] = *member
+	return nil
+}
+
+func (r *memoryWorkspaceRepository) GetMember(workspaceID, userID int) (*models.WorkspaceMember, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	member, ok := r.members[memberKey{workspaceID, userID}]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &member, nil
+}
+
+func (r *memoryWorkspaceRepository) UpdateMember(member *models.WorkspaceMember) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := memberKey{member.WorkspaceID, member.UserID}
+	existing, ok := r.members[key]
+	if !ok {
+		return ErrNotFound
+	}
+	existing.Role = member.Role
+	r.members[key] = existing
+	return nil
+}
+
+func (r *memoryWorkspaceRepository) ListMembers(workspaceID int) ([]models.WorkspaceMember, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	members := make([]models.WorkspaceMember, 0)
+	for key, member := range r.members {
+		if key.workspaceID == workspaceID {
+			members = append(members, member)
+		}
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].UserID < members[j].UserID })
+	return members, nil
+}
+
+func (r *memoryWorkspaceRepository) RemoveMember(workspaceID, userID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := memberKey{workspaceID, userID}
+	if _, ok := r.members[key]; !ok {
+		return ErrNotFound
+	}
+	delete(r.members, key)
+	return nil
+}
@@ -0,0 +1,12 @@
+package repository
+
+import "task/backend/models"
+
+// OutboxRepository stores published events until they have been relayed.
+type OutboxRepository interface {
+	Add(event *models.OutboxEvent) error
+	// Pending returns up to limit events in the order they were added.
+	Pending(limit int) ([]models.OutboxEvent, error)
+	// Remove deletes relayed events.
+	Remove(ids []int) error
+}
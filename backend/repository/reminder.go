@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"task/backend/models"
+)
+
+// ErrReminderRecorded is returned when a reminder window of a task has
+// already been recorded for its due date.
+var ErrReminderRecorded = errors.New("reminder already recorded")
+
+// ReminderRepository stores which reminder windows of tasks have passed.
+type ReminderRepository interface {
+	// Pending returns the tasks that are due by before and may still need
+	// a reminder: those that aren't completed, cancelled, trashed or already
+	// reminded of being overdue. The soonest due come first.
+	Pending(before time.Time) ([]models.Task, error)
+	// Recorded returns the windows recorded for a task and due date, in
+	// minutes before the due date.
+	Recorded(taskID int, dueDate time.Time) ([]int, error)
+	// Record stores a passed window, or returns ErrReminderRecorded.
+	Record(reminder *models.TaskReminder) error
+}
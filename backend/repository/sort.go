@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"strings"
+
+	"task/backend/models"
+)
+
+var ErrInvalidSort = errors.New("invalid sort")
+
+// SortField is a task attribute that listings can be ordered by.
+type SortField string
+
+const (
+	SortDueDate   SortField = "due_date"
+	SortCreatedAt SortField = "created_at"
+	SortUpdatedAt SortField = "updated_at"
+	SortTitle     SortField = "title"
+	SortStatus    SortField = "status"
+	// SortPriority orders by urgency, most urgent first, and then by due
+	// date with undated tasks last.
+	SortPriority SortField = "priority"
+)
+
+// SortFields lists the accepted sort fields.
+var SortFields = []SortField{SortDueDate, SortCreatedAt, SortUpdatedAt, SortTitle, SortStatus, SortPriority}
+
+// SortKey is one level of ordering. Ties on every key are broken by ID.
+type SortKey struct {
+	Field SortField
+	Desc  bool
+}
+
+// ParseSort parses a comma-separated list of sort fields, each optionally
+// prefixed with "-" for descending order, e.g. "due_date,-created_at".
+func ParseSort(value string) ([]SortKey, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	var keys []SortKey
+	seen := make(map[SortField]bool)
+	for _, part := range strings.Split(value, ",") {
+		name, desc := strings.CutPrefix(strings.TrimSpace(part), "-")
+		field := SortField(name)
+		if !isSortField(field) {
+			return nil, fmt.Errorf("%w: unknown field %q, use one of %s", ErrInvalidSort, name, sortFieldNames())
+		}
+		if seen[field] {
+			return nil, fmt.Errorf("%w: duplicate field %q", ErrInvalidSort, name)
+		}
+		seen[field] = true
+		keys = append(keys, SortKey{Field: field, Desc: desc})
+	}
+	return keys, nil
+}
+
+func sortFieldNames() string {
+	names := make([]string, len(SortFields))
+	for i, field := range SortFields {
+		names[i] = string(field)
+	}
+	return strings.Join(names, ", ")
+}
+
+func isSortField(field SortField) bool {
+	for _, known := range SortFields {
+		if field == known {
+			return true
+		}
+	}
+	return false
+}
+
+// compareTasks orders tasks by keys the same way the SQL ORDER BY built by
+// the GORM repository does, including the final ID tie-breaker.
+func compareTasks(a, b models.Task, keys []SortKey) int {
+	for _, key := range keys {
+		result := compareField(a, b, key.Field)
+		if key.Desc {
+			result = -result
+		}
+		if result != 0 {
+			return result
+		}
+	}
+	return cmp.Compare(a.ID, b.ID)
+}
+
+func compareField(a, b models.Task, field SortField) int {
+	switch field {
+	case SortDueDate:
+		return compareDueDates(a, b)
+	case SortCreatedAt:
+		return a.CreatedAt.Compare(b.CreatedAt)
+	case SortUpdatedAt:
+		return a.UpdatedAt.Compare(b.UpdatedAt)
+	case SortTitle:
+		return strings.Compare(a.Title, b.Title)
+	case SortStatus:
+		return strings.Compare(string(a.Status), string(b.Status))
+	case SortPriority:
+		if result := cmp.Compare(b.Priority.Rank(), a.Priority.Rank()); result != 0 {
+			return result
+		}
+		return compareDueDates(a, b)
+	}
+	return 0
+}
+
+// compareDueDates sorts undated tasks after dated ones, like NULLS LAST.
+func compareDueDates(a, b models.Task) int {
+	switch {
+	case a.DueDate == nil && b.DueDate == nil:
+		return 0
+	case a.DueDate == nil:
+		return 1
+	case b.DueDate == nil:
+		return -1
+	}
+	return a.DueDate.Compare(*b.DueDate)
+}
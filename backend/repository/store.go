@@ -0,0 +1,72 @@
+package repository
+
+import "gorm.io/gorm"
+
+// Store groups the repositories of one storage backend.
+type Store struct {
+	Tasks      TaskRepository
+	Users      UserRepository
+	APITokens  APITokenRepository
+	Workspaces WorkspaceRepository
+	Tags       TagRepository
+	Workflows  WorkflowRepository
+	Audit      AuditRepository
+	Webhooks   WebhookRepository
+	Outbox     OutboxRepository
+	Reminders  ReminderRepository
+
+	// transaction runs fn on a Store bound to a new transaction
+	transaction func(fn func(tx *Store) error) error
+}
+
+// NewGormStore returns a Store whose repositories share one GORM connection.
+func NewGormStore(db *gorm.DB) *Store {
+	return &Store{
+		Tasks:      NewGormTaskRepository(db),
+		Users:      NewGormUserRepository(db),
+		APITokens:  NewGormAPITokenRepository(db),
+		Workspaces: NewGormWorkspaceRepository(db),
+		Tags:       NewGormTagRepository(db),
+		Workflows:  NewGormWorkflowRepository(db),
+		Audit:      NewGormAuditRepository(db),
+		Webhooks:   NewGormWebhookRepository(db),
+		Outbox:     NewGormOutboxRepository(db),
+		Reminders:  NewGormReminderRepository(db),
+
+		// Inside a transaction this nests as a savepoint
+		transaction: func(fn func(tx *Store) error) error {
+			return db.Transaction(func(tx *gorm.DB) error {
+				return fn(NewGormStore(tx))
+			})
+		},
+	}
+}
+
+// NewMemoryStore returns a Store that keeps everything in memory.
+func NewMemoryStore() *Store {
+	tags := NewMemoryTagRepository()
+	tasks := NewMemoryTaskRepository(tags)
+	return &Store{
+		Tasks:      tasks,
+		Users:      NewMemoryUserRepository(),
+		APITokens:  NewMemoryAPITokenRepository(),
+		Workspaces: NewMemoryWorkspaceRepository(),
+		Tags:       tags,
+		Workflows:  NewMemoryWorkflowRepository(),
+		Audit:      NewMemoryAuditRepository(),
+		Webhooks:   NewMemoryWebhookRepository(),
+		Outbox:     NewMemoryOutboxRepository(),
+		Reminders:  NewMemoryReminderRepository(tasks),
+	}
+}
+
+// Transaction runs fn with a Store whose changes are committed together, or
+// rolled back if fn returns an error. Only fn's Store may be used until it
+// returns. The memory store can't roll back: changes made before the error
+// stay.
+func (s *Store) Transaction(fn func(tx *Store) error) error {
+	if s.transaction == nil {
+		return fn(s)
+	}
+	return s.transaction(fn)
+}
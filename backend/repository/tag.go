@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"errors"
+
+	"task/backend/models"
+)
+
+var ErrDuplicateTag = errors.New("tag with this name already exists in the workspace")
+
+// TagRepository abstracts storage of workspace tags. Attaching tags to tasks
+// is part of TaskRepository.
+type TagRepository interface {
+	Create(tag *models.Tag) error
+	GetByID(id int) (*models.Tag, error)
+	// GetByNames returns the workspace's tags with the given names, ordered
+	// by name. Unknown names are skipped.
+	GetByNames(workspaceID int, names []string) ([]models.Tag, error)
+	List(workspaceID int) ([]models.Tag, error)
+	Update(tag *models.Tag) error
+	// Delete removes the tag and detaches it from every task.
+	Delete(tag *models.Tag) error
+}
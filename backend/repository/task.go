@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"task/backend/models"
+)
+
+var (
+	ErrNotFound            = errors.New("record not found")
+	ErrDuplicateTitle      = errors.New("task with this title already exists in the workspace")
+	ErrDuplicateDependency = errors.New("dependency already exists")
+	ErrDependencyCycle     = errors.New("dependency would create a cycle")
+)
+
+// TaskFilter holds the query options accepted by TaskRepository.List.
+type TaskFilter struct {
+	WorkspaceID int
+	Title       string // exact match
+	Status      string
+	Priority    models.TaskPriority
+	SeriesID    int // tasks of one recurring series
+	DueBefore   *time.Time
+	Search      string
+	// Tags matches tasks carrying any of the tag names, or all of them
+	// when AllTags is set.
+	Tags    []string
+	AllTags bool
+	Sort    []SortKey // ID order when empty
+	// After and Before, as returned by DecodeCursor, select the tasks
+	// following or preceding a position instead of Page. At most one is set.
+	After  *models.Task
+	Before *models.Task
+	Page   int
+	Size   int
+	// SkipCount leaves TaskPage.Total nil, saving a COUNT query.
+	SkipCount bool
+	// Deleted lists the tasks in the trash instead of the others
+	Deleted bool
+}
+
+// Matches reports whether a task passes the filter's conditions, leaving out
+// its trash, sort and paging settings. It is how the memory store and the
+// task stream filter tasks the way List does in SQL.
+func (f TaskFilter) Matches(task models.Task) bool {
+	if f.WorkspaceID != 0 && task.WorkspaceID != f.WorkspaceID {
+		return false
+	}
+	if f.Title != "" && task.Title != f.Title {
+		return false
+	}
+	if f.Status != "" && string(task.Status) != f.Status {
+		return false
+	}
+	if f.Priority != "" && task.Priority != f.Priority {
+		return false
+	}
+	if f.SeriesID != 0 && (task.SeriesID == nil || *task.SeriesID != f.SeriesID) {
+		return false
+	}
+	// Like SQL, a NULL due date never satisfies due_date <= ?
+	if f.DueBefore != nil && (task.DueDate == nil || task.DueDate.After(*f.DueBefore)) {
+		return false
+	}
+	if f.Search != "" && !strings.Contains(strings.ToLower(task.Title), strings.ToLower(f.Search)) {
+		return false
+	}
+	if len(f.Tags) > 0 && !hasTags(task, f.Tags, f.AllTags) {
+		return false
+	}
+	return true
+}
+
+func hasTags(task models.Task, names []string, all bool) bool {
+	matched := 0
+	for _, name := range names {
+		if slices.ContainsFunc(task.Tags, func(tag models.Tag) bool { return tag.Name == name }) {
+			matched++
+		}
+	}
+	if all {
+		return matched == len(names)
+	}
+	return matched > 0
+}
+
+// TaskPage is one page of a task listing.
+type TaskPage struct {
+	Tasks []models.Task
+	Total *int64 // nil when the filter skipped counting
+	// HasPrev and HasNext report whether tasks may exist before and after
+	// the page in the listing's order.
+	HasPrev bool
+	HasNext bool
+}
+
+// TaskRepository abstracts task storage so handlers don't depend on a
+// specific database. Tasks are returned with their tags ordered by name and
+// their Blocked flag set; Create and Update store Task.Tags, which must
+// already exist. Tasks in the trash are left out everywhere except by
+// GetDeleted and List with TaskFilter.Deleted.
+type TaskRepository interface {
+	Create(task *models.Task) error
+	GetByTitle(workspaceID int, title string) (*models.Task, error)
+	GetByID(id int) (*models.Task, error)
+	List(filter TaskFilter) (*TaskPage, error)
+	// ListChildren returns the direct subtasks of the given tasks in ID order.
+	ListChildren(parentIDs []int) ([]models.Task, error)
+	Update(task *models.Task) error
+	// Delete moves the task to the trash and removes its dependencies. Its
+	// subtasks, including trashed ones, become top-level tasks.
+	Delete(task *models.Task) error
+	// DeleteTree moves the task together with all of its descendants to the
+	// trash, all with the same deletion time.
+	DeleteTree(task *models.Task) error
+
+	// GetDeleted returns a task from the trash.
+	GetDeleted(id int) (*models.Task, error)
+	// Restore takes a trashed task and the descendants that were moved to
+	// the trash with it out of the trash. Descendants trashed on their own
+	// before stay there. The task's title is stored as well, so it can be
+	// renamed to avoid a clash.
+	Restore(task *models.Task) error
+	// Purge permanently removes a trashed task and its trashed descendants
+	// and returns them in ID order.
+	Purge(task *models.Task) ([]models.Task, error)
+	// PurgeDeletedBefore permanently removes the tasks moved to the trash
+	// before cutoff and returns them in ID order.
+	PurgeDeletedBefore(cutoff time.Time) ([]models.Task, error)
+
+	// AddDependency records that taskID cannot start before dependsOnID is
+	// resolved, or returns ErrDependencyCycle if dependsOnID already waits
+	// for taskID. The check and the insert are atomic.
+	AddDependency(taskID, dependsOnID int) error
+	RemoveDependency(taskID, dependsOnID int) error
+	// Dependencies returns the tasks taskID depends on in ID order.
+	Dependencies(taskID int) ([]models.Task, error)
+	// Dependents returns the tasks that depend on taskID in ID order.
+	Dependents(taskID int) ([]models.Task, error)
+}
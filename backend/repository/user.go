@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"errors"
+
+	"task/backend/models"
+)
+
+var ErrDuplicateEmail = errors.New("user with this email already exists")
+
+// UserRepository abstracts storage of user accounts.
+type UserRepository interface {
+	Create(user *models.User) error
+	GetByEmail(email string) (*models.User, error)
+	GetByID(id int) (*models.User, error)
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"time"
+
+	"task/backend/models"
+)
+
+// WebhookRepository stores webhook subscriptions and the queue of their
+// deliveries.
+type WebhookRepository interface {
+	Create(hook *models.Webhook) error
+	GetByID(id int) (*models.Webhook, error)
+	List(workspaceID int) ([]models.Webhook, error)
+	Update(hook *models.Webhook) error
+	// Delete removes the webhook together with its deliveries.
+	Delete(hook *models.Webhook) error
+
+	CreateDelivery(delivery *models.WebhookDelivery) error
+	UpdateDelivery(delivery *models.WebhookDelivery) error
+	// DueDeliveries returns up to limit pending deliveries whose next attempt
+	// is due at now, longest waiting first.
+	DueDeliveries(now time.Time, limit int) ([]models.WebhookDelivery, error)
+	// ListDeliveries returns one page of the webhook's deliveries, newest
+	// first, together with the total number of deliveries.
+	ListDeliveries(webhookID, page, size int) ([]models.WebhookDelivery, int64, error)
+}
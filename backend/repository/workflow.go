@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"errors"
+
+	"task/backend/models"
+)
+
+// WorkflowRepository abstracts storage of custom workspace workflows.
+type WorkflowRepository interface {
+	// Get returns the workspace's custom workflow, or ErrNotFound if it
+	// uses the default one.
+	Get(workspaceID int) (*models.Workflow, error)
+	// Save creates or replaces the workspace's workflow.
+	Save(workflow *models.Workflow) error
+	// Delete removes the workspace's workflow, reverting it to the default.
+	Delete(workspaceID int) error
+}
+
+// WorkflowFor returns the workflow in effect for a workspace.
+func WorkflowFor(repo WorkflowRepository, workspaceID int) (*models.Workflow, error) {
+	workflow, err := repo.Get(workspaceID)
+	if errors.Is(err, ErrNotFound) {
+		return models.DefaultWorkflow(workspaceID), nil
+	}
+	return workflow, err
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"task/backend/models"
+)
+
+var (
+	ErrAlreadyMember     = errors.New("user is already a member of this workspace")
+	ErrDuplicatePersonal = errors.New("user already has a personal workspace")
+)
+
+// WorkspaceRepository abstracts storage of workspaces and their members.
+type WorkspaceRepository interface {
+	// Create stores the workspace and makes its owner a member with the
+	// owner role, together: a workspace never exists without its owner.
+	Create(workspace *models.Workspace) error
+	GetByID(id int) (*models.Workspace, error)
+	// GetPersonal returns the user's personal workspace.
+	GetPersonal(userID int) (*models.Workspace, error)
+	ListForUser(userID int) ([]models.Workspace, error)
+	AddMember(member *models.WorkspaceMember) error
+	GetMember(workspaceID, userID int) (*models.WorkspaceMember, error)
+	// UpdateMember saves the member's role.
+	UpdateMember(member *models.WorkspaceMember) error
+	ListMembers(workspaceID int) ([]models.WorkspaceMember, error)
+	RemoveMember(workspaceID, userID int) error
+}
+
+// ownerMember returns the membership that makes workspace's owner its owner.
+func ownerMember(workspace *models.Workspace) models.WorkspaceMember {
+	return models.WorkspaceMember{
+		WorkspaceID: workspace.ID,
+		UserID:      workspace.OwnerID,
+		Role:        models.RoleOwner,
+		CreatedAt:   workspace.CreatedAt,
+	}
+}
+
+// EnsurePersonalWorkspace returns the user's personal workspace, creating it
+// on first use.
+func EnsurePersonalWorkspace(repo WorkspaceRepository, userID int) (*models.Workspace, error) {
+	workspace, err := repo.GetPersonal(userID)
+	if !errors.Is(err, ErrNotFound) {
+		return workspace, err
+	}
+
+	now := time.Now()
+	workspace = &models.Workspace{
+		Name:      "Personal",
+		OwnerID:   userID,
+		Personal:  true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := repo.Create(workspace); err != nil {
+		// A concurrent request may have created it first
+		if existing, getErr := repo.GetPersonal(userID); getErr == nil {
+			return existing, nil
+		}
+		return nil, err
+	}
+	return workspace, nil
+}
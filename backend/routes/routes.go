@@ -0,0 +1,98 @@
+package routes
+
+import (
+	"task/backend/auth"
+	"task/backend/handlers"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Handlers bundles the handlers and middleware mounted by Setup.
+type Handlers struct {
+	Tasks       *handlers.TaskHandler
+	Tags        *handlers.TagHandler
+	Auth        *handlers.AuthHandler
+	APITokens   *handlers.APITokenHandler
+	Workspaces  *handlers.WorkspaceHandler
+	Workflows   *handlers.WorkflowHandler
+	Webhooks    *handlers.WebhookHandler
+	Stream      *handlers.StreamHandler
+	Board       *handlers.BoardHandler
+	RequireAuth fiber.Handler
+	// ResolveWorkspace selects the workspace task routes operate on
+	ResolveWorkspace fiber.Handler
+}
+
+func Setup(app *fiber.App, h Handlers) {
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendString("Hey Champ, the Task API is now live!\nThanks to Andy\n")
+	})
+
+	// Public authentication routes
+	app.Post("/auth/signup", h.Auth.Signup)
+	app.Post("/auth/login", h.Auth.Login)
+	app.Post("/auth/refresh", h.Auth.Refresh)
+
+	// Personal API tokens can only be managed from a login session
+	tokens := app.Group("/tokens", h.RequireAuth, auth.SessionOnly)
+	tokens.Post("/", h.APITokens.CreateToken)
+	tokens.Get("/", h.APITokens.ListTokens)
+	tokens.Delete("/:id", h.APITokens.RevokeToken)
+
+	// Workspace and membership management
+	workspaces := app.Group("/workspaces", h.RequireAuth)
+	workspaces.Post("/", h.Workspaces.CreateWorkspace)
+	workspaces.Get("/", h.Workspaces.ListWorkspaces)
+	workspaces.Get("/:id/members", h.Workspaces.ListMembers)
+	workspaces.Post("/:id/members", h.Workspaces.AddMember)
+	workspaces.Put("/:id/members/:userId", h.Workspaces.UpdateMember)
+	workspaces.Delete("/:id/members/:userId", h.Workspaces.RemoveMember)
+
+	// Task routes, scoped to the selected workspace and guarded by the
+	// caller's role in it
+	tasks := app.Group("/tasks", h.RequireAuth, h.ResolveWorkspace)
+	tasks.Post("/", auth.Require(auth.ActionCreateTask), h.Tasks.CreateTask)
+	tasks.Get("/", auth.Require(auth.ActionReadTasks), h.Tasks.GetAllTasks)
+	tasks.Get("/events", auth.Require(auth.ActionReadTasks), h.Stream.StreamTasks)
+	tasks.Get("/:id", auth.Require(auth.ActionReadTasks), h.Tasks.GetTask)
+	tasks.Get("/:id/children", auth.Require(auth.ActionReadTasks), h.Tasks.ListChildren)
+	tasks.Get("/:id/tree", auth.Require(auth.ActionReadTasks), h.Tasks.GetTaskTree)
+	tasks.Get("/:id/history", auth.Require(auth.ActionReadTasks), h.Tasks.GetTaskHistory)
+	tasks.Get("/:id/dependencies", auth.Require(auth.ActionReadTasks), h.Tasks.ListDependencies)
+	tasks.Post("/:id/dependencies", auth.Require(auth.ActionUpdateTask), h.Tasks.AddDependency)
+	tasks.Delete("/:id/dependencies/:dependsOnId", auth.Require(auth.ActionUpdateTask), h.Tasks.RemoveDependency)
+	tasks.Put("/:id", auth.Require(auth.ActionUpdateTask), h.Tasks.UpdateTask)
+	tasks.Delete("/:id", auth.Require(auth.ActionDeleteTask), h.Tasks.DeleteTask)
+	tasks.Post("/:id/restore", auth.Require(auth.ActionDeleteTask), h.Tasks.RestoreTask)
+
+	// The live task board is a WebSocket, which browsers open without
+	// custom headers
+	app.Get("/board", auth.QueryCredentials, h.RequireAuth, h.ResolveWorkspace, auth.Require(auth.ActionReadTasks), h.Board.Connect)
+
+	// Deleted tasks wait in the trash until they are restored or purged
+	trash := app.Group("/trash", h.RequireAuth, h.ResolveWorkspace)
+	trash.Get("/", auth.Require(auth.ActionReadTasks), h.Tasks.ListTrash)
+	trash.Delete("/:id", auth.Require(auth.ActionPurgeTask), h.Tasks.PurgeTask)
+
+	// Tag routes, scoped like the tasks they label
+	tags := app.Group("/tags", h.RequireAuth, h.ResolveWorkspace)
+	tags.Get("/", auth.Require(auth.ActionReadTags), h.Tags.ListTags)
+	tags.Post("/", auth.Require(auth.ActionManageTags), h.Tags.CreateTag)
+	tags.Put("/:id", auth.Require(auth.ActionManageTags), h.Tags.RenameTag)
+	tags.Delete("/:id", auth.Require(auth.ActionDeleteTag), h.Tags.DeleteTag)
+
+	// The status workflow of the selected workspace
+	workflow := app.Group("/workflow", h.RequireAuth, h.ResolveWorkspace)
+	workflow.Get("/", auth.Require(auth.ActionReadTasks), h.Workflows.GetWorkflow)
+	workflow.Put("/", auth.Require(auth.ActionManageWorkflow), h.Workflows.UpdateWorkflow)
+	workflow.Delete("/", auth.Require(auth.ActionManageWorkflow), h.Workflows.ResetWorkflow)
+
+	// Webhooks of the selected workspace. Their URLs and delivery logs are
+	// only visible to the admins who manage them.
+	webhooks := app.Group("/webhooks", h.RequireAuth, h.ResolveWorkspace, auth.Require(auth.ActionManageWebhooks))
+	webhooks.Get("/", h.Webhooks.ListWebhooks)
+	webhooks.Post("/", h.Webhooks.CreateWebhook)
+	webhooks.Put("/:id", h.Webhooks.UpdateWebhook)
+	webhooks.Delete("/:id", h.Webhooks.DeleteWebhook)
+	webhooks.Get("/:id/deliveries", h.Webhooks.ListDeliveries)
+}
@@ -0,0 +1,197 @@
+// Package stream fans task events out to live clients, like the Server-Sent
+// Events stream, and keeps the latest of them so that clients can catch up
+// after reconnecting.
+package stream
+
+import (
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"task/backend/events"
+	"task/backend/models"
+)
+
+// clientBuffer is how many messages may wait for a client before it is
+// dropped for falling behind.
+const clientBuffer = 256
+
+// Message is a task event as it goes out to clients.
+type Message struct {
+	// ID orders the messages of a broker. IDs start at the broker's start
+	// time in milliseconds, so they keep growing across restarts.
+	ID          uint64
+	Event       events.Type
+	WorkspaceID int
+	Task        models.Task
+	// Before is the task before an update, nil for other events
+	Before *models.Task
+	// Data is the encoded models.TaskStreamEvent
+	Data []byte
+}
+
+// Client receives the messages of one workspace.
+type Client struct {
+	workspaceID int
+	messages    chan Message
+}
+
+// Messages returns the client's messages. The channel is closed when the
+// client falls behind or is disconnected; it should reconnect and resume
+// from the last message it got.
+func (c *Client) Messages() <-chan Message {
+	return c.messages
+}
+
+// Subscription is what a client gets when it connects.
+type Subscription struct {
+	*Client
+	// Replay holds the buffered messages after the one the client resumes
+	// from.
+	Replay []Message
+	// Gap is set when the client resumes from a message that is no longer
+	// buffered, so some of the messages it missed are lost.
+	Gap bool
+	// LastID is the ID of the latest message when the client subscribed.
+	LastID uint64
+}
+
+// Broker keeps the latest messages in a bounded buffer and hands new ones
+// to its clients.
+type Broker struct {
+	size int
+
+	mu      sync.Mutex
+	buffer  []Message // oldest first
+	nextID  uint64
+	clients map[*Client]struct{}
+}
+
+// NewBroker returns a broker that buffers up to size messages for replay.
+func NewBroker(size int) *Broker {
+	return &Broker{
+		size:    size,
+		nextID:  uint64(time.Now().UnixMilli()),
+		clients: make(map[*Client]struct{}),
+	}
+}
+
+// Subscribe makes the broker an asynchronous subscriber of bus.
+func (b *Broker) Subscribe(bus *events.Bus) {
+	bus.SubscribeAsync(b.Handle)
+}
+
+// Handle turns a task event into a message for the clients of its
+// workspace. Status changes are left out since the update that comes with
+// them already carries the new status, and so are purges.
+func (b *Broker) Handle(event events.Event) {
+	msg := Message{Event: event.Type(), WorkspaceID: event.WorkspaceID, Task: event.Task()}
+	data := models.TaskStreamEvent{Task: msg.Task, ActorID: event.ActorID, OccurredAt: event.OccurredAt}
+	switch payload := event.Payload.(type) {
+	case events.TaskCreated, events.TaskDeleted:
+	case events.TaskUpdated:
+		msg.Before = &payload.Before
+		data.Changes = payload.Changes
+	case events.TaskRestored:
+		data.Changes = payload.Changes
+	default:
+		return
+	}
+
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("Encoding stream event %d: %v", event.ID, err)
+		return
+	}
+	msg.Data = encoded
+	b.publish(msg)
+}
+
+func (b *Broker) publish(msg Message) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	msg.ID = b.nextID
+	b.nextID++
+	b.buffer = append(b.buffer, msg)
+	if len(b.buffer) > b.size {
+		b.buffer = b.buffer[len(b.buffer)-b.size:]
+	}
+
+	for client := range b.clients {
+		if client.workspaceID != msg.WorkspaceID {
+			continue
+		}
+		select {
+		case client.messages <- msg:
+		default:
+			// Waiting for a slow client would hold up everyone else
+			b.drop(client)
+		}
+	}
+}
+
+// Connect registers a client for the messages of a workspace. With resume
+// set, the subscription also replays the buffered messages after lastID.
+func (b *Broker) Connect(workspaceID int, lastID uint64, resume bool) Subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	client := &Client{workspaceID: workspaceID, messages: make(chan Message, clientBuffer)}
+	b.clients[client] = struct{}{}
+
+	sub := Subscription{Client: client, LastID: b.nextID - 1}
+	if !resume {
+		return sub
+	}
+	switch {
+	case lastID > sub.LastID:
+		// Not handed out by this broker
+		sub.Gap = true
+	case len(b.buffer) == 0:
+		sub.Gap = lastID != sub.LastID
+	case lastID+1 < b.buffer[0].ID:
+		// The next message has already left the buffer
+		sub.Gap = true
+	}
+	if sub.Gap {
+		return sub
+	}
+	for _, msg := range b.buffer {
+		if msg.ID > lastID && msg.WorkspaceID == workspaceID {
+			sub.Replay = append(sub.Replay, msg)
+		}
+	}
+	return sub
+}
+
+// Disconnect removes a client and closes its messages.
+func (b *Broker) Disconnect(client *Client) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.drop(client)
+}
+
+// DisconnectAll drops every client, e.g. before shutting down.
+func (b *Broker) DisconnectAll() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for client := range b.clients {
+		b.drop(client)
+	}
+}
+
+// Clients returns how many clients are connected.
+func (b *Broker) Clients() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.clients)
+}
+
+func (b *Broker) drop(client *Client) {
+	if _, ok := b.clients[client]; ok {
+		delete(b.clients, client)
+		close(client.messages)
+	}
+}
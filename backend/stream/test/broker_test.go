@@ -0,0 +1,93 @@
+package stream
+
+import (
+	"testing"
+
+	"task/backend/events"
+	"task/backend/models"
+	"task/backend/stream"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func created(workspaceID int, title string) events.Event {
+	task := models.Task{Title: title, WorkspaceID: workspaceID}
+	return events.New(workspaceID, 1, events.TaskCreated{Task: task})
+}
+
+func titles(messages []stream.Message) []string {
+	titles := make([]string, len(messages))
+	for i, msg := range messages {
+		titles[i] = msg.Task.Title
+	}
+	return titles
+}
+
+func TestBroker_DeliversToWorkspaceClients(t *testing.T) {
+	broker := stream.NewBroker(10)
+	sub := broker.Connect(1, 0, false)
+	other := broker.Connect(2, 0, false)
+
+	broker.Handle(created(1, "first"))
+	broker.Handle(created(2, "other"))
+	broker.Handle(events.New(1, 1, events.StatusChanged{Task: models.Task{Title: "first"}}))
+
+	msg := <-sub.Messages()
+	assert.Equal(t, "first", msg.Task.Title)
+	assert.Equal(t, events.TypeTaskCreated, msg.Event)
+	assert.Equal(t, sub.LastID+1, msg.ID)
+	assert.Empty(t, sub.Messages(), "status changes aren't streamed")
+	assert.Len(t, other.Messages(), 1)
+}
+
+func TestBroker_Replay(t *testing.T) {
+	broker := stream.NewBroker(3)
+	start := broker.Connect(1, 0, false).LastID
+	for _, title := range []string{"first", "second", "third", "fourth"} {
+		broker.Handle(created(1, title))
+	}
+	broker.Handle(created(2, "other"))
+
+	// Only "third" and "fourth" of the workspace are still buffered
+	sub := broker.Connect(1, start+2, true)
+	assert.False(t, sub.Gap)
+	assert.Equal(t, []string{"third", "fourth"}, titles(sub.Replay))
+	assert.Equal(t, start+5, sub.LastID)
+
+	sub = broker.Connect(1, start+5, true)
+	assert.False(t, sub.Gap)
+	assert.Empty(t, sub.Replay)
+
+	assert.True(t, broker.Connect(1, start+1, true).Gap, "second has left the buffer")
+	assert.True(t, broker.Connect(1, start+6, true).Gap, "unknown IDs can't be resumed from")
+}
+
+func TestBroker_DropsSlowClients(t *testing.T) {
+	broker := stream.NewBroker(10)
+	sub := broker.Connect(1, 0, false)
+
+	for range 1000 {
+		broker.Handle(created(1, "task"))
+	}
+	assert.Zero(t, broker.Clients())
+
+	received := 0
+	for range sub.Messages() {
+		received++
+	}
+	require.Positive(t, received)
+	assert.Less(t, received, 1000, "the channel is closed once the client falls behind")
+}
+
+func TestBroker_DisconnectAll(t *testing.T) {
+	broker := stream.NewBroker(10)
+	sub := broker.Connect(1, 0, false)
+	broker.Connect(2, 0, false)
+
+	broker.DisconnectAll()
+	assert.Zero(t, broker.Clients())
+	_, open := <-sub.Messages()
+	assert.False(t, open)
+	broker.Disconnect(sub.Client)
+}
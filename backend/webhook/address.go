@@ -0,0 +1,91 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// ErrBlockedAddress is returned for webhooks that would reach the server's
+// own network, so that they can't be used to probe it.
+var ErrBlockedAddress = errors.New("webhook URLs cannot point to loopback, private or link-local addresses")
+
+// blockedPrefixes are the ranges Blocked rejects besides those netip knows
+// about: "this network" and the carrier-grade NAT range.
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+}
+
+// Blocked reports whether webhooks may not reach ip: loopback, private,
+// link-local, multicast and unspecified addresses, including IPv4 addresses
+// mapped to IPv6.
+func Blocked(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return true
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckURL resolves the host of a webhook URL and returns ErrBlockedAddress
+// if any of its addresses is Blocked. The Client of NewClient checks again
+// when it connects, as the host may resolve differently by then.
+func CheckURL(ctx context.Context, rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+
+	host := parsed.Hostname()
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return fmt.Errorf("cannot resolve %q", host)
+	}
+	for _, addr := range addrs {
+		if Blocked(addr) {
+			return ErrBlockedAddress
+		}
+	}
+	return nil
+}
+
+// NewClient returns the HTTP client deliveries are sent with. Unless
+// allowPrivate is set, it refuses to connect to Blocked addresses, also
+// after redirects, and ignores proxy settings so that every connection is
+// checked.
+func NewClient(allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: 10 * time.Second, KeepAlive: 30 * time.Second}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if !allowPrivate {
+		dialer.Control = refuseBlocked
+		transport.Proxy = nil
+	}
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: 10 * time.Second, Transport: transport}
+}
+
+// refuseBlocked is a net.Dialer Control function that stops connections to
+// Blocked addresses after their host is resolved.
+func refuseBlocked(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if Blocked(addrPort.Addr()) {
+		return ErrBlockedAddress
+	}
+	return nil
+}
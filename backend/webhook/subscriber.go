@@ -0,0 +1,51 @@
+package webhook
+
+import (
+	"task/backend/events"
+	"task/backend/models"
+	"task/backend/repository"
+)
+
+// Subscribe queues webhook deliveries for task events, in the transaction
+// that made the change. Restored and purged tasks raise no webhook events.
+func Subscribe(bus *events.Bus) {
+	bus.Subscribe(enqueue)
+}
+
+func enqueue(tx *repository.Store, event events.Event) error {
+	task := event.Task()
+	payload := models.WebhookPayload{
+		OccurredAt:  event.OccurredAt,
+		WorkspaceID: event.WorkspaceID,
+		ActorID:     event.ActorID,
+		Task:        &task,
+		Changes:     []models.FieldChange{},
+	}
+
+	switch change := event.Payload.(type) {
+	case events.TaskCreated:
+		payload.Event = models.EventTaskCreated
+		payload.Changes = events.Diff(nil, &task)
+	case events.TaskUpdated:
+		payload.Event = models.EventTaskUpdated
+		payload.Changes = change.Changes
+	case events.StatusChanged:
+		if change.To != models.TaskStatusCompleted {
+			return nil
+		}
+		payload.Event = models.EventTaskCompleted
+		payload.Changes = []models.FieldChange{{Field: "status", Before: change.From, After: change.To}}
+	case events.TaskDeleted:
+		payload.Event = models.EventTaskDeleted
+	case events.TaskReminder:
+		payload.Event = models.EventTaskOverdue
+		if change.MinutesBefore > 0 {
+			payload.Event = models.EventTaskDueSoon
+			payload.MinutesBefore = change.MinutesBefore
+		}
+	default:
+		return nil
+	}
+
+	return Enqueue(tx.Webhooks, payload)
+}
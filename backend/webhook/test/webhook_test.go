@@ -0,0 +1,273 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"task/backend/models"
+	"task/backend/repository"
+	"task/backend/webhook"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const secret = "a-long-enough-secret"
+
+// receiver records the requests it gets and answers them with the queued
+// statuses, then with 200.
+type receiver struct {
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   [][]byte
+	statuses []int
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, req)
+	r.bodies = append(r.bodies, body)
+	status := http.StatusOK
+	if len(r.statuses) > 0 {
+		status, r.statuses = r.statuses[0], r.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func (r *receiver) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.requests)
+}
+
+// setup returns a repository with one webhook pointing at a new receiver.
+func setup(t *testing.T, events ...models.WebhookEvent) (repository.WebhookRepository, *models.Webhook, *receiver) {
+	recv := &receiver{}
+	server := httptest.NewServer(recv)
+	t.Cleanup(server.Close)
+
+	repo := repository.NewMemoryWebhookRepository()
+	hook := &models.Webhook{WorkspaceID: 1, URL: server.URL, Events: events, Secret: secret}
+	require.NoError(t, repo.Create(hook))
+	return repo, hook, recv
+}
+
+// newDispatcher returns a dispatcher that can reach the test receivers on
+// the loopback interface.
+func newDispatcher(repo repository.WebhookRepository) *webhook.Dispatcher {
+	dispatcher := webhook.NewDispatcher(repo)
+	dispatcher.Client = webhook.NewClient(true)
+	return dispatcher
+}
+
+func payload(event models.WebhookEvent, at time.Time) models.WebhookPayload {
+	return models.WebhookPayload{
+		Event:       event,
+		OccurredAt:  at,
+		WorkspaceID: 1,
+		ActorID:     7,
+		Task:        &models.Task{ID: 3, Title: "write-docs", WorkspaceID: 1},
+		Changes:     []models.FieldChange{},
+	}
+}
+
+func deliveries(t *testing.T, repo repository.WebhookRepository, hook *models.Webhook) []models.WebhookDelivery {
+	list, _, err := repo.ListDeliveries(hook.ID, 1, 100)
+	require.NoError(t, err)
+	return list
+}
+
+func TestSignAndVerify(t *testing.T) {
+	body := []byte(`{"event":"task.created"}`)
+	signature := webhook.Sign(secret, body)
+
+	assert.Regexp(t, `^sha256=[0-9a-f]{64}$`, signature)
+	assert.True(t, webhook.Verify(secret, body, signature))
+	assert.False(t, webhook.Verify("another-secret-entirely", body, signature))
+	assert.False(t, webhook.Verify(secret, []byte(`{"event":"task.deleted"}`), signature))
+}
+
+func TestEnqueue_OnlySubscribedEvents(t *testing.T) {
+	repo, hook, _ := setup(t, models.EventTaskCompleted)
+	other := &models.Webhook{WorkspaceID: 2, URL: "http://example.com", Secret: secret}
+	require.NoError(t, repo.Create(other))
+
+	now := time.Now()
+	require.NoError(t, webhook.Enqueue(repo, payload(models.EventTaskUpdated, now)))
+	require.NoError(t, webhook.Enqueue(repo, payload(models.EventTaskCompleted, now)))
+
+	queued := deliveries(t, repo, hook)
+	require.Len(t, queued, 1)
+	assert.Equal(t, models.EventTaskCompleted, queued[0].Event)
+	assert.Equal(t, models.DeliveryPending, queued[0].Status)
+	assert.Empty(t, deliveries(t, repo, other), "webhooks of other workspaces get nothing")
+}
+
+func TestDeliverDue_SendsSignedPayload(t *testing.T) {
+	repo, hook, recv := setup(t)
+	now := time.Now()
+	require.NoError(t, webhook.Enqueue(repo, payload(models.EventTaskCreated, now)))
+
+	accepted, err := newDispatcher(repo).DeliverDue(now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, accepted)
+	require.Equal(t, 1, recv.count())
+
+	request, body := recv.requests[0], recv.bodies[0]
+	delivery := deliveries(t, repo, hook)[0]
+	assert.Equal(t, "application/json", request.Header.Get("Content-Type"))
+	assert.Equal(t, "task.created", request.Header.Get(webhook.EventHeader))
+	assert.Equal(t, strconv.Itoa(delivery.ID), request.Header.Get(webhook.DeliveryHeader))
+	assert.True(t, webhook.Verify(secret, body, request.Header.Get(webhook.SignatureHeader)))
+
+	var sent models.WebhookPayload
+	require.NoError(t, json.Unmarshal(body, &sent))
+	assert.Equal(t, models.EventTaskCreated, sent.Event)
+	assert.Equal(t, 7, sent.ActorID)
+	assert.Equal(t, "write-docs", sent.Task.Title)
+
+	assert.Equal(t, models.DeliverySucceeded, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, http.StatusOK, delivery.ResponseStatus)
+	assert.NotNil(t, delivery.DeliveredAt)
+
+	// Delivered events aren't sent again
+	accepted, err = newDispatcher(repo).DeliverDue(now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Zero(t, accepted)
+	assert.Equal(t, 1, recv.count())
+}
+
+func TestDeliverDue_RetriesWithBackoff(t *testing.T) {
+	repo, hook, recv := setup(t)
+	recv.statuses = []int{http.StatusInternalServerError, http.StatusBadGateway}
+	dispatcher := newDispatcher(repo)
+
+	now := time.Now()
+	require.NoError(t, webhook.Enqueue(repo, payload(models.EventTaskDeleted, now)))
+
+	_, err := dispatcher.DeliverDue(now)
+	require.NoError(t, err)
+	delivery := deliveries(t, repo, hook)[0]
+	assert.Equal(t, models.DeliveryPending, delivery.Status)
+	assert.Equal(t, http.StatusInternalServerError, delivery.ResponseStatus)
+	assert.Contains(t, delivery.Error, "500")
+	assert.WithinDuration(t, now.Add(dispatcher.Backoff), delivery.NextAttemptAt, time.Millisecond)
+
+	// Nothing is sent before the next attempt is due
+	_, err = dispatcher.DeliverDue(now.Add(dispatcher.Backoff - time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, 1, recv.count())
+
+	now = now.Add(dispatcher.Backoff)
+	_, err = dispatcher.DeliverDue(now)
+	require.NoError(t, err)
+	delivery = deliveries(t, repo, hook)[0]
+	assert.Equal(t, 2, delivery.Attempts)
+	assert.WithinDuration(t, now.Add(2*dispatcher.Backoff), delivery.NextAttemptAt, time.Millisecond)
+
+	accepted, err := dispatcher.DeliverDue(now.Add(2 * dispatcher.Backoff))
+	require.NoError(t, err)
+	assert.Equal(t, 1, accepted)
+	delivery = deliveries(t, repo, hook)[0]
+	assert.Equal(t, models.DeliverySucceeded, delivery.Status)
+	assert.Equal(t, 3, delivery.Attempts)
+	assert.Empty(t, delivery.Error)
+
+	// Retries carry the same delivery ID, so receivers can spot repeats
+	assert.Equal(t, recv.requests[0].Header.Get(webhook.DeliveryHeader), recv.requests[2].Header.Get(webhook.DeliveryHeader))
+}
+
+func TestDeliverDue_GivesUp(t *testing.T) {
+	repo, hook, recv := setup(t)
+	recv.statuses = []int{http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError}
+	dispatcher := newDispatcher(repo)
+	dispatcher.MaxAttempts = 2
+
+	now := time.Now()
+	require.NoError(t, webhook.Enqueue(repo, payload(models.EventTaskCreated, now)))
+	for i := 0; i < 3; i++ {
+		_, err := dispatcher.DeliverDue(now.Add(time.Duration(i) * 24 * time.Hour))
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, 2, recv.count())
+	delivery := deliveries(t, repo, hook)[0]
+	assert.Equal(t, models.DeliveryFailed, delivery.Status)
+	assert.Equal(t, 2, delivery.Attempts)
+	assert.Nil(t, delivery.DeliveredAt)
+}
+
+func TestDeliverDue_UnreachableReceiver(t *testing.T) {
+	repo := repository.NewMemoryWebhookRepository()
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	hook := &models.Webhook{WorkspaceID: 1, URL: server.URL, Secret: secret}
+	require.NoError(t, repo.Create(hook))
+
+	now := time.Now()
+	require.NoError(t, webhook.Enqueue(repo, payload(models.EventTaskCreated, now)))
+	_, err := newDispatcher(repo).DeliverDue(now)
+	require.NoError(t, err, "failed deliveries don't fail the dispatcher")
+
+	delivery := deliveries(t, repo, hook)[0]
+	assert.Equal(t, models.DeliveryPending, delivery.Status)
+	assert.Zero(t, delivery.ResponseStatus)
+	assert.NotEmpty(t, delivery.Error)
+}
+
+func TestDeliverDue_RefusesPrivateAddresses(t *testing.T) {
+	repo, hook, recv := setup(t)
+	now := time.Now()
+	require.NoError(t, webhook.Enqueue(repo, payload(models.EventTaskCreated, now)))
+
+	// The default client checks the address it connects to
+	accepted, err := webhook.NewDispatcher(repo).DeliverDue(now)
+	require.NoError(t, err)
+	assert.Zero(t, accepted)
+	assert.Zero(t, recv.count())
+
+	delivery := deliveries(t, repo, hook)[0]
+	assert.Equal(t, models.DeliveryPending, delivery.Status)
+	assert.Zero(t, delivery.ResponseStatus)
+	assert.Contains(t, delivery.Error, webhook.ErrBlockedAddress.Error())
+}
+
+func TestBlocked(t *testing.T) {
+	blocked := []string{"127.0.0.1", "10.1.2.3", "172.16.0.1", "192.168.0.1", "169.254.169.254", "0.0.0.0", "100.64.0.1", "224.0.0.1", "::1", "::", "fe80::1", "fd00::1", "::ffff:127.0.0.1"}
+	for _, addr := range blocked {
+		assert.True(t, webhook.Blocked(netip.MustParseAddr(addr)), addr)
+	}
+	for _, addr := range []string{"203.0.113.10", "8.8.8.8", "2001:db8::1"} {
+		assert.False(t, webhook.Blocked(netip.MustParseAddr(addr)), addr)
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	ctx := context.Background()
+	assert.ErrorIs(t, webhook.CheckURL(ctx, "http://169.254.169.254/latest/meta-data"), webhook.ErrBlockedAddress)
+	assert.ErrorIs(t, webhook.CheckURL(ctx, "https://[::1]:8443/hooks"), webhook.ErrBlockedAddress)
+	assert.ErrorIs(t, webhook.CheckURL(ctx, "http://localhost:3000/hooks"), webhook.ErrBlockedAddress)
+	assert.NoError(t, webhook.CheckURL(ctx, "https://203.0.113.10/hooks"))
+}
+
+func TestDelay(t *testing.T) {
+	dispatcher := &webhook.Dispatcher{Backoff: time.Minute, MaxBackoff: time.Hour}
+
+	assert.Equal(t, time.Minute, dispatcher.Delay(1))
+	assert.Equal(t, 2*time.Minute, dispatcher.Delay(2))
+	assert.Equal(t, 32*time.Minute, dispatcher.Delay(6))
+	assert.Equal(t, time.Hour, dispatcher.Delay(7))
+	assert.Equal(t, time.Hour, dispatcher.Delay(50))
+}
@@ -0,0 +1,186 @@
+// Package webhook delivers task events to the webhooks subscribed to them.
+// Events are queued as deliveries first and sent by a Dispatcher, which
+// retries failed deliveries with exponential backoff. Receivers may see a
+// delivery more than once and can use its ID to ignore repeats.
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"task/backend/models"
+	"task/backend/repository"
+)
+
+// Headers sent with every delivery.
+const (
+	EventHeader    = "X-Webhook-Event"
+	DeliveryHeader = "X-Webhook-Delivery"
+	// SignatureHeader holds "sha256=" followed by the hex encoded
+	// HMAC-SHA256 of the body, keyed with the webhook's secret
+	SignatureHeader = "X-Webhook-Signature"
+)
+
+// Sign returns the signature header value of body.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is the signature of body, in constant
+// time.
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+// Enqueue queues payload for every webhook of its workspace that subscribes
+// to its event.
+func Enqueue(repo repository.WebhookRepository, payload models.WebhookPayload) error {
+	hooks, err := repo.List(payload.WorkspaceID)
+	if err != nil {
+		return err
+	}
+
+	var body []byte
+	for _, hook := range hooks {
+		if !hook.Wants(payload.Event) {
+			continue
+		}
+		if body == nil {
+			if body, err = json.Marshal(payload); err != nil {
+				return err
+			}
+		}
+
+		delivery := models.WebhookDelivery{
+			WebhookID:     hook.ID,
+			Event:         payload.Event,
+			Payload:       body,
+			Status:        models.DeliveryPending,
+			NextAttemptAt: payload.OccurredAt,
+			CreatedAt:     time.Now(),
+			UpdatedAt:     time.Now(),
+		}
+		if err := repo.CreateDelivery(&delivery); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Dispatcher sends queued deliveries.
+type Dispatcher struct {
+	Repo   repository.WebhookRepository
+	Client *http.Client
+	// MaxAttempts is how often a delivery is tried before it fails for good
+	MaxAttempts int
+	// Backoff is the wait after the first failed attempt. It doubles with
+	// every further failure, up to MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// BatchSize limits the deliveries sent by one DeliverDue call
+	BatchSize int
+}
+
+// NewDispatcher returns a Dispatcher that keeps retrying a delivery for
+// about 14 hours before giving up. Its Client refuses Blocked addresses.
+func NewDispatcher(repo repository.WebhookRepository) *Dispatcher {
+	return &Dispatcher{
+		Repo:        repo,
+		Client:      NewClient(false),
+		MaxAttempts: 12,
+		Backoff:     30 * time.Second,
+		MaxBackoff:  6 * time.Hour,
+		BatchSize:   100,
+	}
+}
+
+// Delay returns how long a delivery waits after its attempts-th failed
+// attempt.
+func (d *Dispatcher) Delay(attempts int) time.Duration {
+	delay := d.Backoff
+	for i := 1; i < attempts && delay < d.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, d.MaxBackoff)
+}
+
+// DeliverDue sends the deliveries that are due at now, one after another,
+// and reschedules the ones that fail. It returns how many were accepted.
+func (d *Dispatcher) DeliverDue(now time.Time) (int, error) {
+	deliveries, err := d.Repo.DueDeliveries(now, d.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	accepted := 0
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		hook, err := d.Repo.GetByID(delivery.WebhookID)
+		if errors.Is(err, repository.ErrNotFound) {
+			// Deleted since the batch was read, together with its deliveries
+			continue
+		}
+		if err != nil {
+			return accepted, err
+		}
+
+		delivery.Attempts++
+		delivery.UpdatedAt = now
+		delivery.ResponseStatus, err = d.send(hook, delivery)
+		switch {
+		case err == nil:
+			delivery.Status = models.DeliverySucceeded
+			delivery.Error = ""
+			delivery.DeliveredAt = &now
+			accepted++
+		case delivery.Attempts >= d.MaxAttempts:
+			delivery.Status = models.DeliveryFailed
+			delivery.Error = err.Error()
+		default:
+			delivery.NextAttemptAt = now.Add(d.Delay(delivery.Attempts))
+			delivery.Error = err.Error()
+		}
+
+		if err := d.Repo.UpdateDelivery(delivery); err != nil {
+			return accepted, err
+		}
+	}
+	return accepted, nil
+}
+
+// send posts the delivery to the webhook and returns the response status.
+// Any status outside 2xx counts as a failure.
+func (d *Dispatcher) send(hook *models.Webhook, delivery *models.WebhookDelivery) (int, error) {
+	request, err := http.NewRequest(http.MethodPost, hook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(EventHeader, string(delivery.Event))
+	request.Header.Set(DeliveryHeader, strconv.Itoa(delivery.ID))
+	request.Header.Set(SignatureHeader, Sign(hook.Secret, delivery.Payload))
+
+	response, err := d.Client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	// Drain a little of the body so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(response.Body, 64<<10))
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return response.StatusCode, fmt.Errorf("receiver responded with %s", response.Status)
+	}
+	return response.StatusCode, nil
+}